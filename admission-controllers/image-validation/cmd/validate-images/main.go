@@ -0,0 +1,176 @@
+// Command validate-images checks the images of rendered manifests against the same image policy the
+// image-validation webhook enforces, so CI can reject a disallowed image before it reaches a cluster.
+//
+// It reads YAML or JSON manifests, multiple documents and List objects included, from the files given
+// as arguments or from stdin, and validates every Deployment, StatefulSet, DaemonSet, ReplicaSet, Job,
+// CronJob and Pod in them. Other kinds are ignored. With --images the input is one image per line instead.
+// Each disallowed image is printed and the command exits with 1, invalid input exits with 2.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/harsha3330/kubernetes/admission-controller/image-validation/imagepolicy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run validates the inputs named by args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate-images", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	allowlistFile := flags.String("registry-allowlist", "", "File with one allowed registry prefix per line, or a regular expression prefixed with re:, # starts a comment. Empty uses the built-in "+strings.Join(imagepolicy.DefaultRegistryAllowlist, ","))
+	imageList := flags.Bool("images", false, "Read one image per line instead of manifests")
+	policy := imagepolicy.Policy{}
+	flags.BoolVar(&policy.DenyLatestTag, "deny-latest-tag", false, "Reject images using the latest tag or no tag at all")
+	flags.BoolVar(&policy.RequireDigest, "require-digest", false, "Reject images that are not pinned by a @sha256: digest")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	policy.Rules = imagepolicy.PrefixRules(imagepolicy.DefaultRegistryAllowlist)
+	if *allowlistFile != "" {
+		allowlist, err := imagepolicy.LoadAllowlist(*allowlistFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		policy.Rules = allowlist.Rules()
+	}
+
+	inputs := flags.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	var violations []string
+	for _, input := range inputs {
+		found, err := validateInput(policy, input, stdin, *imageList)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		violations = append(violations, found...)
+	}
+
+	for _, violation := range violations {
+		fmt.Fprintln(stdout, violation)
+	}
+	if len(violations) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// validateInput validates the file named input, or stdin for "-", and returns its violations.
+func validateInput(policy imagepolicy.Policy, input string, stdin io.Reader, imageList bool) ([]string, error) {
+	reader := stdin
+	source := "stdin"
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader, source = file, input
+	}
+	if imageList {
+		return validateImageList(policy, source, reader)
+	}
+	return validateManifests(policy, source, reader)
+}
+
+// validateImageList validates one image per line, blank lines and lines starting with # are skipped.
+func validateImageList(policy imagepolicy.Policy, source string, reader io.Reader) ([]string, error) {
+	var violations []string
+	scanner := bufio.NewScanner(reader)
+	for n := 1; scanner.Scan(); n++ {
+		image := strings.TrimSpace(scanner.Text())
+		if image == "" || strings.HasPrefix(image, "#") {
+			continue
+		}
+		for _, violation := range policy.ImageViolations(image, fmt.Sprintf("line %d", n)) {
+			violations = append(violations, source+": "+violation.Message)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	return violations, nil
+}
+
+// validateManifests validates the workloads of a YAML or JSON stream of manifests.
+func validateManifests(policy imagepolicy.Policy, source string, reader io.Reader) ([]string, error) {
+	var violations []string
+	decoder := yaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return violations, nil
+			}
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		found, err := validateManifest(policy, source, raw)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, found...)
+	}
+}
+
+// validateManifest validates a single manifest, the items of a List are validated one by one.
+func validateManifest(policy imagepolicy.Policy, source string, raw json.RawMessage) ([]string, error) {
+	// Empty documents, e.g. a trailing "---", decode to null
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if strings.HasSuffix(typeMeta.Kind, "List") {
+		var list struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		var violations []string
+		for _, item := range list.Items {
+			found, err := validateManifest(policy, source, item)
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, found...)
+		}
+		return violations, nil
+	}
+	if !imagepolicy.IsWorkloadKind(typeMeta.Kind) {
+		return nil, nil
+	}
+
+	workload, err := imagepolicy.DecodeWorkload(typeMeta.Kind, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	name := workload.Object.GetName()
+	if namespace := workload.Object.GetNamespace(); namespace != "" {
+		name = namespace + "/" + name
+	}
+	var violations []string
+	for _, violation := range policy.PodSpecViolations(workload.PodSpec) {
+		violations = append(violations, fmt.Sprintf("%s: %s %s: %s", source, workload.Kind, name, violation.Message))
+	}
+	return violations, nil
+}
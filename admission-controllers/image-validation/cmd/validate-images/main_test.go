@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const allowedDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: app
+        image: 095728565421.dkr.ecr.us-east-1.amazonaws.com/pause:3.9
+`
+
+const disallowedDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  selector:
+    matchLabels:
+      app: worker
+  template:
+    metadata:
+      labels:
+        app: worker
+    spec:
+      initContainers:
+      - name: setup
+        image: 095728565421.dkr.ecr.us-east-1.amazonaws.com/busybox:1.36
+      containers:
+      - name: app
+        image: evil.example.com/worker:1.0
+`
+
+const service = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+`
+
+func runWith(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunAllowedManifests(t *testing.T) {
+	code, stdout, stderr := runWith(t, allowedDeployment+"---\n"+service+"---\n")
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s%s", code, stdout, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("expected no output, got %q", stdout)
+	}
+}
+
+func TestRunDisallowedManifests(t *testing.T) {
+	code, stdout, _ := runWith(t, allowedDeployment+"---\n"+disallowedDeployment)
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one violation, got %q", stdout)
+	}
+	for _, want := range []string{"stdin: Deployment worker:", "evil.example.com/worker:1.0", "container app"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("expected %q in %q", want, lines[0])
+		}
+	}
+}
+
+func TestRunJSONList(t *testing.T) {
+	list := `{"apiVersion":"v1","kind":"List","items":[
+	  {"apiVersion":"v1","kind":"Pod","metadata":{"name":"debug","namespace":"ops"},
+	   "spec":{"containers":[{"name":"shell","image":"docker.io/library/busybox:latest"}]}},
+	  {"apiVersion":"batch/v1","kind":"CronJob","metadata":{"name":"report"},
+	   "spec":{"schedule":"@daily","jobTemplate":{"spec":{"template":{"spec":{"restartPolicy":"Never",
+	   "containers":[{"name":"report","image":"095728565421.dkr.ecr.us-east-1.amazonaws.com/report:2"}]}}}}}}]}`
+	code, stdout, _ := runWith(t, list)
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stdout, "Pod ops/debug:") || strings.Contains(stdout, "CronJob") {
+		t.Errorf("expected only the Pod to be reported, got %q", stdout)
+	}
+}
+
+func TestRunPolicyFlags(t *testing.T) {
+	code, stdout, _ := runWith(t, allowedDeployment, "--require-digest")
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stdout, "digest") {
+		t.Errorf("expected a digest violation, got %q", stdout)
+	}
+}
+
+func TestRunFilesAndAllowlist(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "worker.yaml")
+	allowlist := filepath.Join(dir, "allowlist")
+	if err := os.WriteFile(manifest, []byte(disallowedDeployment), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(allowlist, []byte("# internal registries\n095728565421.dkr.ecr.us-east-1.amazonaws.com/\nevil.example.com/\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, _ := runWith(t, "", manifest)
+	if code != 1 || !strings.HasPrefix(stdout, manifest+": ") {
+		t.Errorf("expected a violation reported against %s, got %d %q", manifest, code, stdout)
+	}
+	code, stdout, _ = runWith(t, "", "--registry-allowlist", allowlist, manifest)
+	if code != 0 {
+		t.Errorf("expected exit code 0 with the allowlist, got %d %q", code, stdout)
+	}
+}
+
+func TestRunImageList(t *testing.T) {
+	images := "# images of the release\n095728565421.dkr.ecr.us-east-1.amazonaws.com/pause:3.9\n\nevil.example.com/miner:1\n"
+	code, stdout, _ := runWith(t, images, "--images")
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if want := "stdin: "; !strings.HasPrefix(stdout, want) || !strings.Contains(stdout, "line 4") ||
+		strings.Contains(stdout, "pause") {
+		t.Errorf("expected only line 4 to be reported, got %q", stdout)
+	}
+}
+
+func TestRunInvalidInput(t *testing.T) {
+	for name, args := range map[string][]string{
+		"unknown flag":      {"--no-such-flag"},
+		"missing file":      {filepath.Join(t.TempDir(), "missing.yaml")},
+		"missing allowlist": {"--registry-allowlist", filepath.Join(t.TempDir(), "missing")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if code, _, _ := runWith(t, allowedDeployment, args...); code != 2 {
+				t.Errorf("expected exit code 2, got %d", code)
+			}
+		})
+	}
+	if code, _, stderr := runWith(t, "kind: [unterminated"); code != 2 || stderr == "" {
+		t.Errorf("expected exit code 2 and an error for invalid YAML, got %d %q", code, stderr)
+	}
+}
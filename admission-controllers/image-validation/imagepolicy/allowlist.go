@@ -0,0 +1,155 @@
+package imagepolicy
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRegistryAllowlist is used when no allowlist file is given.
+var DefaultRegistryAllowlist = []string{
+	"095728565421.dkr.ecr",
+}
+
+// regexRulePrefix marks an allowlist entry as a regular expression instead of a literal prefix.
+const regexRulePrefix = "re:"
+
+// RegistryRule allows images starting with prefix, or matching pattern for a regex entry.
+type RegistryRule struct {
+	prefix  string
+	pattern *regexp.Regexp
+}
+
+func (r RegistryRule) String() string {
+	if r.pattern != nil {
+		return regexRulePrefix + r.pattern.String()
+	}
+	return r.prefix
+}
+
+// PrefixRules returns a rule per literal registry prefix.
+func PrefixRules(prefixes []string) []RegistryRule {
+	rules := make([]RegistryRule, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		rules = append(rules, RegistryRule{prefix: prefix})
+	}
+	return rules
+}
+
+// ParseAllowlist parses an allowlist file with one registry prefix per line. An entry starting with "re:"
+// is a regular expression, anchored at the start of the image like a prefix, e.g.
+// re:(111111111111|222222222222)\.dkr\.ecr\.us-east-1\.amazonaws\.com/
+// A "#" at the start of a line or after a space starts a comment, blank lines are ignored.
+// It fails on the first invalid regular expression, naming its line.
+func ParseAllowlist(data string) ([]RegistryRule, error) {
+	var rules []RegistryRule
+	for n, line := range strings.Split(data, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		expr, isRegex := strings.CutPrefix(line, regexRulePrefix)
+		if !isRegex {
+			rules = append(rules, RegistryRule{prefix: line})
+			continue
+		}
+		pattern, err := regexp.Compile("^(?:" + expr + ")")
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid registry regular expression %q: %w", n+1, expr, err)
+		}
+		rules = append(rules, RegistryRule{pattern: pattern})
+	}
+	return rules, nil
+}
+
+// ImageAllowed reports whether the image starts with one of the allowed registry prefixes or matches
+// one of the regular expressions. Prefixes are checked with strings.HasPrefix, not the regex engine.
+func ImageAllowed(rules []RegistryRule, image string) bool {
+	for _, rule := range rules {
+		if rule.pattern == nil && strings.HasPrefix(image, rule.prefix) {
+			return true
+		}
+		if rule.pattern != nil && rule.pattern.MatchString(image) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowlist serves the registry rules last loaded from file so edits to a mounted
+// ConfigMap are picked up without restarting the webhook. Without a file it serves fixed rules.
+type Allowlist struct {
+	file string
+
+	mu      sync.RWMutex
+	rules   []RegistryRule
+	modTime time.Time
+}
+
+// NewStaticAllowlist returns an allowlist serving fixed rules.
+func NewStaticAllowlist(rules []RegistryRule) *Allowlist {
+	return &Allowlist{rules: rules}
+}
+
+// LoadAllowlist loads the allowlist from file, Reload picks up later edits.
+func LoadAllowlist(file string) (*Allowlist, error) {
+	allowlist := &Allowlist{file: file}
+	if _, err := allowlist.Reload(); err != nil {
+		return nil, err
+	}
+	return allowlist, nil
+}
+
+// File returns the file the allowlist is loaded from, empty for fixed rules.
+func (a *Allowlist) File() string {
+	return a.file
+}
+
+// Rules returns the current registry rules.
+func (a *Allowlist) Rules() []RegistryRule {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.rules
+}
+
+// Reload parses the file again when it changed since the last load.
+// It reports whether new rules were loaded, the current ones are kept on errors,
+// including an invalid regular expression.
+func (a *Allowlist) Reload() (bool, error) {
+	if a.file == "" {
+		return false, nil
+	}
+	info, err := os.Stat(a.file)
+	if err != nil {
+		return false, err
+	}
+	a.mu.RLock()
+	unchanged := !a.modTime.IsZero() && info.ModTime().Equal(a.modTime)
+	a.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(a.file)
+	if err != nil {
+		return false, fmt.Errorf("reading registry allowlist %s: %w", a.file, err)
+	}
+	rules, err := ParseAllowlist(string(data))
+	if err != nil {
+		return false, fmt.Errorf("parsing registry allowlist %s: %w", a.file, err)
+	}
+	a.mu.Lock()
+	a.rules = rules
+	a.modTime = info.ModTime()
+	a.mu.Unlock()
+	return true, nil
+}
@@ -0,0 +1,90 @@
+package imagepolicy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAllowlistAndMatch(t *testing.T) {
+	allowlist, err := ParseAllowlist("# registries we own\n095728565421.dkr.ecr\n\n  ghcr.io/acme/  # team images\n" +
+		`re:(111111111111|222222222222)\.dkr\.ecr\.us-east-1\.amazonaws\.com/`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(allowlist), `[095728565421.dkr.ecr ghcr.io/acme/ re:^(?:(111111111111|222222222222)\.dkr\.ecr\.us-east-1\.amazonaws\.com/)]`; got != want {
+		t.Fatalf("allowlist = %s, want %s", got, want)
+	}
+
+	tests := map[string]bool{
+		"095728565421.dkr.ecr.ap-south-1.amazonaws.com/app:1.0": true,
+		"ghcr.io/acme/api:2":  true,
+		"ghcr.io/other/api:2": false,
+		"nginx:latest":        false,
+		"222222222222.dkr.ecr.us-east-1.amazonaws.com/app:1.0":   true,
+		"222222222222.dkr.ecr.eu-west-1.amazonaws.com/app:1.0":   false,
+		"evil.io/222222222222.dkr.ecr.us-east-1.amazonaws.com/x": false,
+	}
+	for image, want := range tests {
+		if got := ImageAllowed(allowlist, image); got != want {
+			t.Errorf("ImageAllowed(%q) = %v, want %v", image, got, want)
+		}
+	}
+}
+
+func TestAllowlistReloaderPicksUpEdits(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "registries")
+	write := func(content string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+	write("095728565421.dkr.ecr\n", start)
+
+	reloader, err := LoadAllowlist(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ImageAllowed(reloader.Rules(), "ghcr.io/acme/api:2") {
+		t.Fatal("expected ghcr.io to be denied before the edit")
+	}
+	if reloaded, err := reloader.Reload(); err != nil || reloaded {
+		t.Fatalf("expected no reload for an unchanged file, got %v, %v", reloaded, err)
+	}
+
+	write("095728565421.dkr.ecr\nghcr.io/acme/\n", start.Add(time.Minute))
+	if reloaded, err := reloader.Reload(); err != nil || !reloaded {
+		t.Fatalf("expected a reload after the edit, got %v, %v", reloaded, err)
+	}
+	if !ImageAllowed(reloader.Rules(), "ghcr.io/acme/api:2") {
+		t.Error("expected ghcr.io/acme to be allowed after the edit")
+	}
+
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reloader.Reload(); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+	if !ImageAllowed(reloader.Rules(), "ghcr.io/acme/api:2") {
+		t.Error("expected the last loaded allowlist to be kept")
+	}
+}
+
+func TestParseAllowlistRejectsInvalidRegex(t *testing.T) {
+	_, err := ParseAllowlist("095728565421.dkr.ecr\nre:ghcr.io/(acme\n")
+	if err == nil {
+		t.Fatal("expected an error for an invalid regular expression")
+	}
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected the error to name the line, got %v", err)
+	}
+}
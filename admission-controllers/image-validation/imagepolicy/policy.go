@@ -0,0 +1,81 @@
+// Package imagepolicy holds the image policy of the validating webhook: the registry allowlist, the
+// tag and digest checks and the decoding of workloads, so the webhook and the validate-images command
+// apply the same rules.
+package imagepolicy
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Policy is the set of checks every image of a workload must pass.
+type Policy struct {
+	// Rules are the registries images must come from
+	Rules []RegistryRule
+	// DenyLatestTag rejects images using the latest tag or no tag at all
+	DenyLatestTag bool
+	// RequireDigest rejects images that are not pinned by a sha256 digest
+	RequireDigest bool
+}
+
+// Rule names a check of the policy, so it can be configured on its own.
+type Rule string
+
+// Rules of the policy.
+const (
+	RuleRegistry  Rule = "registry"
+	RuleLatestTag Rule = "latest-tag"
+	RuleDigest    Rule = "digest"
+)
+
+// Violation is an image breaking a rule of the policy, with a message naming the image and its container.
+type Violation struct {
+	Rule    Rule
+	Message string
+}
+
+// PodSpecViolations checks every container, init container and ephemeral container image of the pod
+// spec and returns a violation for each rule an image breaks.
+func (p Policy) PodSpecViolations(podSpec *corev1.PodSpec) []Violation {
+	var violations []Violation
+	for _, image := range PodSpecImages(podSpec) {
+		violations = append(violations, p.ImageViolations(image.Image, image.Container)...)
+	}
+	return violations
+}
+
+// ImageViolations returns one violation per rule the image breaks, where names the container using it.
+func (p Policy) ImageViolations(image, where string) []Violation {
+	var violations []Violation
+	if !ImageAllowed(p.Rules, image) {
+		violations = append(violations, Violation{RuleRegistry, fmt.Sprintf("image %s in %s is not from an allowed private registry", image, where)})
+	}
+	_, tag, digest := ParseImageReference(image)
+	if p.DenyLatestTag && digest == "" && (tag == "" || tag == "latest") {
+		if tag == "" {
+			violations = append(violations, Violation{RuleLatestTag, fmt.Sprintf("image %s in %s has no tag, which defaults to the mutable latest tag", image, where)})
+		} else {
+			violations = append(violations, Violation{RuleLatestTag, fmt.Sprintf("image %s in %s uses the mutable latest tag", image, where)})
+		}
+	}
+	if p.RequireDigest && !strings.HasPrefix(digest, "sha256:") {
+		violations = append(violations, Violation{RuleDigest, fmt.Sprintf("image %s in %s is not pinned by a @sha256: digest", image, where)})
+	}
+	return violations
+}
+
+// ParseImageReference splits an image reference into its repository, tag and digest.
+// The tag separator is only looked for after the last "/", so a registry port such as
+// localhost:5000/app is not mistaken for a tag.
+func ParseImageReference(image string) (repository, tag, digest string) {
+	repository = image
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository, digest = repository[:i], repository[i+1:]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	return repository, tag, digest
+}
@@ -0,0 +1,22 @@
+package imagepolicy
+
+import "testing"
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image, repository, tag, digest string
+	}{
+		{"nginx", "nginx", "", ""},
+		{"nginx:1.27", "nginx", "1.27", ""},
+		{"localhost:5000/app", "localhost:5000/app", "", ""},
+		{"localhost:5000/app:v1", "localhost:5000/app", "v1", ""},
+		{"localhost:5000/app@sha256:abc", "localhost:5000/app", "", "sha256:abc"},
+		{"registry.io/team/app:v1@sha256:abc", "registry.io/team/app", "v1", "sha256:abc"},
+	}
+	for _, tt := range tests {
+		repository, tag, digest := ParseImageReference(tt.image)
+		if repository != tt.repository || tag != tt.tag || digest != tt.digest {
+			t.Errorf("ParseImageReference(%q) = %q, %q, %q, want %q, %q, %q", tt.image, repository, tag, digest, tt.repository, tt.tag, tt.digest)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package imagepolicy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Workload is a decoded object that runs pods, with its pod spec and the JSON pointer of the pod spec in the object.
+type Workload struct {
+	Kind     string
+	Object   metav1.Object
+	PodSpec  *corev1.PodSpec
+	SpecPath string
+}
+
+// WorkloadKinds lists the kinds DecodeWorkload supports.
+const WorkloadKinds = "Deployment, StatefulSet, DaemonSet, ReplicaSet, Job, CronJob or Pod"
+
+// IsWorkloadKind reports whether DecodeWorkload supports kind.
+func IsWorkloadKind(kind string) bool {
+	switch kind {
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job", "CronJob", "Pod":
+		return true
+	}
+	return false
+}
+
+// DecodeWorkload decodes raw as an object of kind and extracts its pod spec.
+func DecodeWorkload(kind string, raw []byte) (*Workload, error) {
+	decode := func(object interface{}) error {
+		if err := json.Unmarshal(raw, object); err != nil {
+			return fmt.Errorf("invalid %s: %w", kind, err)
+		}
+		return nil
+	}
+	switch kind {
+	case "Deployment":
+		var deployment appsv1.Deployment
+		if err := decode(&deployment); err != nil {
+			return nil, err
+		}
+		return &Workload{Kind: kind, Object: &deployment, PodSpec: &deployment.Spec.Template.Spec, SpecPath: "/spec/template/spec"}, nil
+	case "StatefulSet":
+		var statefulSet appsv1.StatefulSet
+		if err := decode(&statefulSet); err != nil {
+			return nil, err
+		}
+		return &Workload{Kind: kind, Object: &statefulSet, PodSpec: &statefulSet.Spec.Template.Spec, SpecPath: "/spec/template/spec"}, nil
+	case "DaemonSet":
+		var daemonSet appsv1.DaemonSet
+		if err := decode(&daemonSet); err != nil {
+			return nil, err
+		}
+		return &Workload{Kind: kind, Object: &daemonSet, PodSpec: &daemonSet.Spec.Template.Spec, SpecPath: "/spec/template/spec"}, nil
+	case "ReplicaSet":
+		var replicaSet appsv1.ReplicaSet
+		if err := decode(&replicaSet); err != nil {
+			return nil, err
+		}
+		return &Workload{Kind: kind, Object: &replicaSet, PodSpec: &replicaSet.Spec.Template.Spec, SpecPath: "/spec/template/spec"}, nil
+	case "Job":
+		var job batchv1.Job
+		if err := decode(&job); err != nil {
+			return nil, err
+		}
+		return &Workload{Kind: kind, Object: &job, PodSpec: &job.Spec.Template.Spec, SpecPath: "/spec/template/spec"}, nil
+	case "CronJob":
+		var cronJob batchv1.CronJob
+		if err := decode(&cronJob); err != nil {
+			return nil, err
+		}
+		return &Workload{Kind: kind, Object: &cronJob, PodSpec: &cronJob.Spec.JobTemplate.Spec.Template.Spec, SpecPath: "/spec/jobTemplate/spec/template/spec"}, nil
+	case "Pod":
+		var pod corev1.Pod
+		if err := decode(&pod); err != nil {
+			return nil, err
+		}
+		return &Workload{Kind: kind, Object: &pod, PodSpec: &pod.Spec, SpecPath: "/spec"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported kind %q, expected %s", kind, WorkloadKinds)
+	}
+}
+
+// ContainerImage is an image of a pod spec along with the container using it, e.g. "init container migrate".
+type ContainerImage struct {
+	Container string
+	Image     string
+}
+
+// PodSpecImages returns the images of every init container, container and ephemeral container of the pod spec.
+func PodSpecImages(podSpec *corev1.PodSpec) []ContainerImage {
+	var images []ContainerImage
+	for _, container := range podSpec.InitContainers {
+		images = append(images, ContainerImage{Container: "init container " + container.Name, Image: container.Image})
+	}
+	for _, container := range podSpec.Containers {
+		images = append(images, ContainerImage{Container: "container " + container.Name, Image: container.Image})
+	}
+	for _, container := range podSpec.EphemeralContainers {
+		images = append(images, ContainerImage{Container: "ephemeral container " + container.Name, Image: container.Image})
+	}
+	return images
+}
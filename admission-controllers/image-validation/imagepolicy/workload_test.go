@@ -0,0 +1,58 @@
+package imagepolicy
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDecodeWorkloadExtractsImages(t *testing.T) {
+	podSpec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "migrate", Image: "migrate:1"}},
+		Containers:     []corev1.Container{{Name: "app", Image: "app:1"}},
+	}
+	template := corev1.PodTemplateSpec{Spec: podSpec}
+	tests := map[string]struct {
+		object   interface{}
+		specPath string
+	}{
+		"Deployment":  {object: appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: template}}, specPath: "/spec/template/spec"},
+		"StatefulSet": {object: appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Template: template}}, specPath: "/spec/template/spec"},
+		"DaemonSet":   {object: appsv1.DaemonSet{Spec: appsv1.DaemonSetSpec{Template: template}}, specPath: "/spec/template/spec"},
+		"ReplicaSet":  {object: appsv1.ReplicaSet{Spec: appsv1.ReplicaSetSpec{Template: template}}, specPath: "/spec/template/spec"},
+		"Job":         {object: batchv1.Job{Spec: batchv1.JobSpec{Template: template}}, specPath: "/spec/template/spec"},
+		"CronJob": {
+			object:   batchv1.CronJob{Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: template}}}},
+			specPath: "/spec/jobTemplate/spec/template/spec",
+		},
+		"Pod": {object: corev1.Pod{Spec: podSpec}, specPath: "/spec"},
+	}
+
+	want := []ContainerImage{{Container: "init container migrate", Image: "migrate:1"}, {Container: "container app", Image: "app:1"}}
+	for kind, tc := range tests {
+		t.Run(kind, func(t *testing.T) {
+			raw, err := json.Marshal(tc.object)
+			if err != nil {
+				t.Fatal(err)
+			}
+			workload, err := DecodeWorkload(kind, raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if workload.SpecPath != tc.specPath {
+				t.Errorf("spec path = %s, want %s", workload.SpecPath, tc.specPath)
+			}
+			if got := PodSpecImages(workload.PodSpec); !reflect.DeepEqual(got, want) {
+				t.Errorf("images = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	if _, err := DecodeWorkload("Service", []byte(`{}`)); err == nil {
+		t.Error("expected an error for an unsupported kind")
+	}
+}
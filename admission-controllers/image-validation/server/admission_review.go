@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// readAdmissionReview decodes an admission.k8s.io/v1 or v1beta1 AdmissionReview and returns its request
+// as v1, along with the apiVersion the response has to be sent back in. The apiVersion is empty when
+// the body is not recognized as a v1beta1 review.
+func readAdmissionReview(r *http.Request) (*admissionv1.AdmissionRequest, string, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading request body: %w", err)
+	}
+	if len(body) == 0 {
+		return nil, "", fmt.Errorf("empty request body, expected an AdmissionReview")
+	}
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(body, &typeMeta); err != nil {
+		return nil, "", fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	switch typeMeta.APIVersion {
+	case admissionv1beta1.SchemeGroupVersion.String():
+		var review admissionv1beta1.AdmissionReview
+		if err := json.Unmarshal(body, &review); err != nil {
+			return nil, typeMeta.APIVersion, fmt.Errorf("invalid AdmissionReview: %w", err)
+		}
+		if review.Request == nil {
+			return nil, typeMeta.APIVersion, fmt.Errorf("AdmissionReview has no request")
+		}
+		req := review.Request
+		return &admissionv1.AdmissionRequest{
+			UID:         req.UID,
+			Kind:        req.Kind,
+			Resource:    req.Resource,
+			SubResource: req.SubResource,
+			Name:        req.Name,
+			Namespace:   req.Namespace,
+			Operation:   admissionv1.Operation(req.Operation),
+			UserInfo:    req.UserInfo,
+			Object:      req.Object,
+			OldObject:   req.OldObject,
+			DryRun:      req.DryRun,
+			Options:     req.Options,
+		}, typeMeta.APIVersion, nil
+	default:
+		// Requests without an apiVersion are treated as v1, the version the webhooks are registered with
+		var review admissionv1.AdmissionReview
+		if err := json.Unmarshal(body, &review); err != nil {
+			return nil, "", fmt.Errorf("invalid AdmissionReview: %w", err)
+		}
+		if review.Request == nil {
+			return nil, "", fmt.Errorf("AdmissionReview has no request")
+		}
+		return review.Request, admissionv1.SchemeGroupVersion.String(), nil
+	}
+}
+
+// reviewCounts counts the served reviews per endpoint and dry run, published through expvar.
+var reviewCounts = expvar.NewMap("admission_reviews")
+
+// recordReview counts a review and reports whether it is a dry run, which must not cause side effects.
+func recordReview(endpoint string, request *admissionv1.AdmissionRequest) bool {
+	dryRun := request.DryRun != nil && *request.DryRun
+	reviewCounts.Add(fmt.Sprintf("%s,dryRun=%t", endpoint, dryRun), 1)
+	return dryRun
+}
+
+// writeAdmissionReview sends the response back as an AdmissionReview in apiVersion.
+func writeAdmissionReview(w http.ResponseWriter, apiVersion string, response *admissionv1.AdmissionResponse) {
+	writeAdmissionReviewWithCode(w, http.StatusOK, apiVersion, response)
+}
+
+// writeBadRequest rejects a request the webhook can't make sense of with a 400 and an AdmissionReview
+// that denies it, so the API server reports message instead of a decoding error.
+// An empty apiVersion answers in admission.k8s.io/v1.
+func writeBadRequest(w http.ResponseWriter, apiVersion string, uid types.UID, message string) {
+	if apiVersion == "" {
+		apiVersion = admissionv1.SchemeGroupVersion.String()
+	}
+	writeAdmissionReviewWithCode(w, http.StatusBadRequest, apiVersion, &admissionv1.AdmissionResponse{
+		UID:     uid,
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusBadRequest,
+			Reason:  metav1.StatusReasonBadRequest,
+			Message: message,
+		},
+	})
+}
+
+func writeAdmissionReviewWithCode(w http.ResponseWriter, code int, apiVersion string, response *admissionv1.AdmissionResponse) {
+	typeMeta := metav1.TypeMeta{APIVersion: apiVersion, Kind: "AdmissionReview"}
+	var responseReview interface{} = admissionv1.AdmissionReview{TypeMeta: typeMeta, Response: response}
+	if apiVersion == admissionv1beta1.SchemeGroupVersion.String() {
+		v1beta1Response := &admissionv1beta1.AdmissionResponse{
+			UID:              response.UID,
+			Allowed:          response.Allowed,
+			Result:           response.Result,
+			Patch:            response.Patch,
+			AuditAnnotations: response.AuditAnnotations,
+			Warnings:         response.Warnings,
+		}
+		if response.PatchType != nil {
+			patchType := admissionv1beta1.PatchType(*response.PatchType)
+			v1beta1Response.PatchType = &patchType
+		}
+		responseReview = admissionv1beta1.AdmissionReview{TypeMeta: typeMeta, Response: v1beta1Response}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	data, _ := json.Marshal(responseReview)
+	w.WriteHeader(code)
+	w.Write(data)
+}
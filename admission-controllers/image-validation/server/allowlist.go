@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/harsha3330/kubernetes/admission-controller/image-validation/imagepolicy"
+)
+
+// registryAllowlist holds the registry rules validateImage allows.
+var registryAllowlist = imagepolicy.NewStaticAllowlist(imagepolicy.PrefixRules(imagepolicy.DefaultRegistryAllowlist))
+
+// watchAllowlist reloads the allowlist file every interval until stop is closed.
+func watchAllowlist(allowlist *imagepolicy.Allowlist, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			reloaded, err := allowlist.Reload()
+			if err != nil {
+				logger.PrintError(err, map[string]string{"allowlist": allowlist.File()})
+				continue
+			}
+			if reloaded {
+				logger.PrintInfo("Reloaded registry allowlist", map[string]string{
+					"allowlist":  allowlist.File(),
+					"registries": fmt.Sprint(allowlist.Rules()),
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log"
+	"net/http"
+)
+
+// debugVarsHandler serves the expvar counters on /debug/vars.
+func debugVarsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+// serveDebugVars serves the expvar counters on address until ctx is done. They get a listener of their own
+// because expvar also publishes the command line and the memory stats, which the webhook port must not expose.
+func serveDebugVars(ctx context.Context, address string) {
+	server := &http.Server{Addr: address, Handler: debugVarsHandler()}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	log.Printf("Serving /debug/vars on %s\n", address)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Debug vars listener stopped: %v\n", err)
+	}
+}
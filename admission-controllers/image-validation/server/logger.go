@@ -1,10 +1,14 @@
-package main
+package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -38,6 +42,19 @@ func (str StringLevel) GetLevel() Level {
 	}
 }
 
+// ParseLevel parses a level name such as "info", case insensitively.
+func ParseLevel(name string) (Level, error) {
+	for level := LevelDebug; level < LevelOff; level++ {
+		if strings.EqualFold(name, level.String()) {
+			return level, nil
+		}
+	}
+	if strings.EqualFold(name, "OFF") {
+		return LevelOff, nil
+	}
+	return LevelDebug, fmt.Errorf("unknown log level %q, expected debug, info, error, fatal or off", name)
+}
+
 func (l Level) String() string {
 	switch l {
 	case LevelDebug:
@@ -53,16 +70,35 @@ func (l Level) String() string {
 	}
 }
 
+// Format is the encoding of log lines.
+type Format string
+
+const (
+	FormatJSON Format = "json" // One JSON object per line.
+	FormatText Format = "text" // Time, level and message followed by key=value properties.
+)
+
+// ParseFormat parses a format name, json or text.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case FormatJSON, FormatText:
+		return format, nil
+	}
+	return FormatJSON, fmt.Errorf("unknown log format %q, expected json or text", name)
+}
+
 type Logger struct {
 	out      io.Writer
 	minLevel Level
+	format   Format
 	mu       sync.Mutex
 }
 
-func NewLogger(out io.Writer, minLevel Level) *Logger {
+func NewLogger(out io.Writer, minLevel Level, format Format) *Logger {
 	return &Logger{
 		out:      out,
 		minLevel: minLevel,
+		format:   format,
 	}
 }
 
@@ -107,9 +143,14 @@ func (l *Logger) print(level Level, message string, properties map[string]string
 
 	var line []byte
 
-	line, err := json.Marshal(aux)
-	if err != nil {
-		line = []byte(LevelError.String() + ": unable to marshal log message:" + err.Error())
+	if l.format == FormatText {
+		line = textLine(aux.Time, aux.Level, aux.Message, aux.Properties, aux.Trace)
+	} else {
+		var err error
+		line, err = json.Marshal(aux)
+		if err != nil {
+			line = []byte(LevelError.String() + ": unable to marshal log message:" + err.Error())
+		}
 	}
 
 	l.mu.Lock()
@@ -121,3 +162,26 @@ func (l *Logger) print(level Level, message string, properties map[string]string
 func (l *Logger) Write(message []byte) (n int, err error) {
 	return l.print(LevelError, string(message), nil)
 }
+
+// textLine formats a log line as its time, level and message followed by the properties as
+// key=value pairs in key order. Values with spaces or quotes are quoted, the trace goes on the next lines.
+func textLine(time, level, message string, properties map[string]string, trace string) []byte {
+	var b strings.Builder
+	b.WriteString(time + " " + level + " " + message)
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		value := properties[key]
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		b.WriteString(" " + key + "=" + value)
+	}
+	if trace != "" {
+		b.WriteString("\n" + strings.TrimRight(trace, "\n"))
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/harsha3330/kubernetes/admission-controller/image-validation/imagepolicy"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// imagePullSecret is the secret injected into workloads pulling from an allowed private registry, empty disables the injection.
+var imagePullSecret string
+
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// imagePullSecretPatch returns the JSON patch adding imagePullSecret to the pod spec when one of its
+// images is from an allowed private registry and the secret is not referenced yet.
+func imagePullSecretPatch(podSpec *corev1.PodSpec, specPath string) []jsonPatchOperation {
+	if imagePullSecret == "" {
+		return nil
+	}
+	usesPrivateRegistry := false
+	for _, image := range imagepolicy.PodSpecImages(podSpec) {
+		if validateImage(image.Image) {
+			usesPrivateRegistry = true
+		}
+	}
+	if !usesPrivateRegistry {
+		return nil
+	}
+	for _, ref := range podSpec.ImagePullSecrets {
+		if ref.Name == imagePullSecret {
+			return nil
+		}
+	}
+
+	secretRef := corev1.LocalObjectReference{Name: imagePullSecret}
+	if len(podSpec.ImagePullSecrets) == 0 {
+		return []jsonPatchOperation{{Op: "add", Path: specPath + "/imagePullSecrets", Value: []corev1.LocalObjectReference{secretRef}}}
+	}
+	return []jsonPatchOperation{{Op: "add", Path: specPath + "/imagePullSecrets/-", Value: secretRef}}
+}
+
+// mutateWorkload injects the configured imagePullSecret into workloads using a private registry.
+// It never denies a request, the validation is left to /validate/workload.
+func mutateWorkload(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	request, apiVersion, err := readAdmissionReview(r)
+	if err != nil {
+		logger.PrintError(err, map[string]string{"path": r.URL.Path})
+		writeBadRequest(w, apiVersion, "", err.Error())
+		return
+	}
+
+	dryRun := recordReview("mutate-workload", request)
+
+	admissionResponse := &admissionv1.AdmissionResponse{
+		UID:     request.UID,
+		Allowed: true,
+	}
+	workload, err := imagepolicy.DecodeWorkload(request.Kind.Kind, request.Object.Raw)
+	if err != nil {
+		logger.PrintError(err, map[string]string{"requestId": string(request.UID), "dryRun": strconv.FormatBool(dryRun)})
+	} else if patch := imagePullSecretPatch(workload.PodSpec, workload.SpecPath); len(patch) > 0 && dryRun {
+		logger.PrintInfo("Skipped imagePullSecret injection for a dry run", map[string]string{
+			"requestId": string(request.UID),
+			"kind":      request.Kind.Kind,
+			"name":      request.Name,
+			"namespace": request.Namespace,
+			"dryRun":    "true",
+		})
+	} else if len(patch) > 0 {
+		data, _ := json.Marshal(patch)
+		patchType := admissionv1.PatchTypeJSONPatch
+		admissionResponse.Patch = data
+		admissionResponse.PatchType = &patchType
+		logger.PrintInfo("Injected imagePullSecret", map[string]string{
+			"requestId": string(request.UID),
+			"kind":      request.Kind.Kind,
+			"name":      request.Name,
+			"namespace": request.Namespace,
+			"secret":    imagePullSecret,
+			"dryRun":    "false",
+		})
+	}
+
+	writeAdmissionReview(w, apiVersion, admissionResponse)
+}
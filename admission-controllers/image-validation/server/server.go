@@ -0,0 +1,431 @@
+// Package server implements the image-validation admission webhook: the validating and mutating
+// handlers, the image policy flags and the HTTP server serving them.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harsha3330/kubernetes/admission-controller/image-validation/imagepolicy"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var logger Logger
+
+// Reasons set on the AdmissionResponse status of a denied workload.
+const (
+	reasonDisallowedImage   metav1.StatusReason = "DisallowedImage"
+	reasonMissingAnnotation metav1.StatusReason = "MissingRequiredAnnotation"
+)
+
+// denyLatestTag rejects images using the latest tag or no tag at all.
+var denyLatestTag bool
+
+// requireDigest rejects images that are not pinned by a sha256 digest.
+var requireDigest bool
+
+// ruleRequiredAnnotation is the --required-annotation check, configured along with the image policy rules.
+const ruleRequiredAnnotation imagepolicy.Rule = "required-annotation"
+
+// warnOnlyRules are rules whose violations are returned as admission warnings instead of denying the workload.
+var warnOnlyRules map[imagepolicy.Rule]bool
+
+// exemptNamespaces are namespaces whose workloads are not validated.
+var exemptNamespaces []string
+
+// exemptKey is a label or annotation key that exempts a workload from validation when set to "true", empty disables it.
+var exemptKey string
+
+// requiredAnnotation is an annotation that must be present and non-empty on every Deployment, empty disables the check.
+var requiredAnnotation string
+
+func loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.PrintInfo("[Middleware] Request Details", map[string]string{
+			"method":  r.Method,
+			"path":    string(r.URL.Path),
+			"address": r.RemoteAddr,
+		})
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validateImage reports whether the image is from a registry of the allowlist, any other image is public.
+func validateImage(image string) bool {
+	return imagepolicy.ImageAllowed(registryAllowlist.Rules(), image)
+}
+
+// imagePolicy returns the image policy configured by the flags, with the current allowlist.
+func imagePolicy() imagepolicy.Policy {
+	return imagepolicy.Policy{
+		Rules:         registryAllowlist.Rules(),
+		DenyLatestTag: denyLatestTag,
+		RequireDigest: requireDigest,
+	}
+}
+
+// parseWarnOnlyRules parses a comma separated list of rules, "all" selects every rule.
+func parseWarnOnlyRules(value string) (map[imagepolicy.Rule]bool, error) {
+	known := []imagepolicy.Rule{imagepolicy.RuleRegistry, imagepolicy.RuleLatestTag, imagepolicy.RuleDigest, ruleRequiredAnnotation}
+	rules := map[imagepolicy.Rule]bool{}
+	for _, name := range strings.Split(value, ",") {
+		rule := imagepolicy.Rule(strings.TrimSpace(name))
+		switch {
+		case rule == "":
+		case rule == "all":
+			for _, r := range known {
+				rules[r] = true
+			}
+		case slices.Contains(known, rule):
+			rules[rule] = true
+		default:
+			return nil, fmt.Errorf("unknown rule %q, expected one of %v or all", rule, known)
+		}
+	}
+	return rules, nil
+}
+
+// splitViolations returns the messages of the violations of enforced rules, and of warn-only rules.
+func splitViolations(violations []imagepolicy.Violation) (reasons, warnings []string) {
+	for _, violation := range violations {
+		if warnOnlyRules[violation.Rule] {
+			warnings = append(warnings, violation.Message)
+		} else {
+			reasons = append(reasons, violation.Message)
+		}
+	}
+	return reasons, warnings
+}
+
+// validatePodSpec checks every container, init container and ephemeral container image of the pod spec.
+// It returns whether all images are allowed and a reason for each image that is not, violations of
+// warn-only rules don't deny the images and are returned as warnings.
+func validatePodSpec(podSpec *corev1.PodSpec) (bool, []string, []string) {
+	reasons, warnings := splitViolations(imagePolicy().PodSpecViolations(podSpec))
+	return len(reasons) == 0, reasons, warnings
+}
+
+// exempted reports whether the object is in an exempt namespace or opts out with the exempt label or annotation.
+func exempted(object metav1.Object) bool {
+	if slices.Contains(exemptNamespaces, object.GetNamespace()) {
+		return true
+	}
+	if exemptKey == "" {
+		return false
+	}
+	return object.GetLabels()[exemptKey] == "true" || object.GetAnnotations()[exemptKey] == "true"
+}
+
+// validateWorkloadObject runs the pod spec checks on a workload, and the required annotation check
+// when it is a Deployment. It returns whether the workload is allowed, the reasons it is not and the
+// warnings of warn-only rules. Exempted workloads are always allowed.
+func validateWorkloadObject(w *imagepolicy.Workload) (bool, []string, []string) {
+	if exempted(w.Object) {
+		return true, nil, nil
+	}
+	violations := imagePolicy().PodSpecViolations(w.PodSpec)
+	if w.Kind == "Deployment" && requiredAnnotation != "" && w.Object.GetAnnotations()[requiredAnnotation] == "" {
+		violations = append(violations, imagepolicy.Violation{
+			Rule:    ruleRequiredAnnotation,
+			Message: fmt.Sprintf("deployment must set the %q annotation to the base image digest recorded by the build pipeline", requiredAnnotation),
+		})
+	}
+	reasons, warnings := splitViolations(violations)
+	return len(reasons) == 0, reasons, warnings
+}
+
+// workloadOf decodes the workload under review. The kind comes from the request, then from the object
+// itself, and defaults to Deployment for reviews sent to /validate/deployment without either.
+// Reviews of the pods/ephemeralcontainers subresource, used by kubectl debug, carry the whole Pod.
+// It fails when the request carries no object rather than validating an empty workload, and for any
+// other subresource.
+func workloadOf(request *admissionv1.AdmissionRequest) (*imagepolicy.Workload, error) {
+	if len(request.Object.Raw) == 0 {
+		return nil, fmt.Errorf("AdmissionReview request has no object")
+	}
+	kind := request.Kind.Kind
+	switch request.SubResource {
+	case "":
+	case "ephemeralcontainers":
+		kind = "Pod"
+	default:
+		return nil, fmt.Errorf("unsupported subresource %q, only ephemeralcontainers is validated", request.SubResource)
+	}
+	if kind == "" {
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(request.Object.Raw, &typeMeta); err != nil {
+			return nil, fmt.Errorf("invalid object: %w", err)
+		}
+		kind = typeMeta.Kind
+	}
+	if kind == "" {
+		kind = "Deployment"
+	}
+	w, err := imagepolicy.DecodeWorkload(kind, request.Object.Raw)
+	if err != nil {
+		return nil, err
+	}
+	if w.Object.GetNamespace() == "" {
+		// The namespace is not set on the object yet when it is created without one
+		w.Object.SetNamespace(request.Namespace)
+	}
+	return w, nil
+}
+
+// validateWorkload validates the images of any workload kind imagepolicy.DecodeWorkload supports.
+// It is served on /validate/workload and on /validate/deployment, kept for existing webhook configurations.
+func validateWorkload(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	request, apiVersion, err := readAdmissionReview(r)
+	if err != nil {
+		logger.PrintError(err, map[string]string{"path": r.URL.Path})
+		writeBadRequest(w, apiVersion, "", err.Error())
+		return
+	}
+	workload, err := workloadOf(request)
+	if err != nil {
+		logger.PrintError(err, map[string]string{"requestId": string(request.UID)})
+		writeBadRequest(w, apiVersion, request.UID, err.Error())
+		return
+	}
+
+	dryRun := recordReview("validate-workload", request)
+	validationFlag, reasons, warnings := validateWorkloadObject(workload)
+
+	logger.PrintInfo("Validated Workload Images", map[string]string{
+		"requestId":   string(request.UID),
+		"dryRun":      strconv.FormatBool(dryRun),
+		"validation":  fmt.Sprintf("%v", validationFlag),
+		"kind":        workload.Kind,
+		"subresource": request.SubResource,
+		"name":        workload.Object.GetName(),
+		"namespace":   workload.Object.GetNamespace(),
+	})
+
+	admissionResponse := &admissionv1.AdmissionResponse{
+		UID:      request.UID,
+		Allowed:  validationFlag,
+		Warnings: warnings,
+	}
+	if !validationFlag {
+		reason := reasonMissingAnnotation
+		if imagesAllowed, _, _ := validatePodSpec(workload.PodSpec); !imagesAllowed {
+			reason = reasonDisallowedImage
+		}
+		admissionResponse.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusForbidden,
+			Reason:  reason,
+			Message: strings.Join(reasons, "; "),
+		}
+	}
+
+	writeAdmissionReview(w, apiVersion, admissionResponse)
+}
+
+// testValidationResult is the response of the /test/validate endpoint.
+type testValidationResult struct {
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name"`
+	Allowed  bool     `json:"allowed"`
+	Reasons  []string `json:"reasons,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// testValidate runs the validation on a raw workload, without an AdmissionReview envelope,
+// so the policy can be tried out without going through a real admission.
+func testValidate(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(body, &typeMeta); err != nil {
+		http.Error(w, fmt.Sprintf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	workload, err := imagepolicy.DecodeWorkload(typeMeta.Kind, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result := testValidationResult{Kind: workload.Kind, Name: workload.Object.GetName()}
+	result.Allowed, result.Reasons, result.Warnings = validateWorkloadObject(workload)
+
+	w.Header().Set("Content-Type", "application/json")
+	data, _ := json.Marshal(result)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func health(w http.ResponseWriter, r *http.Request) {
+	data := map[string]string{
+		"status": "healthy",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if shuttingDown.Load() {
+		// Stop the API server from routing new reviews to a draining pod
+		data["status"] = "shutting down"
+		jsonData, _ := json.Marshal(data)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write(jsonData)
+		return
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		http.Error(w, "failed to marshal JSON", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
+
+// Options configures the server, the handler settings are bound by BindFlags directly. The zero
+// LogLevel and LogFormat log everything as JSON.
+type Options struct {
+	Port                    string
+	EnableTestEndpoint      bool
+	TLSCertFile             string
+	TLSKeyFile              string
+	ShutdownDelay           time.Duration
+	ShutdownGracePeriod     time.Duration
+	CertReloadInterval      time.Duration
+	RegistryAllowlistFile   string
+	AllowlistReloadInterval time.Duration
+	DebugVarsAddress        string
+	LogLevel                Level
+	LogFormat               Format
+}
+
+// BindFlags registers the server, image policy and log format flags on fs.
+func (o *Options) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Port, "port", "8080", "Port to run the HTTP server on")
+	fs.BoolVar(&o.EnableTestEndpoint, "enable-test-endpoint", false, "Serve /test/validate to dry-run the validation on a raw workload such as a Deployment or Pod")
+	fs.StringVar(&requiredAnnotation, "required-annotation", "", "Annotation every Deployment must set to a non-empty value, e.g. image.policy/base-digest. Empty disables the check")
+	fs.Func("exempt-namespaces", "Comma separated namespaces whose Deployments are not validated, e.g. monitoring", func(value string) error {
+		exemptNamespaces = nil
+		for _, ns := range strings.Split(value, ",") {
+			if ns = strings.TrimSpace(ns); ns != "" {
+				exemptNamespaces = append(exemptNamespaces, ns)
+			}
+		}
+		return nil
+	})
+	fs.StringVar(&exemptKey, "exempt-key", "", "Label or annotation key that exempts a workload from validation when set to \"true\", e.g. image-validation/exempt. Empty, the default, disables the opt-out")
+	fs.BoolVar(&denyLatestTag, "deny-latest-tag", false, "Reject images using the latest tag or no tag at all")
+	fs.BoolVar(&requireDigest, "require-digest", false, "Reject images that are not pinned by a @sha256: digest")
+	fs.Func("warn-only", "Comma separated rules whose violations are returned as admission warnings instead of denying the workload: registry, latest-tag, digest, required-annotation or all", func(value string) error {
+		rules, err := parseWarnOnlyRules(value)
+		if err != nil {
+			return err
+		}
+		warnOnlyRules = rules
+		return nil
+	})
+	fs.StringVar(&imagePullSecret, "image-pull-secret", "", "imagePullSecret injected by /mutate/workload into Deployments and Pods using a private registry image. Empty disables the injection")
+	fs.StringVar(&o.TLSCertFile, "tls-cert-file", "", "TLS certificate file, the server uses HTTPS when set together with --tls-key-file")
+	fs.StringVar(&o.TLSKeyFile, "tls-key-file", "", "TLS private key file, the server uses HTTPS when set together with --tls-cert-file")
+	fs.DurationVar(&o.ShutdownDelay, "shutdown-delay", 5*time.Second, "How long requests are still served after SIGINT or SIGTERM while the health endpoint reports 503, so the pod leaves the endpoints first")
+	fs.StringVar(&o.DebugVarsAddress, "debug-vars-address", "", "Address of a separate plain HTTP listener serving the review counters on /debug/vars, e.g. 127.0.0.1:6060. expvar also publishes the command line and memory stats there. Empty, the default, disables it")
+	fs.DurationVar(&o.ShutdownGracePeriod, "shutdown-grace-period", 10*time.Second, "How long in-flight requests are given to finish on SIGINT or SIGTERM")
+	fs.DurationVar(&o.CertReloadInterval, "cert-reload-interval", time.Minute, "How often the TLS certificate and key files are checked for changes")
+	fs.StringVar(&o.RegistryAllowlistFile, "registry-allowlist", "", "File with one allowed registry prefix per line, or a regular expression prefixed with re:, # starts a comment. Empty uses the built-in "+strings.Join(imagepolicy.DefaultRegistryAllowlist, ","))
+	fs.DurationVar(&o.AllowlistReloadInterval, "registry-allowlist-reload-interval", 30*time.Second, "How often the --registry-allowlist file is checked for changes")
+	fs.Func("log-format", "Format of the log lines: json or text (default json)", func(value string) (err error) {
+		o.LogFormat, err = ParseFormat(value)
+		return err
+	})
+}
+
+// BindLogLevelFlag registers --log-level on fs, a command sharing its logging flags with other
+// commands sets LogLevel itself instead.
+func (o *Options) BindLogLevelFlag(fs *flag.FlagSet) {
+	fs.Func("log-level", "Lowest level logged: debug, info, error, fatal or off (default debug)", func(value string) (err error) {
+		o.LogLevel, err = ParseLevel(value)
+		return err
+	})
+}
+
+// Run serves the webhook until ctx is done, then drains it.
+func Run(ctx context.Context, o Options) error {
+	logger = *NewLogger(os.Stdout, o.LogLevel, o.LogFormat)
+	if (o.TLSCertFile == "") != (o.TLSKeyFile == "") {
+		return errors.New("--tls-cert-file and --tls-key-file must be set together")
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", health)
+	mux.HandleFunc("/validate/workload", validateWorkload)
+	mux.HandleFunc("/validate/deployment", validateWorkload)
+	mux.HandleFunc("/mutate/workload", mutateWorkload)
+	if o.EnableTestEndpoint {
+		mux.HandleFunc("/test/validate", testValidate)
+	}
+
+	wrapper := trackInFlight(loggingMiddleware(mux))
+	server := http.Server{
+		Addr:    ":" + o.Port,
+		Handler: wrapper,
+	}
+
+	if o.DebugVarsAddress != "" {
+		go serveDebugVars(ctx, o.DebugVarsAddress)
+	}
+
+	if o.RegistryAllowlistFile != "" {
+		allowlist, err := imagepolicy.LoadAllowlist(o.RegistryAllowlistFile)
+		if err != nil {
+			return err
+		}
+		if len(allowlist.Rules()) == 0 {
+			log.Printf("Registry allowlist %s has no entries, every image is denied\n", o.RegistryAllowlistFile)
+		}
+		registryAllowlist = allowlist
+		go watchAllowlist(allowlist, o.AllowlistReloadInterval, ctx.Done())
+	}
+
+	serveFn := server.ListenAndServe
+	if o.TLSCertFile == "" {
+		log.Printf("Starting server on port %s\n", o.Port)
+	} else {
+		reloader, err := newCertReloader(o.TLSCertFile, o.TLSKeyFile)
+		if err != nil {
+			return err
+		}
+		go reloader.watch(o.CertReloadInterval, ctx.Done())
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+		serveFn = func() error { return server.ListenAndServeTLS("", "") }
+		log.Printf("Starting TLS server on port %s\n", o.Port)
+	}
+
+	return serve(ctx, &server, serveFn, o.ShutdownDelay, o.ShutdownGracePeriod)
+}
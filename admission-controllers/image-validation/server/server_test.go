@@ -0,0 +1,856 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"expvar"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harsha3330/kubernetes/admission-controller/image-validation/imagepolicy"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func init() {
+	logger = *NewLogger(io.Discard, LevelOff, FormatJSON)
+}
+
+func newDeployment(images ...string) *appsv1.Deployment {
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+	}
+	for _, image := range images {
+		deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers,
+			corev1.Container{Name: "c", Image: image})
+	}
+	return deployment
+}
+
+// reviewDeployment sends the deployment through the real webhook path and returns the response.
+func reviewDeployment(t *testing.T, deployment *appsv1.Deployment) *admissionv1.AdmissionResponse {
+	t.Helper()
+	raw, err := json.Marshal(deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{UID: "req-1", Object: runtime.RawExtension{Raw: raw}},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	validateWorkload(rec, httptest.NewRequest(http.MethodPost, "/validate/deployment", bytes.NewReader(body)))
+
+	var response admissionv1.AdmissionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding admission review: %v", err)
+	}
+	return response.Response
+}
+
+func TestTestValidateMatchesWebhook(t *testing.T) {
+	tests := map[string]*appsv1.Deployment{
+		"private image": newDeployment("095728565421.dkr.ecr.ap-south-1.amazonaws.com/app:1.0"),
+		"public image":  newDeployment("095728565421.dkr.ecr.ap-south-1.amazonaws.com/app:1.0", "nginx:latest"),
+	}
+
+	for name, deployment := range tests {
+		t.Run(name, func(t *testing.T) {
+			raw, err := json.Marshal(deployment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			testValidate(rec, httptest.NewRequest(http.MethodPost, "/test/validate", bytes.NewReader(raw)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+			}
+
+			var result testValidationResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatal(err)
+			}
+			webhook := reviewDeployment(t, deployment)
+			if result.Allowed != webhook.Allowed {
+				t.Errorf("test endpoint allowed = %v, webhook allowed = %v", result.Allowed, webhook.Allowed)
+			}
+			if !result.Allowed && len(result.Reasons) == 0 {
+				t.Errorf("expected reasons for a denied deployment")
+			}
+		})
+	}
+}
+
+func TestTestValidateRejectsUnknownKind(t *testing.T) {
+	rec := httptest.NewRecorder()
+	body := bytes.NewReader([]byte(`{"apiVersion":"v1","kind":"Service"}`))
+	testValidate(rec, httptest.NewRequest(http.MethodPost, "/test/validate", body))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestRequiredAnnotation(t *testing.T) {
+	requiredAnnotation = "image.policy/base-digest"
+	defer func() { requiredAnnotation = "" }()
+
+	image := "095728565421.dkr.ecr.ap-south-1.amazonaws.com/app:1.0"
+
+	t.Run("missing annotation is denied", func(t *testing.T) {
+		response := reviewDeployment(t, newDeployment(image))
+		if response.Allowed {
+			t.Fatal("expected the deployment to be denied")
+		}
+		if response.Result == nil || !strings.Contains(response.Result.Message, "image.policy/base-digest") {
+			t.Errorf("denial message should name the required annotation, got %+v", response.Result)
+		}
+	})
+
+	t.Run("annotated deployment is allowed", func(t *testing.T) {
+		deployment := newDeployment(image)
+		deployment.Annotations = map[string]string{"image.policy/base-digest": "sha256:abc"}
+		if response := reviewDeployment(t, deployment); !response.Allowed {
+			t.Errorf("expected the deployment to be allowed, got %+v", response.Result)
+		}
+	})
+}
+
+func TestDenialNamesImagesAndContainers(t *testing.T) {
+	deployment := newDeployment("095728565421.dkr.ecr.ap-south-1.amazonaws.com/app:1.0", "nginx:latest", "redis:7")
+	deployment.Spec.Template.Spec.Containers[1].Name = "web"
+	deployment.Spec.Template.Spec.Containers[2].Name = "cache"
+
+	response := reviewDeployment(t, deployment)
+	if response.Allowed {
+		t.Fatal("expected the deployment to be denied")
+	}
+	if response.Result == nil {
+		t.Fatal("expected a denial status")
+	}
+	if response.Result.Reason != reasonDisallowedImage || response.Result.Code != http.StatusForbidden {
+		t.Errorf("expected reason %s with code 403, got %s with %d", reasonDisallowedImage, response.Result.Reason, response.Result.Code)
+	}
+	for _, want := range []string{
+		"image nginx:latest in container web is not from an allowed private registry",
+		"image redis:7 in container cache is not from an allowed private registry",
+	} {
+		if !strings.Contains(response.Result.Message, want) {
+			t.Errorf("expected %q in the denial message, got %q", want, response.Result.Message)
+		}
+	}
+}
+
+// writeKeyPair writes a fresh self-signed certificate for commonName with the given modification time.
+func writeKeyPair(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func servedCommonName(t *testing.T, reloader *certReloader) string {
+	t.Helper()
+	cert, err := reloader.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Subject.CommonName
+}
+
+func TestCertReloaderPicksUpRotatedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	start := time.Now().Add(-time.Hour)
+	writeKeyPair(t, certFile, keyFile, "first", start)
+
+	reloader, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := servedCommonName(t, reloader); got != "first" {
+		t.Fatalf("expected the first certificate, got %q", got)
+	}
+	if reloaded, err := reloader.reload(); err != nil || reloaded {
+		t.Fatalf("expected no reload for unchanged files, got %v, %v", reloaded, err)
+	}
+
+	writeKeyPair(t, certFile, keyFile, "second", start.Add(time.Minute))
+	if reloaded, err := reloader.reload(); err != nil || !reloaded {
+		t.Fatalf("expected a reload after rotation, got %v, %v", reloaded, err)
+	}
+	if got := servedCommonName(t, reloader); got != "second" {
+		t.Fatalf("expected the rotated certificate, got %q", got)
+	}
+
+	if err := os.WriteFile(keyFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reloader.reload(); err == nil {
+		t.Fatal("expected an error for an invalid key")
+	}
+	if got := servedCommonName(t, reloader); got != "second" {
+		t.Fatalf("expected the last good certificate to be kept, got %q", got)
+	}
+}
+
+// mutate sends the object through /mutate/workload and returns the response.
+func mutate(t *testing.T, kind string, object interface{}, dryRun bool) *admissionv1.AdmissionResponse {
+	t.Helper()
+	raw, err := json.Marshal(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{
+			UID:    "req-1",
+			Kind:   metav1.GroupVersionKind{Kind: kind},
+			Object: runtime.RawExtension{Raw: raw},
+			DryRun: &dryRun,
+		},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	mutateWorkload(rec, httptest.NewRequest(http.MethodPost, "/mutate/workload", bytes.NewReader(body)))
+
+	var response admissionv1.AdmissionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding admission review: %v", err)
+	}
+	if !response.Response.Allowed {
+		t.Fatalf("mutation must never deny, got %+v", response.Response.Result)
+	}
+	return response.Response
+}
+
+func TestMutateWorkloadInjectsImagePullSecret(t *testing.T) {
+	imagePullSecret = "ecr-pull"
+	defer func() { imagePullSecret = "" }()
+
+	private := "095728565421.dkr.ecr.ap-south-1.amazonaws.com/app:1.0"
+	withSecrets := func(deployment *appsv1.Deployment, names ...string) *appsv1.Deployment {
+		for _, name := range names {
+			deployment.Spec.Template.Spec.ImagePullSecrets = append(deployment.Spec.Template.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+		}
+		return deployment
+	}
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "c", Image: private}}}}
+
+	tests := []struct {
+		name      string
+		kind      string
+		object    interface{}
+		wantPatch string
+	}{
+		{
+			name:      "deployment without secrets",
+			kind:      "Deployment",
+			object:    newDeployment(private),
+			wantPatch: `[{"op":"add","path":"/spec/template/spec/imagePullSecrets","value":[{"name":"ecr-pull"}]}]`,
+		},
+		{
+			name:      "deployment with another secret",
+			kind:      "Deployment",
+			object:    withSecrets(newDeployment(private), "other"),
+			wantPatch: `[{"op":"add","path":"/spec/template/spec/imagePullSecrets/-","value":{"name":"ecr-pull"}}]`,
+		},
+		{
+			name:   "deployment already referencing the secret",
+			kind:   "Deployment",
+			object: withSecrets(newDeployment(private), "other", "ecr-pull"),
+		},
+		{
+			name:   "public images only",
+			kind:   "Deployment",
+			object: newDeployment("nginx:latest"),
+		},
+		{
+			name:      "pod",
+			kind:      "Pod",
+			object:    pod,
+			wantPatch: `[{"op":"add","path":"/spec/imagePullSecrets","value":[{"name":"ecr-pull"}]}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := mutate(t, tt.kind, tt.object, false)
+			if string(response.Patch) != tt.wantPatch {
+				t.Errorf("expected patch %s, got %s", tt.wantPatch, response.Patch)
+			}
+			if tt.wantPatch != "" && (response.PatchType == nil || *response.PatchType != admissionv1.PatchTypeJSONPatch) {
+				t.Errorf("expected a JSONPatch patch type, got %v", response.PatchType)
+			}
+		})
+	}
+}
+
+func TestTagAndDigestPolicies(t *testing.T) {
+	denyLatestTag, requireDigest = true, true
+	defer func() { denyLatestTag, requireDigest = false, false }()
+
+	registry := "095728565421.dkr.ecr.ap-south-1.amazonaws.com/app"
+	tests := []struct {
+		name    string
+		image   string
+		reasons []string
+	}{
+		{"pinned", registry + ":v1@sha256:abc", nil},
+		{"digest only", registry + "@sha256:abc", nil},
+		{"latest", registry + ":latest", []string{"uses the mutable latest tag", "is not pinned by a @sha256: digest"}},
+		{"no tag", registry, []string{"has no tag, which defaults to the mutable latest tag", "is not pinned by a @sha256: digest"}},
+		{"tagged", registry + ":v1", []string{"is not pinned by a @sha256: digest"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, reasons, _ := validatePodSpec(&corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: tt.image}}})
+			if allowed != (len(tt.reasons) == 0) || len(reasons) != len(tt.reasons) {
+				t.Fatalf("expected reasons %q, got allowed=%v %q", tt.reasons, allowed, reasons)
+			}
+			for i, want := range tt.reasons {
+				if !strings.Contains(reasons[i], want) || !strings.Contains(reasons[i], "in container web") {
+					t.Errorf("expected reason %d to contain %q, got %q", i, want, reasons[i])
+				}
+			}
+		})
+	}
+
+	t.Run("policies are off by default", func(t *testing.T) {
+		denyLatestTag, requireDigest = false, false
+		defer func() { denyLatestTag, requireDigest = true, true }()
+		if allowed, reasons, _ := validatePodSpec(&corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: registry}}}); !allowed {
+			t.Errorf("expected the image to be allowed, got %q", reasons)
+		}
+	})
+}
+
+func TestExemptions(t *testing.T) {
+	exemptNamespaces, exemptKey = []string{"monitoring"}, "image-validation/exempt"
+	defer func() { exemptNamespaces, exemptKey = nil, "" }()
+
+	public := func(namespace string) *appsv1.Deployment {
+		deployment := newDeployment("grafana/grafana:11.0.0")
+		deployment.Namespace = namespace
+		return deployment
+	}
+
+	t.Run("exempt namespace is allowed", func(t *testing.T) {
+		if response := reviewDeployment(t, public("monitoring")); !response.Allowed {
+			t.Errorf("expected the deployment to be allowed, got %+v", response.Result)
+		}
+	})
+
+	t.Run("other namespaces are still validated", func(t *testing.T) {
+		if response := reviewDeployment(t, public("default")); response.Allowed {
+			t.Error("expected the deployment to be denied")
+		}
+	})
+
+	t.Run("opt-out label", func(t *testing.T) {
+		deployment := public("default")
+		deployment.Labels = map[string]string{"image-validation/exempt": "true"}
+		if response := reviewDeployment(t, deployment); !response.Allowed {
+			t.Errorf("expected the deployment to be allowed, got %+v", response.Result)
+		}
+	})
+
+	t.Run("opt-out annotation", func(t *testing.T) {
+		deployment := public("default")
+		deployment.Annotations = map[string]string{"image-validation/exempt": "true"}
+		if response := reviewDeployment(t, deployment); !response.Allowed {
+			t.Errorf("expected the deployment to be allowed, got %+v", response.Result)
+		}
+	})
+
+	t.Run("opt-out must be true", func(t *testing.T) {
+		deployment := public("default")
+		deployment.Labels = map[string]string{"image-validation/exempt": "false"}
+		if response := reviewDeployment(t, deployment); response.Allowed {
+			t.Error("expected the deployment to be denied")
+		}
+	})
+}
+
+func TestAdmissionReviewVersions(t *testing.T) {
+	raw, err := json.Marshal(newDeployment("nginx:latest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := map[string]interface{}{
+		"uid":    "req-1",
+		"kind":   map[string]string{"group": "apps", "version": "v1", "kind": "Deployment"},
+		"object": json.RawMessage(raw),
+	}
+
+	for _, apiVersion := range []string{"admission.k8s.io/v1", "admission.k8s.io/v1beta1"} {
+		t.Run(apiVersion, func(t *testing.T) {
+			body, err := json.Marshal(map[string]interface{}{
+				"apiVersion": apiVersion,
+				"kind":       "AdmissionReview",
+				"request":    request,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rec := httptest.NewRecorder()
+			validateWorkload(rec, httptest.NewRequest(http.MethodPost, "/validate/deployment", bytes.NewReader(body)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var response struct {
+				APIVersion string `json:"apiVersion"`
+				Kind       string `json:"kind"`
+				Response   struct {
+					UID     string         `json:"uid"`
+					Allowed bool           `json:"allowed"`
+					Status  *metav1.Status `json:"status"`
+				} `json:"response"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.APIVersion != apiVersion || response.Kind != "AdmissionReview" {
+				t.Errorf("expected an %s AdmissionReview, got %s %s", apiVersion, response.APIVersion, response.Kind)
+			}
+			if response.Response.UID != "req-1" || response.Response.Allowed {
+				t.Errorf("expected req-1 to be denied, got %+v", response.Response)
+			}
+			if response.Response.Status == nil || response.Response.Status.Reason != reasonDisallowedImage {
+				t.Errorf("expected the denial reason, got %+v", response.Response.Status)
+			}
+		})
+	}
+}
+
+func TestGracefulShutdownDrainsInFlightRequests(t *testing.T) {
+	defer shuttingDown.Store(false)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: trackInFlight(mux)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, server, func() error { return server.Serve(listener) }, 0, 5*time.Second)
+	}()
+
+	responses := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			responses <- 0
+			return
+		}
+		resp.Body.Close()
+		responses <- resp.StatusCode
+	}()
+	<-started
+
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for !shuttingDown.Load() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	rec := httptest.NewRecorder()
+	health(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected the health endpoint to report 503 while shutting down, got %d", rec.Code)
+	}
+
+	close(release)
+	if code := <-responses; code != http.StatusOK {
+		t.Errorf("expected the in-flight request to complete with 200, got %d", code)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestShutdownDelayKeepsServingNewRequests(t *testing.T) {
+	defer shuttingDown.Store(false)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: trackInFlight(mux)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, server, func() error { return server.Serve(listener) }, time.Second, 5*time.Second)
+	}()
+
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for !shuttingDown.Load() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	resp, err := http.Get("http://" + listener.Addr().String() + "/ok")
+	if err != nil {
+		t.Fatalf("expected requests to be served during the shutdown delay, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 during the shutdown delay, got %d", resp.StatusCode)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestMutateWorkloadSkipsDryRun(t *testing.T) {
+	imagePullSecret = "ecr-pull"
+	defer func() { imagePullSecret = "" }()
+
+	deployment := newDeployment("095728565421.dkr.ecr.ap-south-1.amazonaws.com/app:1.0")
+	dryRuns := func() int64 {
+		if count, ok := reviewCounts.Get("mutate-workload,dryRun=true").(*expvar.Int); ok {
+			return count.Value()
+		}
+		return 0
+	}
+	before := dryRuns()
+
+	response := mutate(t, "Deployment", deployment, true)
+	if len(response.Patch) != 0 || response.PatchType != nil {
+		t.Errorf("expected no patch for a dry run, got %s", response.Patch)
+	}
+	if dryRuns() != before+1 {
+		t.Errorf("expected the dry run to be counted once, got %d after %d", dryRuns(), before)
+	}
+}
+
+func TestDebugVarsServeTheReviewCounts(t *testing.T) {
+	rec := httptest.NewRecorder()
+	debugVarsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), `"admission_reviews"`) {
+		t.Errorf("expected the review counts, got %s", rec.Body.String())
+	}
+}
+
+func TestValidateDeploymentRejectsMalformedReviews(t *testing.T) {
+	service, err := json.Marshal(corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceReview, err := json.Marshal(admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{
+			UID:    "req-1",
+			Kind:   metav1.GroupVersionKind{Version: "v1", Kind: "Service"},
+			Object: runtime.RawExtension{Raw: service},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		body    string
+		message string
+	}{
+		"empty body":         {body: "", message: "empty request body"},
+		"truncated JSON":     {body: `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{"uid":`, message: "invalid JSON"},
+		"nil request":        {body: `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview"}`, message: "has no request"},
+		"empty object":       {body: `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{"uid":"req-1"}}`, message: "has no object"},
+		"not a Deployment":   {body: string(serviceReview), message: `unsupported kind "Service"`},
+		"undecodable object": {body: `{"request":{"uid":"req-1","object":{"spec":"replicas"}}}`, message: "invalid Deployment"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			validateWorkload(rec, httptest.NewRequest(http.MethodPost, "/validate/deployment", strings.NewReader(tc.body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+
+			var review admissionv1.AdmissionReview
+			if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+				t.Fatalf("decoding admission review: %v", err)
+			}
+			if review.Response == nil || review.Response.Allowed {
+				t.Fatalf("expected a denied response, got %+v", review.Response)
+			}
+			if review.Response.Result == nil || !strings.Contains(review.Response.Result.Message, tc.message) {
+				t.Errorf("expected a message containing %q, got %+v", tc.message, review.Response.Result)
+			}
+		})
+	}
+}
+
+func TestValidateWorkloadDeniesPublicStatefulSetImage(t *testing.T) {
+	raw, err := json.Marshal(appsv1.StatefulSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1.36"}},
+			Containers:     []corev1.Container{{Name: "db", Image: "095728565421.dkr.ecr.ap-south-1.amazonaws.com/db:1.0"}},
+		}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{
+			UID:    "req-1",
+			Kind:   metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+			Object: runtime.RawExtension{Raw: raw},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	validateWorkload(rec, httptest.NewRequest(http.MethodPost, "/validate/workload", bytes.NewReader(body)))
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+		t.Fatalf("decoding admission review: %v", err)
+	}
+	if review.Response.Allowed {
+		t.Fatal("expected the statefulset to be denied")
+	}
+	if want := "image busybox:1.36 in init container init"; !strings.Contains(review.Response.Result.Message, want) {
+		t.Errorf("expected %q in the denial message, got %q", want, review.Response.Result.Message)
+	}
+}
+
+func TestValidateWorkloadDeniesPublicEphemeralContainerImage(t *testing.T) {
+	pod := corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "095728565421.dkr.ecr.ap-south-1.amazonaws.com/app:1.0"}},
+		},
+	}
+	review := func(t *testing.T, pod corev1.Pod, subResource string) *admissionv1.AdmissionResponse {
+		t.Helper()
+		raw, err := json.Marshal(pod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := json.Marshal(admissionv1.AdmissionReview{
+			Request: &admissionv1.AdmissionRequest{
+				UID:         "req-1",
+				Kind:        metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+				Resource:    metav1.GroupVersionResource{Version: "v1", Resource: "pods"},
+				SubResource: subResource,
+				Operation:   admissionv1.Update,
+				Object:      runtime.RawExtension{Raw: raw},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := httptest.NewRecorder()
+		validateWorkload(rec, httptest.NewRequest(http.MethodPost, "/validate/workload", bytes.NewReader(body)))
+		var response admissionv1.AdmissionReview
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decoding admission review: %v", err)
+		}
+		return response.Response
+	}
+
+	debugged := pod
+	debugged.Spec.EphemeralContainers = []corev1.EphemeralContainer{{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox:1.36"},
+	}}
+	response := review(t, debugged, "ephemeralcontainers")
+	if response.Allowed {
+		t.Fatal("expected the ephemeral container to be denied")
+	}
+	if want := "image busybox:1.36 in ephemeral container debugger"; !strings.Contains(response.Result.Message, want) {
+		t.Errorf("expected %q in the denial message, got %q", want, response.Result.Message)
+	}
+
+	debugged.Spec.EphemeralContainers[0].Image = "095728565421.dkr.ecr.ap-south-1.amazonaws.com/debug:1.0"
+	if response := review(t, debugged, "ephemeralcontainers"); !response.Allowed {
+		t.Errorf("expected a private ephemeral container image to be allowed, got %+v", response.Result)
+	}
+
+	if response := review(t, pod, "status"); response.Allowed {
+		t.Error("expected an unsupported subresource to be rejected")
+	}
+}
+
+func TestWarnOnlyRulesAdmitWithWarnings(t *testing.T) {
+	denyLatestTag = true
+	defer func() { denyLatestTag, warnOnlyRules = false, nil }()
+	rules, err := parseWarnOnlyRules("latest-tag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnOnlyRules = rules
+
+	response := reviewDeployment(t, newDeployment("095728565421.dkr.ecr.ap-south-1.amazonaws.com/app:latest"))
+	if !response.Allowed {
+		t.Fatalf("expected a warn-only violation to be admitted, got %+v", response.Result)
+	}
+	if len(response.Warnings) != 1 || !strings.Contains(response.Warnings[0], "uses the mutable latest tag") {
+		t.Errorf("expected a latest tag warning, got %q", response.Warnings)
+	}
+
+	// Enforced rules still deny, the warnings are returned along with the denial
+	response = reviewDeployment(t, newDeployment("nginx:latest"))
+	if response.Allowed {
+		t.Fatal("expected a public image to be denied")
+	}
+	if response.Result.Reason != reasonDisallowedImage || strings.Contains(response.Result.Message, "latest tag") {
+		t.Errorf("expected only the registry violation to deny, got %+v", response.Result)
+	}
+	if len(response.Warnings) != 1 {
+		t.Errorf("expected the latest tag warning, got %q", response.Warnings)
+	}
+}
+
+func TestParseWarnOnlyRules(t *testing.T) {
+	rules, err := parseWarnOnlyRules("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range []imagepolicy.Rule{imagepolicy.RuleRegistry, imagepolicy.RuleLatestTag, imagepolicy.RuleDigest, ruleRequiredAnnotation} {
+		if !rules[rule] {
+			t.Errorf("expected all to include %s", rule)
+		}
+	}
+	if _, err := parseWarnOnlyRules("latest-tag,no-such-rule"); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
+
+func TestLoggerDropsMessagesBelowLevel(t *testing.T) {
+	level, err := ParseLevel("info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	l := NewLogger(&out, level, FormatJSON)
+	l.PrintDebug("debug noise", nil)
+	l.PrintInfo("served", map[string]string{"path": "/validate/workload"})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 || strings.Contains(out.String(), "debug noise") {
+		t.Fatalf("expected only the info message, got %q", out.String())
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
+
+func TestLoggerFormats(t *testing.T) {
+	var out bytes.Buffer
+	l := NewLogger(&out, LevelDebug, FormatJSON)
+	l.PrintInfo("Validated Workload Images", map[string]string{"kind": "Deployment", "name": "web"})
+	l.PrintError(fmt.Errorf("invalid object"), nil)
+
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry struct {
+			Level      string            `json:"level"`
+			Time       string            `json:"time"`
+			Message    string            `json:"message"`
+			Properties map[string]string `json:"properties"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected a JSON line, got %q: %v", line, err)
+		}
+		if entry.Level == "" || entry.Time == "" || entry.Message == "" {
+			t.Errorf("expected level, time and message to be set, got %+v", entry)
+		}
+	}
+
+	out.Reset()
+	format, err := ParseFormat("text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l = NewLogger(&out, LevelDebug, format)
+	l.PrintInfo("Validated Workload Images", map[string]string{"name": "web", "kind": "Deployment", "reason": "not allowed"})
+	if want := ` INFO Validated Workload Images kind=Deployment name=web reason="not allowed"` + "\n"; !strings.HasSuffix(out.String(), want) {
+		t.Errorf("expected a line ending with %q, got %q", want, out.String())
+	}
+
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
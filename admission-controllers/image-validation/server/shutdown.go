@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// shuttingDown is set once the server starts draining, the health endpoint then reports 503.
+var shuttingDown atomic.Bool
+
+// inFlight counts the requests currently being served.
+var inFlight atomic.Int64
+
+func trackInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serve runs serveFn until ctx is done, then keeps accepting requests for delay while the failing
+// health endpoint takes the pod out of the endpoints, shuts the server down and waits up to grace
+// for the in-flight requests to finish.
+func serve(ctx context.Context, server *http.Server, serveFn func() error, delay, grace time.Duration) error {
+	shuttingDown.Store(false)
+	errCh := make(chan error, 1)
+	go func() { errCh <- serveFn() }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shuttingDown.Store(true)
+	if delay > 0 {
+		logger.PrintInfo("Shutdown requested, waiting for the endpoints to be updated", map[string]string{
+			"shutdownDelay": delay.String(),
+		})
+		time.Sleep(delay)
+	}
+	draining := inFlight.Load()
+	logger.PrintInfo("Shutting down, draining in-flight requests", map[string]string{
+		"inFlight":    strconv.FormatInt(draining, 10),
+		"gracePeriod": grace.String(),
+	})
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	shutdownErr := server.Shutdown(shutdownCtx)
+	remaining := inFlight.Load()
+	logger.PrintInfo("Server stopped", map[string]string{
+		"drained":   strconv.FormatInt(draining-remaining, 10),
+		"abandoned": strconv.FormatInt(remaining, 10),
+	})
+	if shutdownErr != nil {
+		return shutdownErr
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
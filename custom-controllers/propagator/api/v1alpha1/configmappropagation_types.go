@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"slices"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -35,6 +37,38 @@ type PropagationSource struct {
 	Namespace string `json:"namespace"`
 }
 
+// DefaultSourceNamespace is the namespace of a source that was stored without one,
+// e.g. by a client that dropped the CRD default.
+const DefaultSourceNamespace = "default"
+
+// NamespaceOrDefault returns the namespace of the source, defaulting to DefaultSourceNamespace.
+// Every lookup of the source goes through it so an empty namespace is resolved the same way everywhere.
+func (s PropagationSource) NamespaceOrDefault() string {
+	if s.Namespace == "" {
+		return DefaultSourceNamespace
+	}
+	return s.Namespace
+}
+
+// AdditionalSource is a Configmap merged over the Source
+type AdditionalSource struct {
+	// Name of the Configmap
+	// +kubebuilder:validation:MinLength:=1
+	// +kubebuilder:validation:MaxLength:=253
+	// +kubebuilder:validation:Pattern:=^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the Configmap, defaults to the namespace of the Source
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Optional skips the Configmap while it doesn't exist, otherwise a missing Configmap marks the
+	// propagation not Ready until it is created. OnSourceDelete only applies to the Source
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
 type TargetRef struct {
 	// Namespace where the propagated ConfigMap should be created/updated.
 	// +kubebuilder:validation:MinLength=1
@@ -44,6 +78,28 @@ type TargetRef struct {
 	// Name of the target ConfigMap. If not provided, defaults to source name.
 	// +optional
 	Name string `json:"name,omitempty"`
+
+	// SyncMode overrides the propagation SyncMode for this target, not supported by SecretPropagation.
+	// +kubebuilder:validation:Enum=CreatedOnce;Periodic;OnChange
+	// +optional
+	SyncMode SyncMode `json:"syncMode,omitempty"`
+
+	// SyncInterval overrides the propagation SyncInterval for this target, used when its SyncMode is Periodic.
+	// 0 disables the periodic syncs of the target. Not supported by SecretPropagation.
+	// +optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+}
+
+// TargetSyncTime records when a target with its own SyncMode or SyncInterval was last synced.
+type TargetSyncTime struct {
+	// Namespace of the target ConfigMap.
+	Namespace string `json:"namespace"`
+
+	// Name of the target ConfigMap.
+	Name string `json:"name"`
+
+	// LastSyncedAt is the time the target was last created or updated from the source.
+	LastSyncedAt metav1.Time `json:"lastSyncedAt"`
 }
 
 // SyncMode defines how and when the Configmaps should be refreshed.
@@ -55,10 +111,21 @@ const (
 	SyncModeCreatedOnce SyncMode = "CreatedOnce"
 	// SyncModePeriodic synchronizes the Configmap from the provider at regular intervals.
 	SyncModePeriodic SyncMode = "Periodic"
-	// SyncModeOnChange only synchronizes when the Configmap's metadata or spec changes.
+	// SyncModeOnChange synchronizes when the spec or the source data changes.
 	SyncModeOnChange SyncMode = "OnChange"
 )
 
+// SourceSelectorMode tells how the ConfigMaps matching a SourceSelector make up the source
+// +kubebuilder:validation:Enum=Merge;Single
+type SourceSelectorMode string
+
+const (
+	// SourceSelectorModeMerge merges every matching ConfigMap into one source
+	SourceSelectorModeMerge SourceSelectorMode = "Merge"
+	// SourceSelectorModeSingle requires exactly one matching ConfigMap
+	SourceSelectorModeSingle SourceSelectorMode = "Single"
+)
+
 // Deletion Policy determines the state of target Configmaps when the source Configmap is deleted.
 // +kubebuilder:validation:Enum=Delete;Orphan
 type DeletionPolicy string
@@ -83,7 +150,110 @@ const (
 	PropagationPolicyOverwrite PropagationPolicy = "Overwrite"
 )
 
+// OnSourceDelete determines what happens to the target Configmaps when the source Configmap is deleted.
+// +kubebuilder:validation:Enum=Retain;DeleteTargets;MarkStale
+type OnSourceDelete string
+
+const (
+	// OnSourceDeleteRetain leaves the targets untouched and keeps retrying until the source is back.
+	OnSourceDeleteRetain OnSourceDelete = "Retain"
+	// OnSourceDeleteDeleteTargets deletes every managed target Configmap.
+	OnSourceDeleteDeleteTargets OnSourceDelete = "DeleteTargets"
+	// OnSourceDeleteMarkStale keeps the targets but marks the propagation as not Ready.
+	OnSourceDeleteMarkStale OnSourceDelete = "MarkStale"
+)
+
+// ValueTransformOperation is an encoding applied to a source value before it is propagated.
+// +kubebuilder:validation:Enum=GzipBase64
+type ValueTransformOperation string
+
+const (
+	// ValueTransformGzipBase64 gzip-compresses the value and stores it base64 encoded in the target.
+	ValueTransformGzipBase64 ValueTransformOperation = "GzipBase64"
+)
+
+// ValueTransform encodes the value of a single source key in the target Configmaps.
+type ValueTransform struct {
+	// Key of the source Configmap whose value is transformed.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+
+	// Operation applied to the value.
+	// +kubebuilder:validation:Required
+	Operation ValueTransformOperation `json:"operation"`
+}
+
+// KeyFormat restricts the propagated keys to the ones usable by a consumption style.
+// +kubebuilder:validation:Enum=Any;EnvSafe;FileSafe
+type KeyFormat string
+
+const (
+	// KeyFormatAny propagates every key.
+	KeyFormatAny KeyFormat = "Any"
+	// KeyFormatEnvSafe only propagates keys that are valid environment variable names (e.g. no dots).
+	KeyFormatEnvSafe KeyFormat = "EnvSafe"
+	// KeyFormatFileSafe only propagates keys that are valid file names when the Configmap is mounted.
+	KeyFormatFileSafe KeyFormat = "FileSafe"
+)
+
+// KeyTransform changes the keys the source data is propagated under.
+type KeyTransform struct {
+	// Prefix is prepended to every propagated key, after Renames is applied.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Renames maps source keys to the key they are propagated under.
+	// +optional
+	Renames map[string]string `json:"renames,omitempty"`
+}
+
+// ServerSideApplyConfig configures updating the target Configmaps with Server-Side Apply.
+type ServerSideApplyConfig struct {
+	// ForceConflicts takes ownership of fields managed by other field managers on an apply conflict.
+	// When false the conflicting target is reported as Drifted and left untouched.
+	// +optional
+	ForceConflicts bool `json:"forceConflicts,omitempty"`
+}
+
+// SecretSource selects the keys of a Secret that are propagated into the target Configmaps.
+// Keys that are not listed are never copied, so private keys stored next to public certificates stay in the Secret.
+type SecretSource struct {
+	// DataKeys are the Secret keys copied into the target data, their values must be valid UTF-8
+	// +optional
+	DataKeys []string `json:"dataKeys,omitempty"`
+
+	// BinaryDataKeys are the Secret keys copied into the target binaryData
+	// +optional
+	BinaryDataKeys []string `json:"binaryDataKeys,omitempty"`
+}
+
+// ConsumerReadiness configures how the Deployments consuming the target Configmaps are checked
+// for having picked up the current data.
+type ConsumerReadiness struct {
+	// DeploymentSelector selects the consuming Deployments in every target namespace
+	DeploymentSelector metav1.LabelSelector `json:"deploymentSelector"`
+}
+
+// ConsumerReadinessStatus aggregates the readiness of the consuming Deployments.
+type ConsumerReadinessStatus struct {
+	// ConfigHash is the hash of the propagated data. A consumer is ready once its pod template carries it in the
+	// sync.propagators.io/config-hash annotation and its rollout has completed
+	ConfigHash string `json:"configHash"`
+
+	// Ready is the number of consuming Deployments running the current data
+	Ready int32 `json:"ready"`
+
+	// Total is the number of consuming Deployments selected in the target namespaces
+	Total int32 `json:"total"`
+
+	// NotReady lists the consuming Deployments that have not picked up the current data yet as namespace/name
+	// +optional
+	NotReady []string `json:"notReady,omitempty"`
+}
+
 // ConfigMapPropagationSpec defines the desired state of ConfigMapPropagation
+// +kubebuilder:validation:XValidation:rule="!has(self.includeKeys) || !has(self.excludeKeys) || self.includeKeys.all(k, !(k in self.excludeKeys))",message="a key may not be listed in both includeKeys and excludeKeys"
 type ConfigMapPropagationSpec struct {
 	// PropagationSource Defines the input for Propagation
 	// Input the Configmap's name and namespace
@@ -91,6 +261,29 @@ type ConfigMapPropagationSpec struct {
 	// +kubebuilder:validation:Required
 	Source PropagationSource `json:"source"`
 
+	// SourceSelector picks the source ConfigMaps in the source namespace by label instead of by Source.Name,
+	// how the matches make up the source is set by SourceSelectorMode.
+	// Source.Name is then only used as the default name of the targets
+	// +optional
+	SourceSelector *metav1.LabelSelector `json:"sourceSelector,omitempty"`
+
+	// SourceSelectorMode tells how the ConfigMaps matching SourceSelector make up the source:
+	// - Merge: every match is merged in name order, on a key conflict the alphabetically later ConfigMap wins
+	// - Single: exactly one ConfigMap must match, so the source can be rotated by moving the label
+	// +kubebuilder:default="Merge"
+	// +optional
+	SourceSelectorMode SourceSelectorMode `json:"sourceSelectorMode,omitempty"`
+
+	// AdditionalSources are merged over the Source in order, on a key conflict the later source wins.
+	// Labels and annotations are still copied from the Source only
+	// +optional
+	AdditionalSources []AdditionalSource `json:"additionalSources,omitempty"`
+
+	// SecretSource reads the source from the Secret named Source.Name instead of a Configmap and copies
+	// only the listed keys. Requires the controller to run with --allow-secret-to-configmap
+	// +optional
+	SecretSource *SecretSource `json:"secretSource,omitempty"`
+
 	// NamespaceSelector selects namespaces where the target ConfigMap
 	// should be propagated.
 	//
@@ -101,9 +294,29 @@ type ConfigMapPropagationSpec struct {
 	//       team: backend
 	//
 	// Use Empty Object to match all namespaces example: namespaceSelector: {}
+	// System namespaces are still left out unless allowSystemNamespaces is set. Leaving the selector
+	// unset selects no namespace, only targets and namespaceNamePattern apply then.
 	// +optional
 	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 
+	// NamespaceNamePattern selects namespaces by name, in addition to the ones selected by NamespaceSelector.
+	// It is a glob such as "team-*", or a regular expression matched against the whole name when
+	// prefixed with "re:", e.g. "re:app-prod-.*".
+	// +optional
+	NamespaceNamePattern string `json:"namespaceNamePattern,omitempty"`
+
+	// NamespaceExcludeSelector removes the namespaces it matches from the namespaces selected by
+	// NamespaceSelector or NamespaceNamePattern, e.g. every namespace except the ones labeled env=prod.
+	// Namespaces listed explicitly in Targets are always kept
+	// +optional
+	NamespaceExcludeSelector *metav1.LabelSelector `json:"namespaceExcludeSelector,omitempty"`
+
+	// ExcludeNamespaces lists namespaces that never receive a target, e.g. istio-system or cert-manager.
+	// They are removed after Targets, NamespaceSelector and NamespaceNamePattern are resolved, explicit
+	// targets included, and on top of the system namespaces left out by AllowSystemNamespaces
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+
 	// Explicit list of target namespaces/ConfigMaps.
 	// +optional
 	Targets []TargetRef `json:"targets,omitempty"`
@@ -114,17 +327,98 @@ type ConfigMapPropagationSpec struct {
 	// +kubebuilder:default="Delete"
 	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 
+	// CanaryNamespace is a target namespace that is synced first on every reconcile
+	// The remaining targets are only synced when the canary sync succeeds, it must be one of the resolved targets
+	// +optional
+	CanaryNamespace string `json:"canaryNamespace,omitempty"`
+
+	// BatchSize limits how many targets are created, updated or deleted per reconcile
+	// The remaining targets are processed on the following reconciles, 0 processes all targets at once
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BatchSize int32 `json:"batchSize,omitempty"`
+
+	// WriteSummaryConfigMap maintains a ConfigMap named <name>-propagation-summary in the source namespace
+	// listing every target ConfigMap and its sync state, for tools that cannot read the CRD
+	// +optional
+	WriteSummaryConfigMap bool `json:"writeSummaryConfigMap,omitempty"`
+
+	// TargetEvents also records create, update and drift events against the target Configmaps
+	// so they show up in `kubectl describe configmap` for the namespace owners. Off by default to avoid event spam
+	// +optional
+	TargetEvents bool `json:"targetEvents,omitempty"`
+
+	// ConsumerReadiness reports in status whether the Deployments consuming the targets run the current data
+	// Only evaluated when the controller runs with --track-consumer-readiness
+	// +optional
+	ConsumerReadiness *ConsumerReadiness `json:"consumerReadiness,omitempty"`
+
+	// AnnotateManagedKeys stamps the sorted list of keys managed by the propagation on every target,
+	// so namespace owners can tell the propagated keys from their own
+	// +optional
+	AnnotateManagedKeys bool `json:"annotateManagedKeys,omitempty"`
+
+	// HashSuffixTargetNames names every target <name>-<hash of the propagated data> and creates it immutable.
+	// A data change creates new targets, the previous ones are deleted after HashedTargetGracePeriod
+	// +optional
+	HashSuffixTargetNames bool `json:"hashSuffixTargetNames,omitempty"`
+
+	// HashedTargetGracePeriod is how long superseded hash suffixed targets are kept so running
+	// workloads can roll over to the new name. Defaults to 5m
+	// +optional
+	HashedTargetGracePeriod *metav1.Duration `json:"hashedTargetGracePeriod,omitempty"`
+
+	// RecreateImmutableTargets deletes and recreates existing targets marked immutable when their data
+	// has to change. By default such targets are skipped and reported with the reason Immutable
+	// +optional
+	RecreateImmutableTargets bool `json:"recreateImmutableTargets,omitempty"`
+
+	// PropagateImmutable marks the targets immutable when the source ConfigMap is immutable, existing
+	// targets included. Their data can't be updated in place afterwards, a data change is handled
+	// per recreateImmutableTargets
+	// +optional
+	PropagateImmutable bool `json:"propagateImmutable,omitempty"`
+
+	// EnableTemplating renders every source Data value as a Go text/template per target before it is
+	// propagated, e.g. baseUrl: https://{{ .Namespace }}.svc.example.com. The template sees .Namespace,
+	// .TargetName and .SourceName. A value that fails to render fails only its target
+	// +optional
+	EnableTemplating bool `json:"enableTemplating,omitempty"`
+
+	// OnSourceDelete determines what to do with the target Configmaps when the source Configmap is deleted
+	// - Retain: Keeps the targets as they are
+	// - DeleteTargets: Deletes all the managed target Configmaps
+	// - MarkStale: Keeps the targets and sets the Ready condition to False with reason SourceDeleted
+	// +kubebuilder:default="Retain"
+	// +optional
+	OnSourceDelete OnSourceDelete `json:"onSourceDelete,omitempty"`
+
 	// SyncMode determines how the Confimaps should be refreshed:
 	// - CreatedOnce: Creates the Configmap only if it does not exist and does not update it thereafter
-	// - Periodic: Synchronizes the Configmap from the external source at regular intervals specified by refreshInterval.
-	//   No periodic updates occur if refreshInterval is 0.
-	// - OnChange: Only synchronizes the Secret when the Configmap's metadata or specification changes
+	// - Periodic: Synchronizes the Configmap from the external source at regular intervals specified by syncInterval.
+	//   No periodic updates occur if syncInterval is 0, the targets are then only synced on changes like OnChange.
+	// - OnChange: Synchronizes the Configmap when the specification or the source Configmap's data changes
 	// +kubebuilder:default="OnChange"
 	// +optional
 	SyncMode SyncMode `json:"syncMode,omitempty"`
 
+	// ServerSideApply updates the existing target Configmaps with Server-Side Apply instead of Update
+	// so that fields set by other field managers (e.g. kubectl apply) are not silently reverted
+	// +optional
+	ServerSideApply *ServerSideApplyConfig `json:"serverSideApply,omitempty"`
+
+	// TargetTTL refreshes target Configmaps from the source once they are older than the TTL,
+	// regardless of the SyncMode. Useful for time sensitive data such as rotating seeds
+	// +optional
+	TargetTTL *metav1.Duration `json:"targetTTL,omitempty"`
+
+	// DeleteExpiredOrphans deletes targets orphaned by this propagation once their TargetTTL has passed
+	// Only used when TargetTTL is set and DeletionPolicy is Orphan
+	// +optional
+	DeleteExpiredOrphans bool `json:"deleteExpiredOrphans,omitempty"`
+
 	// SyncInterval determines how often to sync the target Configmap
-	// Only Used when syncmode is periodic
+	// Only Used when syncmode is periodic, 0 disables the periodic syncs
 	// +kubebuilder:default="5m"
 	// +optional
 	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
@@ -141,18 +435,96 @@ type ConfigMapPropagationSpec struct {
 	// +optional
 	PropagationPolicy PropagationPolicy `json:"propagationPolicy,omitempty"`
 
+	// PruneRemovedSourceKeys removes a key from Merge targets once the source no longer carries it.
+	// Only the keys the controller propagated are pruned, target-local keys and PreserveTargetKeys stay.
+	// Sources with more keys than fit in the 4096 character propagated-keys annotation are not pruned
+	// +optional
+	PruneRemovedSourceKeys bool `json:"pruneRemovedSourceKeys,omitempty"`
+
+	// PreserveTargetKeys lists target-local keys that are never pruned from the target Configmaps,
+	// even when PropagationPolicy is Overwrite (e.g. a locally injected token).
+	// A preserved key is still updated if the source Configmap carries it.
+	// +optional
+	PreserveTargetKeys []string `json:"preserveTargetKeys,omitempty"`
+
+	// ProtectedKeys lists locally managed keys, Data or BinaryData, that keep the value present on the
+	// target Configmaps under either PropagationPolicy. Unlike PreserveTargetKeys they are never
+	// overwritten by the source, the source value is only written when the target doesn't carry the key
+	// +optional
+	ProtectedKeys []string `json:"protectedKeys,omitempty"`
+
+	// IncludeKeys limits the propagated keys to the listed source keys, Data and BinaryData alike.
+	// When empty every source key is propagated
+	// +kubebuilder:validation:MaxItems=256
+	// +kubebuilder:validation:items:MaxLength=253
+	// +optional
+	IncludeKeys []string `json:"includeKeys,omitempty"`
+
+	// ExcludeKeys drops the listed source keys from the propagated keys, after IncludeKeys is applied.
+	// A key may not be listed in both IncludeKeys and ExcludeKeys
+	// +kubebuilder:validation:MaxItems=256
+	// +kubebuilder:validation:items:MaxLength=253
+	// +optional
+	ExcludeKeys []string `json:"excludeKeys,omitempty"`
+
+	// KeyTransform renames and prefixes the propagated keys. IncludeKeys, ExcludeKeys and ValueTransforms
+	// refer to the source keys. Two source keys ending up under the same key fail the target, and a
+	// source key dropped by IncludeKeys or ExcludeKeys can't be renamed
+	// +optional
+	KeyTransform *KeyTransform `json:"keyTransform,omitempty"`
+
+	// CopyLabels lists the source labels copied onto the target Configmaps, ["*"] copies all of them.
+	// The controller's own sync.propagators.io/ labels are never copied
+	// +optional
+	CopyLabels []string `json:"copyLabels,omitempty"`
+
+	// CopyAnnotations lists the source annotations copied onto the target Configmaps, ["*"] copies all
+	// of them except kubectl's last-applied-configuration. The controller's own sync.propagators.io/
+	// annotations are never copied
+	// +optional
+	CopyAnnotations []string `json:"copyAnnotations,omitempty"`
+
+	// ValueTransforms encodes the values of the listed source keys before they are propagated,
+	// e.g. GzipBase64 to fit large text values into the Configmap size limit.
+	// Encoded keys are listed in the sync.propagators.io/gzip-base64-keys annotation of the targets.
+	// +optional
+	ValueTransforms []ValueTransform `json:"valueTransforms,omitempty"`
+
+	// KeyFormat determines which keys are propagated based on how the targets are consumed:
+	// - Any: Propagates every key
+	// - EnvSafe: Only keys usable as environment variable names (envFrom)
+	// - FileSafe: Only keys usable as file names (volume mounts)
+	// Keys that don't conform are skipped and reported in the KeysSkipped condition.
+	// +kubebuilder:default="Any"
+	// +optional
+	KeyFormat KeyFormat `json:"keyFormat,omitempty"`
+
 	// AllowSystem Namespaces determines if propagator needs to target System Namespace
 	// +kubebuilder:default=true
 	AllowSystemNamespaces bool `json:"allowSystemNamespaces,omitempty"`
 }
 
+// KeyFilteredOut reports whether IncludeKeys or ExcludeKeys drop the source key from the propagated keys.
+func (s ConfigMapPropagationSpec) KeyFilteredOut(key string) bool {
+	if len(s.IncludeKeys) > 0 && !slices.Contains(s.IncludeKeys, key) {
+		return true
+	}
+	return slices.Contains(s.ExcludeKeys, key)
+}
+
 // targetsSummary tells the aggregated result of the reconciliation.
 // Useful for operators to quickly understand how many targets succeeded or failed
 // without expanding the full TargetStatuses list.
 type TargetsSummary struct {
-	// Total number of target namespaces evaluated for this propagation in the last sync.
+	// Total number of targets evaluated for this propagation in the last sync, every desired and
+	// every previously managed target is counted once. It is the sum of Unchanged, Created, Updated,
+	// Deleted, Orphaned, Failed and Skipped.
 	Total int32 `json:"total,omitempty"`
 
+	// Unchanged is the number of targets left as they were in the last sync because they were already
+	// up to date, not due for a sync yet or waiting for a later batch.
+	Unchanged int32 `json:"unchanged,omitempty"`
+
 	Created int32 `json:"created,omitempty"`
 
 	Updated int32 `json:"updated,omitempty"`
@@ -162,6 +534,14 @@ type TargetsSummary struct {
 	Orphaned int32 `json:"orphaned,omitempty"`
 
 	Failed int32 `json:"failed,omitempty"`
+
+	// Skipped is the number of targets left alone in the last sync because they are owned by another
+	// propagation, immutable, in a missing or terminating namespace, or over the namespace ConfigMap budget.
+	Skipped int32 `json:"skipped,omitempty"`
+
+	// Drifted is the number of targets whose out-of-band edits were reverted in the last sync. It is not
+	// a bucket of its own, the drifted targets are also counted in Updated.
+	Drifted int32 `json:"drifted,omitempty"`
 }
 
 // TargetStatus represents the sync condition of a single target ConfigMap.
@@ -180,7 +560,8 @@ type TargetStatus struct {
 	// Common values:
 	// - "Synced"   : successfully reconciled
 	// - "Failed"   : update or creation error occurred
-	// - "Drifted"  : manual modifications detected
+	// - "Drifted"  : fields owned by other field managers were left untouched
+	// A target whose manual modifications were reverted is Synced with the reason DriftDetected.
 	// - "Skipped"  : skipped due to CreateOnce or missing permissions
 	// +kubebuilder:validation:MinLength=1
 	State string `json:"state"`
@@ -216,6 +597,11 @@ type ConfigMapPropagationStatus struct {
 	// the latest Spec.
 	SyncedGeneration string `json:"syncedGeneration,omitempty"`
 
+	// ObservedGeneration is the metadata.generation of the last successful sync. The status reflects
+	// the current spec once it equals metadata.generation
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// LastSyncedAt is the timestamp of the most recent reconciliation attempt
 	// (successful or failed). Useful for knowing controller liveness.
 	LastSyncedAt metav1.Time `json:"lastSyncedAt,omitempty"`
@@ -223,6 +609,49 @@ type ConfigMapPropagationStatus struct {
 	// Will be used with createonce for one successfule sync
 	LastSuccessfulSync metav1.Time `json:"lastSuccessfuleSync,omitempty"`
 
+	// EffectiveSyncInterval is the interval actually used for Periodic syncs after
+	// clamping SyncInterval to the controller's minimum, 0 when periodic syncs are disabled.
+	// +optional
+	EffectiveSyncInterval *metav1.Duration `json:"effectiveSyncInterval,omitempty"`
+
+	// BatchCursor is the last target (namespace/name) processed when BatchSize is set.
+	// Empty when no batched sync is in progress.
+	// +optional
+	BatchCursor string `json:"batchCursor,omitempty"`
+
+	// TargetSyncTimes tracks the last sync of every target that overrides the SyncMode or SyncInterval,
+	// so each of them is refreshed on its own schedule.
+	// +optional
+	TargetSyncTimes []TargetSyncTime `json:"targetSyncTimes,omitempty"`
+
+	// CurrentTargetName is the name of the current hash suffixed targets when HashSuffixTargetNames is set.
+	// Targets with an explicit name carry the same suffix.
+	// +optional
+	CurrentTargetName string `json:"currentTargetName,omitempty"`
+
+	// Revision increments every time the propagated data changes, independent of the spec generation
+	// and the number of syncs. It is also stamped on every target in the sync.propagators.io/revision annotation
+	// +optional
+	Revision int64 `json:"revision,omitempty"`
+
+	// RevisionHash is the hash of the propagated data at Revision
+	// +optional
+	RevisionHash string `json:"revisionHash,omitempty"`
+
+	// MatchedNamespaceCount is the number of namespaces resolved from the targets and the namespace selector
+	// on the last reconcile.
+	// +optional
+	MatchedNamespaceCount int32 `json:"matchedNamespaceCount"`
+
+	// ManagedCount is the number of desired targets that exist and did not fail on the last reconcile.
+	// A gap with MatchedNamespaceCount points at failing targets.
+	// +optional
+	ManagedCount int32 `json:"managedCount"`
+
+	// ConsumerReadiness aggregates the readiness of the consuming Deployments when ConsumerReadiness is set
+	// +optional
+	ConsumerReadiness *ConsumerReadinessStatus `json:"consumerReadiness,omitempty"`
+
 	// TargetsSummary gives a compressed overview of how many targets succeeded
 	// or failed during reconciliation.
 	TargetsSummary TargetsSummary `json:"targetsSummary,omitempty"`
@@ -242,9 +671,13 @@ type ConfigMapPropagationStatus struct {
 // +kubebuilder:printcolumn:name="SourceName",type="string",JSONPath=".spec.source.name"
 // +kubebuilder:printcolumn:name="SyncMode",type="string",JSONPath=".spec.syncMode"
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Matched",type="integer",JSONPath=".status.matchedNamespaceCount"
+// +kubebuilder:printcolumn:name="Managed",type="integer",JSONPath=".status.managedCount"
 // +kubebuilder:selectablefield:JSONPath=`.spec.source.name`
 // +kubebuilder:selectablefield:JSONPath=`.spec.source.namespace`
-// ConfigMapPropagation is the Schema for the configmappropagations API
+// ConfigMapPropagation is the Schema for the configmappropagations API.
+// It is cluster scoped: the source names its namespace and the targets may be in any namespace,
+// so one propagation fans a ConfigMap out across the cluster.
 type ConfigMapPropagation struct {
 	metav1.TypeMeta `json:",inline"`
 
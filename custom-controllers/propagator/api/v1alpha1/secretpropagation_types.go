@@ -0,0 +1,137 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretPropagationSpec defines the desired state of SecretPropagation
+type SecretPropagationSpec struct {
+	// Source is the Secret copied to the targets
+	// If Namespace is not given , default namespace will be taken as input
+	// +kubebuilder:validation:Required
+	Source PropagationSource `json:"source"`
+
+	// NamespaceSelector selects namespaces where the target Secret should be propagated.
+	// Use Empty Object to match all namespaces example: namespaceSelector: {}
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Explicit list of target namespaces/Secrets. The targets follow the SyncMode and SyncInterval of the
+	// propagation, per-target overrides are rejected.
+	// +kubebuilder:validation:XValidation:rule="self.all(t, !has(t.syncMode) && !has(t.syncInterval))",message="syncMode and syncInterval can't be overridden per target of a SecretPropagation"
+	// +optional
+	Targets []TargetRef `json:"targets,omitempty"`
+
+	// DeletionPolicy tells what to do about the target Secrets when the SecretPropagation is deleted
+	// - Delete: Deletes the target Secrets
+	// - Orphan: Does not delete the target Secrets
+	// +kubebuilder:default="Delete"
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// SyncMode determines how the Secrets should be refreshed:
+	// - CreatedOnce: Creates the target Secrets once and does not update them thereafter
+	// - Periodic: Syncs the target Secrets every SyncInterval
+	// - OnChange: Syncs the target Secrets whenever the source Secret or the spec changes
+	// +kubebuilder:default="OnChange"
+	// +optional
+	SyncMode SyncMode `json:"syncMode,omitempty"`
+
+	// SyncInterval determines how often to sync the target Secrets in Periodic mode, 5m when unset.
+	// 0 disables the periodic syncs
+	// +optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+
+	// PropagationPolicy determines how the source keys are written to the target Secrets:
+	// - Overwrite: Keeps the target and source in sync and deletes the extra keys
+	// - Merge: Adds the source keys without deleting the extra keys
+	// +kubebuilder:default="Merge"
+	// +optional
+	PropagationPolicy PropagationPolicy `json:"propagationPolicy,omitempty"`
+
+	// AllowSystemNamespaces determines if the propagator may target system namespaces
+	// +optional
+	AllowSystemNamespaces bool `json:"allowSystemNamespaces,omitempty"`
+
+	// AdoptExistingTargets lets the propagation take over an existing Secret at a target location that no
+	// propagation manages. When unset such a Secret is left alone and reported as a conflict
+	// +optional
+	AdoptExistingTargets bool `json:"adoptExistingTargets,omitempty"`
+}
+
+// SecretPropagationStatus defines the observed state of SecretPropagation.
+type SecretPropagationStatus struct {
+	// Conditions follow the standard Kubernetes conditions pattern.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// SyncedGeneration is the metadata.generation that the controller has last fully reconciled.
+	SyncedGeneration string `json:"syncedGeneration,omitempty"`
+
+	// LastSyncedAt is the timestamp of the most recent sync.
+	LastSyncedAt metav1.Time `json:"lastSyncedAt,omitempty"`
+
+	// TargetsSummary gives a compressed overview of how many targets succeeded
+	// or failed during reconciliation.
+	TargetsSummary TargetsSummary `json:"targetsSummary,omitempty"`
+
+	// TargetStatuses contains detailed per-target records only for targets that
+	// failed or were skipped.
+	// +optional
+	TargetStatuses []TargetStatus `json:"targetStatuses,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:metadata:labels=propagators.io/component=controller
+// +kubebuilder:resource:scope=Cluster,categories={propagators},shortName=sp
+// +kubebuilder:printcolumn:name="SourceNamespace",type="string",JSONPath=".spec.source.namespace"
+// +kubebuilder:printcolumn:name="SourceName",type="string",JSONPath=".spec.source.name"
+// +kubebuilder:printcolumn:name="SyncMode",type="string",JSONPath=".spec.syncMode"
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// SecretPropagation is the Schema for the secretpropagations API
+type SecretPropagation struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of SecretPropagation
+	// +required
+	Spec SecretPropagationSpec `json:"spec"`
+
+	// status defines the observed state of SecretPropagation
+	// +optional
+	Status SecretPropagationStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// SecretPropagationList contains a list of SecretPropagation
+type SecretPropagationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []SecretPropagation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SecretPropagation{}, &SecretPropagationList{})
+}
@@ -25,6 +25,21 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalSource) DeepCopyInto(out *AdditionalSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalSource.
+func (in *AdditionalSource) DeepCopy() *AdditionalSource {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapPropagation) DeepCopyInto(out *ConfigMapPropagation) {
 	*out = *in
@@ -88,21 +103,108 @@ func (in *ConfigMapPropagationList) DeepCopyObject() runtime.Object {
 func (in *ConfigMapPropagationSpec) DeepCopyInto(out *ConfigMapPropagationSpec) {
 	*out = *in
 	out.Source = in.Source
+	if in.SourceSelector != nil {
+		in, out := &in.SourceSelector, &out.SourceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalSources != nil {
+		in, out := &in.AdditionalSources, &out.AdditionalSources
+		*out = make([]AdditionalSource, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretSource != nil {
+		in, out := &in.SecretSource, &out.SecretSource
+		*out = new(SecretSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.NamespaceSelector != nil {
 		in, out := &in.NamespaceSelector, &out.NamespaceSelector
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NamespaceExcludeSelector != nil {
+		in, out := &in.NamespaceExcludeSelector, &out.NamespaceExcludeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludeNamespaces != nil {
+		in, out := &in.ExcludeNamespaces, &out.ExcludeNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Targets != nil {
 		in, out := &in.Targets, &out.Targets
 		*out = make([]TargetRef, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConsumerReadiness != nil {
+		in, out := &in.ConsumerReadiness, &out.ConsumerReadiness
+		*out = new(ConsumerReadiness)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HashedTargetGracePeriod != nil {
+		in, out := &in.HashedTargetGracePeriod, &out.HashedTargetGracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ServerSideApply != nil {
+		in, out := &in.ServerSideApply, &out.ServerSideApply
+		*out = new(ServerSideApplyConfig)
+		**out = **in
+	}
+	if in.TargetTTL != nil {
+		in, out := &in.TargetTTL, &out.TargetTTL
+		*out = new(v1.Duration)
+		**out = **in
 	}
 	if in.SyncInterval != nil {
 		in, out := &in.SyncInterval, &out.SyncInterval
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.PreserveTargetKeys != nil {
+		in, out := &in.PreserveTargetKeys, &out.PreserveTargetKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedKeys != nil {
+		in, out := &in.ProtectedKeys, &out.ProtectedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IncludeKeys != nil {
+		in, out := &in.IncludeKeys, &out.IncludeKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeKeys != nil {
+		in, out := &in.ExcludeKeys, &out.ExcludeKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.KeyTransform != nil {
+		in, out := &in.KeyTransform, &out.KeyTransform
+		*out = new(KeyTransform)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CopyLabels != nil {
+		in, out := &in.CopyLabels, &out.CopyLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CopyAnnotations != nil {
+		in, out := &in.CopyAnnotations, &out.CopyAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ValueTransforms != nil {
+		in, out := &in.ValueTransforms, &out.ValueTransforms
+		*out = make([]ValueTransform, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapPropagationSpec.
@@ -127,6 +229,23 @@ func (in *ConfigMapPropagationStatus) DeepCopyInto(out *ConfigMapPropagationStat
 	}
 	in.LastSyncedAt.DeepCopyInto(&out.LastSyncedAt)
 	in.LastSuccessfulSync.DeepCopyInto(&out.LastSuccessfulSync)
+	if in.EffectiveSyncInterval != nil {
+		in, out := &in.EffectiveSyncInterval, &out.EffectiveSyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.TargetSyncTimes != nil {
+		in, out := &in.TargetSyncTimes, &out.TargetSyncTimes
+		*out = make([]TargetSyncTime, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConsumerReadiness != nil {
+		in, out := &in.ConsumerReadiness, &out.ConsumerReadiness
+		*out = new(ConsumerReadinessStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	out.TargetsSummary = in.TargetsSummary
 	if in.TargetStatuses != nil {
 		in, out := &in.TargetStatuses, &out.TargetStatuses
@@ -145,6 +264,64 @@ func (in *ConfigMapPropagationStatus) DeepCopy() *ConfigMapPropagationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumerReadiness) DeepCopyInto(out *ConsumerReadiness) {
+	*out = *in
+	in.DeploymentSelector.DeepCopyInto(&out.DeploymentSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumerReadiness.
+func (in *ConsumerReadiness) DeepCopy() *ConsumerReadiness {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumerReadiness)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumerReadinessStatus) DeepCopyInto(out *ConsumerReadinessStatus) {
+	*out = *in
+	if in.NotReady != nil {
+		in, out := &in.NotReady, &out.NotReady
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumerReadinessStatus.
+func (in *ConsumerReadinessStatus) DeepCopy() *ConsumerReadinessStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumerReadinessStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyTransform) DeepCopyInto(out *KeyTransform) {
+	*out = *in
+	if in.Renames != nil {
+		in, out := &in.Renames, &out.Renames
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyTransform.
+func (in *KeyTransform) DeepCopy() *KeyTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PropagationSource) DeepCopyInto(out *PropagationSource) {
 	*out = *in
@@ -160,9 +337,175 @@ func (in *PropagationSource) DeepCopy() *PropagationSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretPropagation) DeepCopyInto(out *SecretPropagation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretPropagation.
+func (in *SecretPropagation) DeepCopy() *SecretPropagation {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretPropagation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SecretPropagation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretPropagationList) DeepCopyInto(out *SecretPropagationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SecretPropagation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretPropagationList.
+func (in *SecretPropagationList) DeepCopy() *SecretPropagationList {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretPropagationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SecretPropagationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretPropagationSpec) DeepCopyInto(out *SecretPropagationSpec) {
+	*out = *in
+	out.Source = in.Source
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]TargetRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretPropagationSpec.
+func (in *SecretPropagationSpec) DeepCopy() *SecretPropagationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretPropagationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretPropagationStatus) DeepCopyInto(out *SecretPropagationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastSyncedAt.DeepCopyInto(&out.LastSyncedAt)
+	out.TargetsSummary = in.TargetsSummary
+	if in.TargetStatuses != nil {
+		in, out := &in.TargetStatuses, &out.TargetStatuses
+		*out = make([]TargetStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretPropagationStatus.
+func (in *SecretPropagationStatus) DeepCopy() *SecretPropagationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretPropagationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretSource) DeepCopyInto(out *SecretSource) {
+	*out = *in
+	if in.DataKeys != nil {
+		in, out := &in.DataKeys, &out.DataKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BinaryDataKeys != nil {
+		in, out := &in.BinaryDataKeys, &out.BinaryDataKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretSource.
+func (in *SecretSource) DeepCopy() *SecretSource {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerSideApplyConfig) DeepCopyInto(out *ServerSideApplyConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerSideApplyConfig.
+func (in *ServerSideApplyConfig) DeepCopy() *ServerSideApplyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerSideApplyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetRef) DeepCopyInto(out *TargetRef) {
 	*out = *in
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetRef.
@@ -190,6 +533,22 @@ func (in *TargetStatus) DeepCopy() *TargetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetSyncTime) DeepCopyInto(out *TargetSyncTime) {
+	*out = *in
+	in.LastSyncedAt.DeepCopyInto(&out.LastSyncedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetSyncTime.
+func (in *TargetSyncTime) DeepCopy() *TargetSyncTime {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetSyncTime)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetsSummary) DeepCopyInto(out *TargetsSummary) {
 	*out = *in
@@ -204,3 +563,18 @@ func (in *TargetsSummary) DeepCopy() *TargetsSummary {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValueTransform) DeepCopyInto(out *ValueTransform) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValueTransform.
+func (in *ValueTransform) DeepCopy() *ValueTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(ValueTransform)
+	in.DeepCopyInto(out)
+	return out
+}
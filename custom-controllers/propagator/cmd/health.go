@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// cacheSyncCheckTimeout bounds how long a readiness probe waits for the informer caches
+const cacheSyncCheckTimeout = time.Second
+
+// addHealthChecks registers the liveness check, which passes while the probe server responds, and the
+// readiness check, which fails until the informer caches of the manager have synced.
+func addHealthChecks(mgr manager.Manager) error {
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		return fmt.Errorf("unable to set up health check: %w", err)
+	}
+	if err := mgr.AddReadyzCheck("readyz", cacheSyncedCheck(mgr.GetCache())); err != nil {
+		return fmt.Errorf("unable to set up ready check: %w", err)
+	}
+	return nil
+}
+
+// cacheSyncedCheck reports the manager not ready until its caches have synced, a reconciler reading
+// from an unsynced cache would see targets as missing.
+func cacheSyncedCheck(c cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), cacheSyncCheckTimeout)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("informer caches have not synced yet")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+// syncingCache is a fake cache whose sync state is flipped by the test. Like an informer cache,
+// WaitForCacheSync blocks until the cache synced or the context is done.
+type syncingCache struct {
+	*informertest.FakeInformers
+	synced atomic.Bool
+}
+
+func (c *syncingCache) WaitForCacheSync(ctx context.Context) bool {
+	for !c.synced.Load() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return true
+}
+
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func probeStatus(t *testing.T, url string) int {
+	t.Helper()
+	var status int
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			status = resp.StatusCode
+			resp.Body.Close()
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("probe %s never answered: %v", url, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestHealthProbesOfStartedManager(t *testing.T) {
+	fakeCache := &syncingCache{FakeInformers: &informertest.FakeInformers{Scheme: scheme}}
+	probeAddr := freeAddress(t)
+	mgr, err := ctrl.NewManager(&rest.Config{Host: "https://127.0.0.1:1"}, ctrl.Options{
+		Scheme:                 scheme,
+		Metrics:                metricsserver.Options{BindAddress: "0"},
+		HealthProbeBindAddress: probeAddr,
+		NewCache: func(*rest.Config, cache.Options) (cache.Cache, error) {
+			return fakeCache, nil
+		},
+		MapperProvider: func(*rest.Config, *http.Client) (meta.RESTMapper, error) {
+			return meta.NewDefaultRESTMapper(nil), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := addHealthChecks(mgr); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- mgr.Start(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("manager stopped with an error: %v", err)
+		}
+	}()
+
+	if status := probeStatus(t, "http://"+probeAddr+"/healthz"); status != http.StatusOK {
+		t.Errorf("healthz = %d, want %d", status, http.StatusOK)
+	}
+	if status := probeStatus(t, "http://"+probeAddr+"/readyz"); status != http.StatusInternalServerError {
+		t.Errorf("readyz before the caches synced = %d, want %d", status, http.StatusInternalServerError)
+	}
+
+	fakeCache.synced.Store(true)
+	if status := probeStatus(t, "http://"+probeAddr+"/readyz"); status != http.StatusOK {
+		t.Errorf("readyz after the caches synced = %d, want %d", status, http.StatusOK)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// defaultLeaderElectionID is the name of the Lease the replicas of the manager compete for
+const defaultLeaderElectionID = "79350203.propagators.io"
+
+// leaderElectionFlags configures leader election of the manager. With leader election enabled only the
+// replica holding the Lease runs the reconcilers, the others wait to take over, so replicas don't race
+// on the target ConfigMaps.
+type leaderElectionFlags struct {
+	enabled   bool
+	id        string
+	namespace string
+}
+
+// bindFlags registers the leader election flags on the flag set.
+func (f *leaderElectionFlags) bindFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.enabled, "leader-elect", false,
+		"Enable leader election for controller manager. "+
+			"Enabling this will ensure there is only one active controller manager.")
+	fs.StringVar(&f.id, "leader-election-id", defaultLeaderElectionID,
+		"The name of the Lease used for leader election. Replicas of the same manager must use the same id.")
+	fs.StringVar(&f.namespace, "leader-election-namespace", "",
+		"The namespace of the leader election Lease. Defaults to the namespace the manager runs in.")
+}
+
+// apply sets the leader election fields of the manager options.
+func (f *leaderElectionFlags) apply(opts *ctrl.Options) {
+	opts.LeaderElection = f.enabled
+	opts.LeaderElectionID = f.id
+	opts.LeaderElectionNamespace = f.namespace
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestLeaderElectionFlagsDefaults(t *testing.T) {
+	var f leaderElectionFlags
+	fs := flag.NewFlagSet("manager", flag.ContinueOnError)
+	f.bindFlags(fs)
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var opts ctrl.Options
+	f.apply(&opts)
+	if opts.LeaderElection {
+		t.Error("leader election is enabled without --leader-elect")
+	}
+	if opts.LeaderElectionID != defaultLeaderElectionID {
+		t.Errorf("LeaderElectionID = %q, want %q", opts.LeaderElectionID, defaultLeaderElectionID)
+	}
+	if opts.LeaderElectionNamespace != "" {
+		t.Errorf("LeaderElectionNamespace = %q, want it empty", opts.LeaderElectionNamespace)
+	}
+}
+
+func TestLeaderElectionFlagsPopulateManagerOptions(t *testing.T) {
+	var f leaderElectionFlags
+	fs := flag.NewFlagSet("manager", flag.ContinueOnError)
+	f.bindFlags(fs)
+	err := fs.Parse([]string{"--leader-elect", "--leader-election-id=propagator.example.com", "--leader-election-namespace=propagator-system"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var opts ctrl.Options
+	f.apply(&opts)
+	if !opts.LeaderElection {
+		t.Error("leader election is not enabled by --leader-elect")
+	}
+	if opts.LeaderElectionID != "propagator.example.com" {
+		t.Errorf("LeaderElectionID = %q, want propagator.example.com", opts.LeaderElectionID)
+	}
+	if opts.LeaderElectionNamespace != "propagator-system" {
+		t.Errorf("LeaderElectionNamespace = %q, want propagator-system", opts.LeaderElectionNamespace)
+	}
+}
@@ -17,19 +17,23 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
-	"os"
+	"fmt"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -37,6 +41,8 @@ import (
 
 	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
 	cmpcontroller "github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/configmappropagation"
+	spcontroller "github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/secretpropagation"
+	webhookv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -52,41 +58,101 @@ func init() {
 	// +kubebuilder:scaffold:scheme
 }
 
+// runController runs the ConfigMapPropagation and SecretPropagation controllers with the flags in args
+// until ctx is done.
 // nolint:gocyclo
-func main() {
-	var metricsAddr string
+func runController(ctx context.Context, args []string, env managerEnv) error {
+	fs := flag.NewFlagSet("serve controller", flag.ContinueOnError)
+	var common commonFlags
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
-	var enableLeaderElection bool
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var defaultSyncMode string
+	var minSyncInterval time.Duration
+	var syncJitter float64
+	var allowedSourceNamespaces string
+	var maxConcurrentReconciles, maxConcurrentPerSource int
+	var sourceFailureThreshold int
+	var sourceBreakerBackoff time.Duration
+	var targetBackoffBase, targetBackoffMax time.Duration
+	var trackConsumerReadiness bool
+	var maxConfigMapsPerNamespace int
+	var allowSecretToConfigMap bool
+	var enableWebhooks bool
+	var orphanSweepInterval time.Duration
+	var listPageSize int64
 	var tlsOpts []func(*tls.Config)
-	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
+	common.bindFlags(fs, "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
-	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
-	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
-		"Enable leader election for controller manager. "+
-			"Enabling this will ensure there is only one active controller manager.")
-	flag.BoolVar(&secureMetrics, "metrics-secure", true,
+	fs.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	fs.BoolVar(&secureMetrics, "metrics-secure", true,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
-	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
-	flag.StringVar(&webhookCertName, "webhook-cert-name", "tls.crt", "The name of the webhook certificate file.")
-	flag.StringVar(&webhookCertKey, "webhook-cert-key", "tls.key", "The name of the webhook key file.")
-	flag.StringVar(&metricsCertPath, "metrics-cert-path", "",
+	fs.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
+	fs.StringVar(&webhookCertName, "webhook-cert-name", "tls.crt", "The name of the webhook certificate file.")
+	fs.StringVar(&webhookCertKey, "webhook-cert-key", "tls.key", "The name of the webhook key file.")
+	fs.StringVar(&metricsCertPath, "metrics-cert-path", "",
 		"The directory that contains the metrics server certificate.")
-	flag.StringVar(&metricsCertName, "metrics-cert-name", "tls.crt", "The name of the metrics server certificate file.")
-	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
-	flag.BoolVar(&enableHTTP2, "enable-http2", false,
+	fs.StringVar(&metricsCertName, "metrics-cert-name", "tls.crt", "The name of the metrics server certificate file.")
+	fs.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
+	fs.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	fs.StringVar(&defaultSyncMode, "default-sync-mode", string(syncv1alpha1.SyncModeOnChange),
+		"The SyncMode used for ConfigMapPropagations that do not set one. One of CreatedOnce, Periodic or OnChange.")
+	fs.DurationVar(&minSyncInterval, "min-sync-interval", cmpcontroller.DefaultMinSyncInterval,
+		"The smallest syncInterval honored in Periodic mode. Smaller intervals are clamped to it.")
+	fs.Float64Var(&syncJitter, "sync-jitter", cmpcontroller.DefaultSyncJitter,
+		"The fraction of the syncInterval Periodic ConfigMapPropagations are spread by, so they don't all sync at once. 0 disables it.")
+	fs.StringVar(&allowedSourceNamespaces, "allowed-source-namespaces", "",
+		"Comma separated list of namespaces source ConfigMaps may be read from. Empty allows every namespace.")
+	fs.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The number of ConfigMapPropagations reconciled in parallel. 1 is safe for any cluster, raise it when sync lag grows with many propagations.")
+	fs.IntVar(&maxConcurrentPerSource, "max-concurrent-per-source", 0,
+		"The number of ConfigMapPropagations sharing a source ConfigMap that may sync at the same time. 0 means no limit.")
+	fs.IntVar(&sourceFailureThreshold, "source-failure-threshold", 5,
+		"Consecutive source ConfigMap fetch errors after which the source is marked unavailable and only probed periodically.")
+	fs.DurationVar(&sourceBreakerBackoff, "source-breaker-backoff", 10*time.Minute,
+		"How long to wait before probing an unavailable source ConfigMap again.")
+	fs.DurationVar(&targetBackoffBase, "target-backoff-base", 5*time.Second,
+		"How long a target ConfigMap whose write failed is left alone before it is retried, doubled on every consecutive failure.")
+	fs.DurationVar(&targetBackoffMax, "target-backoff-max", 5*time.Minute,
+		"The longest a repeatedly failing target ConfigMap is left alone between retries.")
+	fs.BoolVar(&trackConsumerReadiness, "track-consumer-readiness", false,
+		"Watch Deployments to report whether the consumers of the targets run the current data. "+
+			"Only used by ConfigMapPropagations that set consumerReadiness.")
+	fs.IntVar(&maxConfigMapsPerNamespace, "max-configmaps-per-namespace", 0,
+		"Skip creating targets in namespaces that already hold this many ConfigMaps. 0 means no limit.")
+	fs.BoolVar(&allowSecretToConfigMap, "allow-secret-to-configmap", false,
+		"Allow ConfigMapPropagations to copy the allowlisted keys of a Secret into target ConfigMaps.")
+	fs.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the validating webhook for ConfigMapPropagations. Requires the webhook serving certificates.")
+	fs.DurationVar(&orphanSweepInterval, "orphan-sweep-interval", time.Minute,
+		"How often target ConfigMaps orphaned with deleteExpiredOrphans are checked for an expired targetTTL.")
+	fs.Int64Var(&listPageSize, "list-page-size", 0,
+		"Read the target ConfigMaps and selected namespaces from the API server this many at a time instead of "+
+			"from the informer cache. The informer still caches every ConfigMap and Namespace, so this does not lower "+
+			"the steady memory of the manager, it only bounds the copies each list allocates at the cost of extra API "+
+			"server calls per reconcile. 0 reads them from the cache in one list.")
 	opts := zap.Options{
 		Development: true,
 	}
-	opts.BindFlags(flag.CommandLine)
-	flag.Parse()
+	opts.BindFlags(fs)
+	config.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if level, ok := common.zapLevel(); ok {
+		opts.Level = level
+	}
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	syncMode, err := cmpcontroller.ParseSyncMode(defaultSyncMode)
+	if err != nil {
+		return fmt.Errorf("invalid --default-sync-mode: %w", err)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -124,7 +190,7 @@ func main() {
 	// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.4/pkg/metrics/server
 	// - https://book.kubebuilder.io/reference/metrics.html
 	metricsServerOptions := metricsserver.Options{
-		BindAddress:   metricsAddr,
+		BindAddress:   common.metricsAddr,
 		SecureServing: secureMetrics,
 		TLSOpts:       tlsOpts,
 	}
@@ -154,13 +220,16 @@ func main() {
 		metricsServerOptions.KeyName = metricsCertKey
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	mgrOptions := ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "79350203.propagators.io",
+		// Secrets are read directly and only their metadata is watched, caching them would hold every
+		// Secret of the cluster in memory
+		Client: client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -172,33 +241,63 @@ func main() {
 		// if you are doing or is intended to do any operation such as perform cleanups
 		// after the manager stops then its usage might be unsafe.
 		// LeaderElectionReleaseOnCancel: true,
-	})
+	}
+	// Only the elected replica runs the reconcilers, the others take over when it loses the Lease
+	common.leaderElection.apply(&mgrOptions)
+	if env.configure != nil {
+		env.configure(&mgrOptions)
+	}
+	restConfig, err := env.restConfig()
 	if err != nil {
-		setupLog.Error(err, "unable to start manager")
-		os.Exit(1)
+		return fmt.Errorf("unable to load the kubeconfig: %w", err)
+	}
+	mgr, err := ctrl.NewManager(restConfig, mgrOptions)
+	if err != nil {
+		return fmt.Errorf("unable to start manager: %w", err)
 	}
 
 	if err := (&cmpcontroller.ConfigMapPropagationReconciler{
+		Client:                    mgr.GetClient(),
+		Scheme:                    mgr.GetScheme(),
+		DefaultSyncMode:           syncMode,
+		MinSyncInterval:           minSyncInterval,
+		SyncJitter:                syncJitter,
+		AllowedSourceNamespaces:   cmpcontroller.ParseNamespaceList(allowedSourceNamespaces),
+		MaxConcurrentReconciles:   maxConcurrentReconciles,
+		MaxConcurrentPerSource:    maxConcurrentPerSource,
+		SourceFailureThreshold:    sourceFailureThreshold,
+		SourceBreakerBackoff:      sourceBreakerBackoff,
+		TargetBackoffBase:         targetBackoffBase,
+		TargetBackoffMax:          targetBackoffMax,
+		TrackConsumerReadiness:    trackConsumerReadiness,
+		MaxConfigMapsPerNamespace: maxConfigMapsPerNamespace,
+		AllowSecretToConfigMap:    allowSecretToConfigMap,
+		OrphanSweepInterval:       orphanSweepInterval,
+		ListPageSize:              listPageSize,
+		BulkListReader:            mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller ConfigMapPropagation: %w", err)
+	}
+	if err := (&spcontroller.SecretPropagationReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "ConfigMapPropagation")
-		os.Exit(1)
+		return fmt.Errorf("unable to create controller SecretPropagation: %w", err)
+	}
+	if enableWebhooks {
+		if err := webhookv1alpha1.SetupConfigMapPropagationWebhookWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to create webhook ConfigMapPropagation: %w", err)
+		}
 	}
 	// +kubebuilder:scaffold:builder
 
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up health check")
-		os.Exit(1)
-	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
-		os.Exit(1)
+	if err := addHealthChecks(mgr); err != nil {
+		return err
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
-		setupLog.Error(err, "problem running manager")
-		os.Exit(1)
+	if err := mgr.Start(ctx); err != nil {
+		return fmt.Errorf("problem running manager: %w", err)
 	}
+	return nil
 }
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"go.uber.org/zap/zapcore"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/harsha3330/kubernetes/admission-controller/image-validation/server"
+)
+
+const usage = `Usage:
+  manager [flags]                   Run the propagation controllers
+  manager serve controller [flags]  Run the propagation controllers
+  manager serve webhook [flags]     Run the image-validation admission webhook
+
+Run a command with -h for its flags.
+`
+
+func main() {
+	ctx := ctrl.SetupSignalHandler()
+	if err := serve(ctx, os.Args[1:], defaultManagerEnv); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		// The webhook does not set up the controller-runtime logger, so report errors on stderr for both
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// serve runs the subcommand named by args. Without the serve command the controller runs, so existing
+// deployments keep working.
+func serve(ctx context.Context, args []string, env managerEnv) error {
+	if len(args) == 0 || args[0] != "serve" {
+		return runController(ctx, args, env)
+	}
+	if len(args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return errors.New("serve needs a subcommand: controller or webhook")
+	}
+	switch args[1] {
+	case "controller":
+		return runController(ctx, args[2:], env)
+	case "webhook":
+		return runWebhook(ctx, args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown serve subcommand %q, want controller or webhook", args[1])
+	}
+}
+
+// managerEnv is what runController needs besides its flags, the smoke test replaces it to run the
+// manager without an API server.
+type managerEnv struct {
+	restConfig func() (*rest.Config, error)
+	// configure, when set, adjusts the manager options after the flags were applied
+	configure func(*ctrl.Options)
+}
+
+var defaultManagerEnv = managerEnv{restConfig: ctrl.GetConfig}
+
+// commonFlags are the logging, metrics and leader election flags every serve subcommand accepts.
+type commonFlags struct {
+	metricsAddr    string
+	logLevel       string
+	leaderElection leaderElectionFlags
+}
+
+// bindFlags registers the common flags on the flag set, metricsUsage describes --metrics-bind-address
+// for the subcommand.
+func (f *commonFlags) bindFlags(fs *flag.FlagSet, metricsUsage string) {
+	fs.StringVar(&f.metricsAddr, "metrics-bind-address", "0", metricsUsage)
+	fs.Func("log-level", "Lowest level logged: debug, info or error. Unset keeps the default of the subcommand", func(value string) error {
+		switch value {
+		case "debug", "info", "error":
+			f.logLevel = value
+			return nil
+		}
+		return fmt.Errorf("unknown log level %q, want debug, info or error", value)
+	})
+	f.leaderElection.bindFlags(fs)
+}
+
+// zapLevel returns the zap level of --log-level, false when it is unset.
+func (f *commonFlags) zapLevel() (zapcore.Level, bool) {
+	switch f.logLevel {
+	case "debug":
+		return zapcore.DebugLevel, true
+	case "info":
+		return zapcore.InfoLevel, true
+	case "error":
+		return zapcore.ErrorLevel, true
+	}
+	return 0, false
+}
+
+// runWebhook runs the image-validation admission webhook with the flags in args until ctx is done.
+func runWebhook(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("serve webhook", flag.ContinueOnError)
+	var common commonFlags
+	common.bindFlags(fs, "The address of a plain HTTP listener serving the review counters on /debug/vars, "+
+		"e.g. 127.0.0.1:6060. Leave as 0 to disable it. Overrides --debug-vars-address.")
+	var opts server.Options
+	opts.BindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if common.logLevel != "" {
+		level, err := server.ParseLevel(common.logLevel)
+		if err != nil {
+			return err
+		}
+		opts.LogLevel = level
+	}
+	if common.metricsAddr != "0" {
+		opts.DebugVarsAddress = common.metricsAddr
+	}
+	if common.leaderElection.enabled {
+		// Admission reviews are answered by every replica behind the Service, there is nothing to elect
+		log.Print("Ignoring --leader-elect, every webhook replica serves")
+	}
+	return server.Run(ctx, opts)
+}
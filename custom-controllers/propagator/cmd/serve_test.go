@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+)
+
+// fakeManagerEnv runs the manager against a synced fake cache and a RESTMapper knowing every type of
+// the scheme, so no API server is needed.
+func fakeManagerEnv() managerEnv {
+	fakeCache := &syncingCache{FakeInformers: &informertest.FakeInformers{Scheme: scheme}}
+	fakeCache.synced.Store(true)
+	return managerEnv{
+		restConfig: func() (*rest.Config, error) {
+			return &rest.Config{Host: "https://127.0.0.1:1"}, nil
+		},
+		configure: func(opts *ctrl.Options) {
+			// Every test starts a new manager registering the same controllers
+			opts.Controller.SkipNameValidation = ptr.To(true)
+			opts.NewCache = func(*rest.Config, cache.Options) (cache.Cache, error) {
+				return fakeCache, nil
+			}
+			opts.MapperProvider = func(*rest.Config, *http.Client) (meta.RESTMapper, error) {
+				mapper := meta.NewDefaultRESTMapper(nil)
+				for gvk := range scheme.AllKnownTypes() {
+					scope := meta.RESTScopeNamespace
+					if gvk.Kind == "Namespace" {
+						scope = meta.RESTScopeRoot
+					}
+					mapper.Add(gvk, scope)
+				}
+				return mapper, nil
+			}
+		},
+	}
+}
+
+// startServe runs serve with args until the test ends.
+func startServe(t *testing.T, env managerEnv, args ...string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, args, env) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("serve %v stopped with an error: %v", args, err)
+		}
+	})
+}
+
+func TestServeControllerServesHealthProbes(t *testing.T) {
+	probeAddr := freeAddress(t)
+	startServe(t, fakeManagerEnv(), "serve", "controller", "--health-probe-bind-address="+probeAddr, "--log-level=error")
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		if status := probeStatus(t, "http://"+probeAddr+path); status != http.StatusOK {
+			t.Errorf("%s = %d, want %d", path, status, http.StatusOK)
+		}
+	}
+}
+
+func TestServeWebhookServesPingAndDebugVars(t *testing.T) {
+	_, port, err := net.SplitHostPort(freeAddress(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	metricsAddr := freeAddress(t)
+	startServe(t, defaultManagerEnv, "serve", "webhook", "--port="+port, "--shutdown-delay=0",
+		"--metrics-bind-address="+metricsAddr, "--log-level=error")
+
+	if status := probeStatus(t, "http://127.0.0.1:"+port+"/ping"); status != http.StatusOK {
+		t.Errorf("/ping = %d, want %d", status, http.StatusOK)
+	}
+	if status := probeStatus(t, "http://"+metricsAddr+"/debug/vars"); status != http.StatusOK {
+		t.Errorf("/debug/vars = %d, want %d", status, http.StatusOK)
+	}
+}
+
+func TestServeRejectsUnknownSubcommand(t *testing.T) {
+	if err := serve(context.Background(), []string{"serve", "scheduler"}, defaultManagerEnv); err == nil {
+		t.Error("serve scheduler succeeded, want an error")
+	}
+}
@@ -2,26 +2,148 @@ package controller
 
 import (
 	"context"
+	"fmt"
 
 	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// propagationTargets holds the desired targets of a propagation and the ConfigMaps carrying its owner
+// label. It is read once per reconcile and passed down to the checks deciding whether to sync and to
+// the sync itself, so the namespaces and targets are not listed again by each of them.
+type propagationTargets struct {
+	desired []*PropagatorTarget
+	// sourceExcluded reports whether the source ConfigMap itself was resolved as a target
+	sourceExcluded bool
+	// current are the targets managed by the propagation, see getCurrentTargets
+	current []*PropagatorTarget
+	// labeled are the ConfigMaps with the owner label, only the ones in current are trusted, see trustedTarget
+	labeled []corev1.ConfigMap
+}
+
+// loadTargets resolves the desired targets and lists the managed ones.
+func (r *ConfigMapPropagationReconciler) loadTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) (*propagationTargets, error) {
+	desired, sourceExcluded, err := r.resolveTargets(ctx, configmapPropagator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute desired targets: %w", err)
+	}
+	labeled, err := r.listLabeledTargets(ctx, configmapPropagator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managed ConfigMaps: %w", err)
+	}
+	return &propagationTargets{
+		desired:        desired,
+		sourceExcluded: sourceExcluded,
+		current:        ownedTargets(ctx, configmapPropagator, labeled, desired),
+		labeled:        labeled,
+	}, nil
+}
+
+// getCurrentTargets returns the targets managed by the propagation. The owner label only narrows the list,
+// a ConfigMap is trusted once its owner UID annotation matches the propagation too, so a forged owner
+// label can't get a ConfigMap pruned or overwritten. Without the desired targets at hand a ConfigMap
+// whose owner UID annotation was stripped isn't trusted either.
 func (r *ConfigMapPropagationReconciler) getCurrentTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) ([]*PropagatorTarget, error) {
+	labeled, err := r.listLabeledTargets(ctx, configmapPropagator)
+	if err != nil {
+		return nil, err
+	}
+	return ownedTargets(ctx, configmapPropagator, labeled, nil), nil
+}
+
+// listLabeledTargets lists the ConfigMaps carrying the owner label of the propagation.
+func (r *ConfigMapPropagationReconciler) listLabeledTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) ([]corev1.ConfigMap, error) {
 	var configmapList corev1.ConfigMapList
-	ownerLabelValue := configmapPropagator.Name
-	if err := r.Client.List(ctx, &configmapList, client.MatchingLabels{
-		OwnerLabelKey: ownerLabelValue,
+	labeled := make([]corev1.ConfigMap, 0)
+	if err := r.listInPages(ctx, &configmapList, func() {
+		labeled = labeled[:0]
+	}, func() error {
+		labeled = append(labeled, configmapList.Items...)
+		return nil
+	}, client.MatchingLabels{
+		OwnerLabelKey: configmapPropagator.Name,
 	}); err != nil {
 		return nil, err
 	}
-	targets := make([]*PropagatorTarget, 0)
-	for _, configmap := range configmapList.Items {
+	return labeled, nil
+}
+
+// ownedTargets returns the labeled ConfigMaps trusted as targets of the propagation, see trustedTarget.
+func ownedTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, labeled []corev1.ConfigMap, desired []*PropagatorTarget) []*PropagatorTarget {
+	desiredKeys := make(map[string]struct{}, len(desired))
+	for _, t := range desired {
+		desiredKeys[t.Namespace+"/"+t.ConfigmapName] = struct{}{}
+	}
+	targets := make([]*PropagatorTarget, 0, len(labeled))
+	for i := range labeled {
+		configmap := &labeled[i]
+		if !trustedTarget(configmap, configmapPropagator, desiredKeys) {
+			logf.FromContext(ctx).V(1).Info("ignoring configmap with the owner label but not the owner UID",
+				"namespace", configmap.Namespace, "name", configmap.Name)
+			continue
+		}
 		targets = append(targets, &PropagatorTarget{
 			ConfigmapName: configmap.Name,
 			Namespace:     configmap.Namespace,
+			HashOf:        configmap.Annotations[HashOfAnnotation],
 		})
 	}
-	return targets, nil
+	return targets
+}
+
+// trustedTarget reports whether a ConfigMap carrying the owner label of the propagation is one of its
+// targets. Its owner UID annotation has to match the propagation. A ConfigMap whose annotation was
+// stripped is only trusted at a desired location, where it would be claimed anyway, and gets the UID
+// restored by the next update. A ConfigMap with the UID of another propagation is never trusted.
+func trustedTarget(configmap *corev1.ConfigMap, configmapPropagator *syncv1alpha1.ConfigMapPropagation, desiredKeys map[string]struct{}) bool {
+	if ownership.IsOwnedBy(configmap, configmapPropagator) {
+		return true
+	}
+	if _, ok := configmap.Annotations[OwnerUIDAnnotation]; ok {
+		return false
+	}
+	_, ok := desiredKeys[configmap.Namespace+"/"+configmap.Name]
+	return ok
+}
+
+// adoptStrippedTargets re-stamps the owner label on managed ConfigMaps that still carry this
+// propagation's owner UID annotation but had the owner label removed out-of-band.
+// Without it getCurrentTargets would no longer see them and the target would silently go unmanaged.
+// Only the managed ConfigMaps without an owner label are listed, which are few.
+func (r *ConfigMapPropagationReconciler) adoptStrippedTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) error {
+	noOwner, err := labels.NewRequirement(OwnerLabelKey, selection.DoesNotExist, nil)
+	if err != nil {
+		return err
+	}
+	selector := labels.SelectorFromSet(labels.Set{ManagedByLabelKey: ManagedByLabelValue}).Add(*noOwner)
+
+	var configmapList corev1.ConfigMapList
+	stripped := make([]corev1.ConfigMap, 0)
+	if err := r.listInPages(ctx, &configmapList, func() {
+		stripped = stripped[:0]
+	}, func() error {
+		for _, configmap := range configmapList.Items {
+			if configmap.Annotations[OwnerUIDAnnotation] == string(configmapPropagator.UID) {
+				stripped = append(stripped, configmap)
+			}
+		}
+		return nil
+	}, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return err
+	}
+	for i := range stripped {
+		configmap := &stripped[i]
+		configmap.Labels[OwnerLabelKey] = configmapPropagator.Name
+		if err := r.Update(ctx, configmap); err != nil {
+			return fmt.Errorf("failed to re-adopt configmap %s/%s: %w", configmap.Namespace, configmap.Name, err)
+		}
+		r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "OwnershipStripped",
+			"owner label was removed from %s/%s, re-adopted it", configmap.Namespace, configmap.Name)
+	}
+	return nil
 }
@@ -0,0 +1,16 @@
+package controller
+
+import (
+	"fmt"
+)
+
+// AdditionalSourceNotFoundError is returned when an AdditionalSource that is not Optional doesn't exist.
+// Unlike a missing Source it never triggers the OnSourceDelete policy.
+type AdditionalSourceNotFoundError struct {
+	Namespace string
+	Name      string
+}
+
+func (e *AdditionalSourceNotFoundError) Error() string {
+	return fmt.Sprintf("additional source ConfigMap %s/%s not found", e.Namespace, e.Name)
+}
@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AmbiguousSourceError is returned when a Single mode SourceSelector matches more than one ConfigMap.
+type AmbiguousSourceError struct {
+	Namespace string
+	Selector  string
+	// Names of the matching ConfigMaps, sorted
+	Names []string
+}
+
+func (e *AmbiguousSourceError) Error() string {
+	return fmt.Sprintf("source selector %q matches %d configmaps in namespace %s, exactly one is required: %s",
+		e.Selector, len(e.Names), e.Namespace, strings.Join(e.Names, ","))
+}
@@ -2,14 +2,18 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"reflect"
+	"strings"
 
 	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 )
 
 func (r *ConfigMapPropagationReconciler) ensureConfigMap(ctx context.Context, cmp *syncv1alpha1.ConfigMapPropagation, t *PropagatorTarget) error {
@@ -17,27 +21,11 @@ func (r *ConfigMapPropagationReconciler) ensureConfigMap(ctx context.Context, cm
 	namespacedName := types.NamespacedName{Namespace: t.Namespace, Name: t.ConfigmapName}
 	err := r.Get(ctx, namespacedName, cm)
 	if err == nil {
-		patched := false
-		if cm.Labels == nil {
-			cm.Labels = map[string]string{}
+		// Taking over a target of another propagation would make both revert each other on every sync
+		if owner, ok := ownership.OtherOwner(cm, cmp); ok {
+			return ownedByAnotherError(t, owner)
 		}
-		ownerLabelVal := cmp.Name
-		if cm.Labels[OwnerLabelKey] != ownerLabelVal {
-			cm.Labels[OwnerLabelKey] = ownerLabelVal
-			patched = true
-		}
-		if cm.Labels[ManagedByLabelKey] != ManagedByLabelValue {
-			cm.Labels[ManagedByLabelKey] = ManagedByLabelValue
-			patched = true
-		}
-		if cm.Annotations == nil {
-			cm.Annotations = map[string]string{}
-		}
-		if cm.Annotations[OwnerUIDAnnotation] != string(cmp.UID) {
-			cm.Annotations[OwnerUIDAnnotation] = string(cmp.UID)
-			patched = true
-		}
-		if patched {
+		if ownership.Claim(cm, cmp, ManagedByLabelValue) {
 			if err := r.Update(ctx, cm); err != nil {
 				return fmt.Errorf("failed to patch labels/annotations on existing configmap: %w", err)
 			}
@@ -48,14 +36,21 @@ func (r *ConfigMapPropagationReconciler) ensureConfigMap(ctx context.Context, cm
 		return err
 	}
 
-	srcName := cmp.Spec.Source.Name
-	srcNS := cmp.Spec.Source.Namespace
-	if srcNS == "" {
-		srcNS = "default"
+	src, err := r.targetSource(ctx, cmp, t)
+	if err != nil {
+		return fmt.Errorf("failed to get source ConfigMap %s/%s: %w", cmp.Spec.Source.NamespaceOrDefault(), cmp.Spec.Source.Name, err)
+	}
+	if src, err = renderTemplates(cmp, t, src); err != nil {
+		return err
+	}
+
+	srcData, encodedKeys, err := prepareSourceData(cmp, src)
+	if err != nil {
+		return err
 	}
-	src := &corev1.ConfigMap{}
-	if err := r.Get(ctx, types.NamespacedName{Namespace: srcNS, Name: srcName}, src); err != nil {
-		return fmt.Errorf("failed to get source ConfigMap %s/%s: %w", srcNS, srcName, err)
+	srcBinaryData, err := prepareSourceBinaryData(cmp, src)
+	if err != nil {
+		return err
 	}
 
 	newCM := &corev1.ConfigMap{
@@ -70,13 +65,35 @@ func (r *ConfigMapPropagationReconciler) ensureConfigMap(ctx context.Context, cm
 				OwnerUIDAnnotation: string(cmp.UID),
 			},
 		},
-		Data:       src.Data,
-		BinaryData: src.BinaryData,
+		Data:       srcData,
+		BinaryData: srcBinaryData,
+	}
+	copiedLabels, copiedAnnotations := copiedMetadata(cmp, src)
+	setCopiedMetadata(cmp, newCM, copiedLabels, copiedAnnotations)
+	setEncodedKeysAnnotation(newCM.Annotations, encodedKeys)
+	setAppliedHashAnnotation(newCM.Annotations, srcData, srcBinaryData)
+	setManagedKeysAnnotation(cmp, newCM.Annotations, srcData)
+	setPropagatedKeysAnnotation(cmp, newCM.Annotations, srcData, srcBinaryData)
+	setSourceHashAnnotation(newCM.Annotations, t.SourceHash)
+	revision, _, err := propagatedRevision(cmp, src)
+	if err != nil {
+		return err
+	}
+	setRevisionAnnotation(newCM.Annotations, revision)
+	r.stampExpiry(cmp, newCM)
+	if t.HashOf != "" {
+		// The name is derived from the data, so the content never changes under that name
+		newCM.Annotations[HashOfAnnotation] = t.HashOf
+		newCM.Immutable = ptr.To(true)
+	}
+	if immutableFromSource(cmp, src) {
+		newCM.Immutable = ptr.To(true)
 	}
 
 	if err := r.Create(ctx, newCM); err != nil {
 		return fmt.Errorf("failed to create propagated configmap %s/%s: %w", t.Namespace, t.ConfigmapName, err)
 	}
+	r.targetEvent(cmp, newCM, corev1.EventTypeNormal, "Propagated", "created from %s/%s by ConfigMapPropagation %s", src.Namespace, src.Name, cmp.Name)
 	return nil
 }
 
@@ -88,38 +105,195 @@ func (r *ConfigMapPropagationReconciler) updateIfNeeded(ctx context.Context, cmp
 		}
 		return err
 	}
-
-	srcNS := cmp.Spec.Source.Namespace
-	if srcNS == "" {
-		srcNS = "default"
+	if owner, ok := ownership.OtherOwner(target, cmp); ok {
+		return ownedByAnotherError(t, owner)
 	}
-	src := &corev1.ConfigMap{}
-	if err := r.Get(ctx, types.NamespacedName{Namespace: srcNS, Name: cmp.Spec.Source.Name}, src); err != nil {
+	// Ownership metadata removed or changed out-of-band is restored along with the data
+	claimed := ownership.Claim(target, cmp, ManagedByLabelValue)
+	// Copied labels and annotations can only be checked against the source, so they are never short-circuited
+	copiesMetadata := len(cmp.Spec.CopyLabels) > 0 || len(cmp.Spec.CopyAnnotations) > 0
+	if !claimed && !copiesMetadata && r.targetUpToDate(cmp, target, t.SourceHash) {
+		t.Unchanged = true
+		return nil
+	}
+
+	src, err := r.targetSource(ctx, cmp, t)
+	if err != nil {
 		return fmt.Errorf("failed to get source configmap for update: %w", err)
 	}
+	// Rendered values differ per target, drift is measured against the rendered data
+	if src, err = renderTemplates(cmp, t, src); err != nil {
+		return err
+	}
+
+	srcData, encodedKeys, err := prepareSourceData(cmp, src)
+	if err != nil {
+		return err
+	}
+
+	desiredData := buildDesiredData(cmp, srcData, target)
+	srcBinaryData, err := prepareSourceBinaryData(cmp, src)
+	if err != nil {
+		return err
+	}
+	desiredBinaryData := buildDesiredBinaryData(cmp, srcBinaryData, target)
+	if target.Annotations == nil {
+		target.Annotations = map[string]string{}
+	}
+	metadataChanged := claimed
+	if setEncodedKeysAnnotation(target.Annotations, encodedKeys) {
+		metadataChanged = true
+	}
+	if setManagedKeysAnnotation(cmp, target.Annotations, srcData) {
+		metadataChanged = true
+	}
+	if setPropagatedKeysAnnotation(cmp, target.Annotations, srcData, srcBinaryData) {
+		metadataChanged = true
+	}
+	if setSourceHashAnnotation(target.Annotations, t.SourceHash) {
+		metadataChanged = true
+	}
+	revision, _, err := propagatedRevision(cmp, src)
+	if err != nil {
+		return err
+	}
+	if setRevisionAnnotation(target.Annotations, revision) {
+		metadataChanged = true
+	}
+	if r.stampExpiry(cmp, target) {
+		metadataChanged = true
+	}
+	copiedLabels, copiedAnnotations := copiedMetadata(cmp, src)
+	if setCopiedMetadata(cmp, target, copiedLabels, copiedAnnotations) {
+		metadataChanged = true
+	}
+	dataChanged := !equality.Semantic.DeepEqual(target.Data, desiredData) || !equality.Semantic.DeepEqual(target.BinaryData, desiredBinaryData)
+	// Data that differs from the desired data while not matching the last write was edited out-of-band,
+	// otherwise the difference comes from the source
+	drifted := dataChanged && editedOutOfBand(target)
+	if drifted {
+		t.DriftedKeys = driftedKeys(target, desiredData, desiredBinaryData)
+		t.DriftCount = bumpDriftCount(target.Annotations, r.now())
+		metadataChanged = true
+	}
+	if setAppliedHashAnnotation(target.Annotations, desiredData, desiredBinaryData) {
+		metadataChanged = true
+	}
+	// Only the data of an immutable ConfigMap is frozen, its metadata can still be updated
+	if dataChanged && ptr.Deref(target.Immutable, false) {
+		if cmp.Spec.RecreateImmutableTargets {
+			return r.recreateImmutableTarget(ctx, cmp, t, target)
+		}
+		return &ImmutableTargetError{Namespace: t.Namespace, Name: t.ConfigmapName}
+	}
+	// A source that became immutable freezes the targets too, along with the data written now
+	if immutableFromSource(cmp, src) && !ptr.Deref(target.Immutable, false) {
+		target.Immutable = ptr.To(true)
+		metadataChanged = true
+	}
+	if !dataChanged && !metadataChanged {
+		t.Unchanged = true
+		return nil
+	}
+
+	if cmp.Spec.ServerSideApply != nil {
+		if err := r.applyTarget(ctx, cmp, target, desiredData, desiredBinaryData, copiedLabels, copiedAnnotations); err != nil {
+			var conflictErr *FieldManagerConflictError
+			if errors.As(err, &conflictErr) {
+				r.targetEvent(cmp, target, corev1.EventTypeWarning, "Drifted", "fields owned by other managers were not updated by ConfigMapPropagation %s: %s",
+					cmp.Name, strings.Join(conflictErr.Conflicts, "; "))
+			}
+			return err
+		}
+	} else {
+		target.Data = desiredData
+		target.BinaryData = desiredBinaryData
+		if err := r.Update(ctx, target); err != nil {
+			if isImmutableRejection(err) {
+				return &ImmutableTargetError{Namespace: t.Namespace, Name: t.ConfigmapName}
+			}
+			return fmt.Errorf("failed to update target configmap %s/%s: %w", t.Namespace, t.ConfigmapName, err)
+		}
+	}
+	if drifted {
+		t.Drifted = true
+		r.targetEvent(cmp, target, corev1.EventTypeWarning, "Drifted", "out-of-band changes to %s were reverted by ConfigMapPropagation %s",
+			strings.Join(t.DriftedKeys, ", "), cmp.Name)
+	}
+	r.targetEvent(cmp, target, corev1.EventTypeNormal, "Updated", "updated from %s/%s by ConfigMapPropagation %s", src.Namespace, src.Name, cmp.Name)
+	return nil
+}
 
+// buildDesiredData computes the Data the target ConfigMap should hold for the propagation policy.
+// Overwrite mirrors the source but keeps any PreserveTargetKeys present on the target,
+// Merge layers the source keys over the existing target keys. ProtectedKeys present on the target
+// keep their target value under both policies.
+func buildDesiredData(cmp *syncv1alpha1.ConfigMapPropagation, srcData map[string]string, target *corev1.ConfigMap) map[string]string {
 	desiredData := map[string]string{}
 	switch cmp.Spec.PropagationPolicy {
 	case "Overwrite":
-		desiredData = src.Data
+		for k, v := range srcData {
+			desiredData[k] = v
+		}
+		for _, k := range cmp.Spec.PreserveTargetKeys {
+			if _, fromSource := desiredData[k]; fromSource {
+				continue
+			}
+			if v, ok := target.Data[k]; ok {
+				desiredData[k] = v
+			}
+		}
 	default:
 		for k, v := range target.Data {
+			if _, inSource := srcData[k]; removedSourceKey(cmp, target, k, inSource) {
+				continue
+			}
 			desiredData[k] = v
 		}
-		for k, v := range src.Data {
+		for k, v := range srcData {
 			desiredData[k] = v
 		}
 	}
-
-	if reflect.DeepEqual(target.Data, desiredData) {
-		return nil
+	for _, k := range cmp.Spec.ProtectedKeys {
+		if v, ok := target.Data[k]; ok {
+			desiredData[k] = v
+		}
+		if _, ok := target.BinaryData[k]; ok {
+			delete(desiredData, k)
+		}
 	}
+	return desiredData
+}
 
-	target.Data = desiredData
-	if err := r.Update(ctx, target); err != nil {
-		return fmt.Errorf("failed to update target configmap %s/%s: %w", t.Namespace, t.ConfigmapName, err)
+// buildDesiredBinaryData computes the BinaryData the target ConfigMap should hold for the propagation policy.
+// Overwrite mirrors the source, Merge layers the source keys over the existing target keys.
+// ProtectedKeys present on the target keep their target value, in Data or BinaryData.
+func buildDesiredBinaryData(cmp *syncv1alpha1.ConfigMapPropagation, srcBinaryData map[string][]byte, target *corev1.ConfigMap) map[string][]byte {
+	desired := map[string][]byte{}
+	if cmp.Spec.PropagationPolicy != "Overwrite" {
+		for k, v := range target.BinaryData {
+			if _, inSource := srcBinaryData[k]; removedSourceKey(cmp, target, k, inSource) {
+				continue
+			}
+			desired[k] = v
+		}
 	}
-	return nil
+	for k, v := range srcBinaryData {
+		desired[k] = v
+	}
+	for _, k := range cmp.Spec.ProtectedKeys {
+		if v, ok := target.BinaryData[k]; ok {
+			desired[k] = v
+		}
+		// A key can't be in both Data and BinaryData, the target keeps it where it already is
+		if _, ok := target.Data[k]; ok {
+			delete(desired, k)
+		}
+	}
+	if len(desired) == 0 {
+		return nil
+	}
+	return desired
 }
 
 func (r *ConfigMapPropagationReconciler) deleteConfigMap(ctx context.Context, ns, name string) error {
@@ -142,24 +316,12 @@ func (r *ConfigMapPropagationReconciler) orphanConfigMap(ctx context.Context, cm
 		return err
 	}
 
-	changed := false
-	if cm.Labels != nil {
-		if lbl, ok := cm.Labels[OwnerLabelKey]; ok {
-			expected := cmp.Name
-			if lbl == expected {
-				delete(cm.Labels, OwnerLabelKey)
-				changed = true
-			}
-		}
-	}
-	if cm.Annotations != nil {
-		if ann, ok := cm.Annotations[OwnerUIDAnnotation]; ok {
-			if ann == string(cmp.UID) {
-				delete(cm.Annotations, OwnerUIDAnnotation)
-				changed = true
-			}
-		}
+	// Remember who orphaned it so the target can be removed once its TTL expires
+	if _, expires := cm.Annotations[ExpiresAtAnnotation]; expires && cmp.Spec.DeleteExpiredOrphans && ownership.IsOwnedBy(cm, cmp) {
+		cm.Annotations[OrphanedFromAnnotation] = string(cmp.UID)
+		cm.Labels[OrphanedLabelKey] = "true"
 	}
+	changed := ownership.Release(cm, cmp)
 
 	if changed {
 		if err := r.Update(ctx, cm); err != nil {
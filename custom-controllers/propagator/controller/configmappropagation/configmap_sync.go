@@ -2,31 +2,53 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-func (r *ConfigMapPropagationReconciler) SyncTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) (ctrl.Result, error) {
-	desired, err := r.getDesiredTargets(ctx, configmapPropagator)
+// SyncTargets creates, updates and cleans up the targets of the propagation from source.
+func (r *ConfigMapPropagationReconciler) SyncTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, source *corev1.ConfigMap) (ctrl.Result, error) {
+	targets, err := r.loadTargets(ctx, configmapPropagator)
 	if err != nil {
-		r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "Compute Desired Failed", "failed to compute desired targets: %v", err)
+		r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "LoadTargetsFailed", "%v", err)
 		return ctrl.Result{}, err
 	}
+	return r.syncTargets(ctx, configmapPropagator, source, targets)
+}
 
-	current, err := r.getCurrentTargets(ctx, configmapPropagator)
-	if err != nil {
-		r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "List Children Failed", "failed to list managed ConfigMaps: %v", err)
-		return ctrl.Result{}, err
+// syncTargets syncs the targets read by loadTargets at the start of the reconcile.
+func (r *ConfigMapPropagationReconciler) syncTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, source *corev1.ConfigMap, targets *propagationTargets) (ctrl.Result, error) {
+	start := time.Now()
+	defer observeSyncDuration(configmapPropagator, start)
+
+	desired, current := targets.desired, targets.current
+	// The source is hashed once, so unchanged targets are skipped without reading the source again
+	// Data that can't be prepared leaves the hash empty and every target reports why it failed
+	sourceHash, _ := sourceRevisionHash(configmapPropagator, source)
+	for _, target := range desired {
+		target.SourceHash = sourceHash
+		target.Source = source
 	}
+	if targets.sourceExcluded {
+		r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "SourceInTargetSet",
+			"source ConfigMap %s/%s is also resolved as a target, it was excluded from the targets",
+			source.Namespace, source.Name)
+	}
+
+	// Targets that override the SyncMode are refreshed on their own schedule, the rest when the propagation is due
+	due := r.propagationDue(configmapPropagator, source, targets)
+	synced := make([]*PropagatorTarget, 0)
 
 	desiredMap := make(map[string]*PropagatorTarget)
 	for _, target := range desired {
@@ -63,11 +85,110 @@ func (r *ConfigMapPropagationReconciler) SyncTargets(ctx context.Context, config
 		}
 	}
 
+	if isDryRun(configmapPropagator) {
+		dueUpdates := make([]*PropagatorTarget, 0, len(toUpdate))
+		for _, t := range toUpdate {
+			if r.targetDue(configmapPropagator, t, due) {
+				dueUpdates = append(dueUpdates, t)
+			}
+		}
+		return r.previewSync(ctx, configmapPropagator, toCreate, dueUpdates, toDelete)
+	}
+
+	// Superseded hash suffixed targets stay around for their grace period
+	toDelete, retireWait, err := r.retireHashedTargets(ctx, configmapPropagator, toDelete)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	var targetSummary syncv1alpha1.TargetsSummary = syncv1alpha1.TargetsSummary{}
 	var targetStatuses []syncv1alpha1.TargetStatus = make([]syncv1alpha1.TargetStatus, 0)
 
-	for _, t := range toCreate {
-		if err := r.ensureConfigMap(ctx, configmapPropagator, t); err != nil {
+	// Targets in terminating namespaces go away with the namespace, they are neither written nor counted as managed
+	terminating, err := r.terminatingNamespaces(ctx, toCreate, toUpdate)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	toCreate, skippedCreates := withoutTerminating(toCreate, terminating)
+	toUpdate, skippedUpdates := withoutTerminating(toUpdate, terminating)
+	for _, t := range append(skippedCreates, skippedUpdates...) {
+		delete(desiredMap, t.Namespace+"/"+t.ConfigmapName)
+		delete(currentMap, t.Namespace+"/"+t.ConfigmapName)
+		targetStatuses = append(targetStatuses, terminatingStatus(t))
+		targetSummary.Skipped += 1
+		targetSummary.Total += 1
+	}
+
+	var policyDenied int32
+	// Targets that failed to be written back off on their own, the reconcile is requeued for the earliest retry
+	var backingOff int32
+	var backoffRetry time.Duration
+	skipBackingOff := func(t *PropagatorTarget) bool {
+		wait, failures, ok := r.targetBackoff().wait(targetBackoffKey(configmapPropagator, t), r.now())
+		if !ok {
+			return false
+		}
+		targetSummary.Failed += 1
+		targetSummary.Total += 1
+		backingOff += 1
+		backoffRetry = earliestRetry(backoffRetry, wait)
+		targetStatuses = append(targetStatuses, backingOffStatus(t, failures, wait))
+		return true
+	}
+	backOff := func(t *PropagatorTarget) {
+		backingOff += 1
+		backoffRetry = earliestRetry(backoffRetry, r.targetBackoff().failure(targetBackoffKey(configmapPropagator, t), r.now()))
+	}
+
+	var collisionErr *KeyCollisionError
+	var templateErr *TemplateError
+	var ownedErr *ownership.OwnedByAnotherError
+	// createTarget and updateTarget sync one target and account for it in the summary and statuses
+	createTarget := func(t *PropagatorTarget) {
+		if skipBackingOff(t) {
+			return
+		}
+		exceeded, count, err := r.namespaceBudgetExceeded(ctx, t.Namespace)
+		if exceeded {
+			targetStatuses = append(targetStatuses, syncv1alpha1.TargetStatus{
+				Namespace: t.Namespace,
+				Name:      t.ConfigmapName,
+				State:     "Skipped",
+				Reason:    "NamespaceConfigMapBudgetExceeded",
+				Message:   fmt.Sprintf("namespace already holds %d configmaps, the budget is %d", count, r.MaxConfigMapsPerNamespace),
+			})
+			targetSummary.Skipped += 1
+			targetSummary.Total += 1
+			return
+		}
+		if err == nil {
+			err = r.ensureConfigMap(ctx, configmapPropagator, t)
+		}
+		if goneStatus, gone := namespaceGoneStatus(t, err); gone {
+			targetSummary.Skipped += 1
+			targetStatuses = append(targetStatuses, goneStatus)
+		} else if errors.As(err, &ownedErr) {
+			r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "OwnedByAnother", "%v", err)
+			targetSummary.Skipped += 1
+			targetStatuses = append(targetStatuses, ownedByAnotherStatus(t, ownedErr))
+		} else if denial, denied := admissionDenial(err); denied {
+			r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "TargetPolicyDenied", "%s/%s creation denied: %s", t.Namespace, t.ConfigmapName, denial)
+			targetSummary.Failed += 1
+			policyDenied += 1
+			targetStatuses = append(targetStatuses, syncv1alpha1.TargetStatus{
+				Namespace: t.Namespace,
+				Name:      t.ConfigmapName,
+				State:     "Failed",
+				Reason:    "TargetPolicyDenied",
+				Message:   denial,
+			})
+		} else if errors.As(err, &collisionErr) {
+			targetSummary.Failed += 1
+			targetStatuses = append(targetStatuses, keyCollisionStatus(t, collisionErr))
+		} else if errors.As(err, &templateErr) {
+			targetSummary.Failed += 1
+			targetStatuses = append(targetStatuses, templateStatus(t, templateErr))
+		} else if err != nil {
 			targetSummary.Failed += 1
 			targetStatuses = append(targetStatuses, syncv1alpha1.TargetStatus{
 				Namespace: t.Namespace,
@@ -77,14 +198,66 @@ func (r *ConfigMapPropagationReconciler) SyncTargets(ctx context.Context, config
 				Message:   "Failed to Ensure the configmap",
 			})
 			r.Recorder.Eventf(configmapPropagator, corev1.EventTypeNormal, "CreatedFailed", "%s/%s creation failed : %v", t.Namespace, t.ConfigmapName, err)
+			backOff(t)
 		} else {
 			targetSummary.Created += 1
+			synced = append(synced, t)
+			r.targetBackoff().success(targetBackoffKey(configmapPropagator, t))
 		}
 		targetSummary.Total += 1
 	}
-
-	for _, t := range toUpdate {
-		if err := r.updateIfNeeded(ctx, configmapPropagator, t); err != nil {
+	// Targets edited outside the controller are restored even when they are not due
+	edited := editedTargets(configmapPropagator, targets)
+	updateTarget := func(t *PropagatorTarget) {
+		_, wasEdited := edited[t.Namespace+"/"+t.ConfigmapName]
+		if !r.targetDue(configmapPropagator, t, due) && !(wasEdited && r.driftCorrectable(configmapPropagator, t)) {
+			targetSummary.Unchanged += 1
+			targetSummary.Total += 1
+			return
+		}
+		if skipBackingOff(t) {
+			return
+		}
+		var conflictErr *FieldManagerConflictError
+		var immutableErr *ImmutableTargetError
+		err := r.updateIfNeeded(ctx, configmapPropagator, t)
+		if errors.As(err, &ownedErr) {
+			r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "OwnedByAnother", "%v", err)
+			targetSummary.Skipped += 1
+			targetStatuses = append(targetStatuses, ownedByAnotherStatus(t, ownedErr))
+		} else if denial, denied := admissionDenial(err); denied {
+			r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "TargetPolicyDenied", "%s/%s update denied: %s", t.Namespace, t.ConfigmapName, denial)
+			targetSummary.Failed += 1
+			policyDenied += 1
+			targetStatuses = append(targetStatuses, syncv1alpha1.TargetStatus{
+				Namespace: t.Namespace,
+				Name:      t.ConfigmapName,
+				State:     "Failed",
+				Reason:    "TargetPolicyDenied",
+				Message:   denial,
+			})
+		} else if errors.As(err, &conflictErr) {
+			r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "FieldManagerConflict", "%v", err)
+			targetSummary.Failed += 1
+			targetStatuses = append(targetStatuses, syncv1alpha1.TargetStatus{
+				Namespace: t.Namespace,
+				Name:      t.ConfigmapName,
+				State:     "Drifted",
+				Reason:    "FieldManagerConflict",
+				Message:   strings.Join(conflictErr.Conflicts, "; "),
+			})
+		} else if errors.As(err, &collisionErr) {
+			targetSummary.Failed += 1
+			targetStatuses = append(targetStatuses, keyCollisionStatus(t, collisionErr))
+		} else if errors.As(err, &templateErr) {
+			targetSummary.Failed += 1
+			targetStatuses = append(targetStatuses, templateStatus(t, templateErr))
+		} else if errors.As(err, &immutableErr) {
+			// Retrying can't change an immutable target, the other targets are still synced
+			r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "TargetImmutable", "%v", err)
+			targetSummary.Skipped += 1
+			targetStatuses = append(targetStatuses, immutableStatus(t, immutableErr))
+		} else if err != nil {
 			r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "UpdateFailed", " %s/%s update failed: %v", t.Namespace, t.ConfigmapName, err)
 			targetStatuses = append(targetStatuses, syncv1alpha1.TargetStatus{
 				Namespace: t.Namespace,
@@ -93,12 +266,86 @@ func (r *ConfigMapPropagationReconciler) SyncTargets(ctx context.Context, config
 				Reason:    fmt.Sprintf("%v", err),
 				Message:   "Failed to update the configmap",
 			})
+			targetSummary.Failed += 1
+			backOff(t)
+		} else if t.Unchanged {
+			targetSummary.Unchanged += 1
+			synced = append(synced, t)
+			r.targetBackoff().success(targetBackoffKey(configmapPropagator, t))
 		} else {
 			targetSummary.Updated += 1
+			synced = append(synced, t)
+			r.targetBackoff().success(targetBackoffKey(configmapPropagator, t))
+			if t.Drifted {
+				targetSummary.Drifted += 1
+				r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "DriftDetected", "%s/%s was modified outside the controller, restored keys %s from the source",
+					t.Namespace, t.ConfigmapName, strings.Join(t.DriftedKeys, ", "))
+				if t.DriftCount >= recurringDriftThreshold {
+					r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "RecurringDrift", "%s/%s has drifted %d times within %s, something else keeps editing it",
+						t.Namespace, t.ConfigmapName, t.DriftCount, recurringDriftWindow)
+				}
+				targetStatuses = append(targetStatuses, driftStatus(t))
+			}
 		}
 		targetSummary.Total += 1
 	}
 
+	// The canary target is synced first, through the same path as the others, and a failure leaves the
+	// remaining targets untouched
+	if canaryNs := configmapPropagator.Spec.CanaryNamespace; canaryNs != "" {
+		if _, ok := terminating[canaryNs]; ok {
+			return r.abortCanary(ctx, configmapPropagator, fmt.Errorf("canary namespace %s is terminating", canaryNs), 0)
+		}
+		canary := canaryTarget(desiredMap, canaryNs)
+		if canary == nil {
+			return r.abortCanary(ctx, configmapPropagator, fmt.Errorf("canary namespace %s is not one of the resolved targets", canaryNs), 0)
+		}
+		statusCount := len(targetStatuses)
+		if _, exists := currentMap[canary.Namespace+"/"+canary.ConfigmapName]; exists {
+			updateTarget(canary)
+			toUpdate = removeTarget(toUpdate, canary)
+		} else {
+			createTarget(canary)
+			toCreate = removeTarget(toCreate, canary)
+		}
+		if failure, failed := canaryFailure(targetStatuses[statusCount:]); failed {
+			var retry time.Duration
+			if failure.Reason == "TargetPolicyDenied" {
+				retry = policyDeniedRequeueDelay
+			} else if backingOff > 0 {
+				retry = backoffRetry
+			}
+			return r.abortCanary(ctx, configmapPropagator, fmt.Errorf("canary %s/%s was not synced: %s: %s",
+				failure.Namespace, failure.Name, failure.Reason, failure.Message), retry)
+		}
+	}
+
+	pendingTargets := len(toCreate) + len(toUpdate) + len(toDelete)
+	carried := carriedBatchFailures(configmapPropagator, toCreate, toUpdate, toDelete)
+	toCreate, toUpdate, toDelete, batchCursor := nextBatch(configmapPropagator, toCreate, toUpdate, toDelete)
+	// Targets left for a later batch are still part of the total
+	deferred := int32(pendingTargets - len(toCreate) - len(toUpdate) - len(toDelete))
+	targetSummary.Unchanged += deferred
+	targetSummary.Total += deferred
+	// Targets that failed in an earlier batch of this pass keep failing it until the pass ends
+	carriedFailures := int32(len(carried))
+	targetStatuses = append(targetStatuses, carried...)
+	targetSummary.Unchanged -= carriedFailures
+	targetSummary.Failed += carriedFailures
+
+	for _, t := range toCreate {
+		createTarget(t)
+	}
+	for _, t := range toUpdate {
+		updateTarget(t)
+	}
+
+	for stale, kept := range staleDuplicates(desired, toDelete) {
+		r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "DuplicateTarget",
+			"%s/%s duplicates the target %s/%s, cleaning it up per deletionPolicy %s",
+			stale.Namespace, stale.ConfigmapName, kept.Namespace, kept.ConfigmapName, configmapPropagator.Spec.DeletionPolicy)
+	}
+
 	for _, t := range toDelete {
 		switch configmapPropagator.Spec.DeletionPolicy {
 		case "Delete":
@@ -122,43 +369,294 @@ func (r *ConfigMapPropagationReconciler) SyncTargets(ctx context.Context, config
 		}
 	}
 
+	if err := r.writeSummaryConfigMap(ctx, configmapPropagator, targetStatuses); err != nil {
+		r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "SummaryFailed", "failed to write the summary configmap: %v", err)
+	}
+
 	updateCmp := configmapPropagator.DeepCopy()
 
 	updateCmp.Status.TargetsSummary = targetSummary
 	updateCmp.Status.TargetStatuses = targetStatuses
-	updateCmp.Status.LastSyncedAt = metav1.NewTime(time.Now())
+	if due {
+		updateCmp.Status.LastSyncedAt = metav1.NewTime(r.now())
+	}
+	updateCmp.Status.TargetSyncTimes = r.targetSyncTimes(configmapPropagator, desired, synced)
+	if due {
+		// The revision follows the data the targets were just synced with
+		// The revision is kept when the data could not be prepared, the targets report why
+		if revision, revisionHash, err := propagatedRevision(configmapPropagator, source); err == nil {
+			updateCmp.Status.Revision, updateCmp.Status.RevisionHash = revision, revisionHash
+		}
+	}
+	updateCmp.Status.CurrentTargetName = ""
+	if configmapPropagator.Spec.HashSuffixTargetNames {
+		hash, err := sourceDataHash(configmapPropagator, source)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		updateCmp.Status.CurrentTargetName = source.Name + "-" + hash
+	}
+	updateCmp.Status.MatchedNamespaceCount, updateCmp.Status.ManagedCount = targetCounts(desiredMap, currentMap, synced, targetStatuses)
+	recordSyncMetrics(configmapPropagator, targetSummary, updateCmp.Status.ManagedCount)
+	logf.FromContext(ctx).Info("synced targets",
+		"created", targetSummary.Created, "updated", targetSummary.Updated,
+		"deleted", targetSummary.Deleted, "failed", targetSummary.Failed, "skipped", targetSummary.Skipped,
+		"duration", time.Since(start), "syncMode", r.syncMode(configmapPropagator))
+	updateCmp.Status.BatchCursor = batchCursor
+	if r.syncMode(configmapPropagator) == syncv1alpha1.SyncModePeriodic {
+		interval := r.syncInterval(configmapPropagator)
+		updateCmp.Status.EffectiveSyncInterval = &metav1.Duration{Duration: interval}
+		requested := configmapPropagator.Spec.SyncInterval
+		clampedNow := requested != nil && requested.Duration < interval
+		previous := configmapPropagator.Status.EffectiveSyncInterval
+		if clampedNow && (previous == nil || previous.Duration != interval) {
+			r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "SyncIntervalClamped",
+				"syncInterval %s is below the minimum, using %s", requested.Duration, interval)
+		}
+	} else {
+		updateCmp.Status.EffectiveSyncInterval = nil
+	}
 	if targetSummary.Failed > 0 {
 		failedParts := make([]string, 0, len(targetStatuses))
 		for _, t := range targetStatuses {
+			if !targetFailed(t) {
+				continue
+			}
 			failedParts = append(failedParts, fmt.Sprintf("%s/%s", t.Namespace, t.Name))
 		}
 		meta.SetStatusCondition(&updateCmp.Status.Conditions, metav1.Condition{
-			Type:    "UnReady",
+			Type:    ConditionTypeReady,
 			Status:  metav1.ConditionFalse,
 			Reason:  "SyncFailed",
 			Message: fmt.Sprintf("Sync Failed for: %s", strings.Join(failedParts, ",")),
 		})
-	} else {
+	} else if batchCursor == "" {
 
 		updateCmp.Status.SyncedGeneration = fmt.Sprintf("%d", configmapPropagator.Generation)
-		updateCmp.Status.LastSuccessfulSync = metav1.NewTime(time.Now())
+		updateCmp.Status.ObservedGeneration = configmapPropagator.Generation
+		updateCmp.Status.LastSuccessfulSync = metav1.NewTime(r.now())
 		meta.SetStatusCondition(&updateCmp.Status.Conditions, metav1.Condition{
-			Type:    "Ready",
-			Status:  metav1.ConditionTrue,
-			Reason:  "Synced",
-			Message: "All Objects have been synced",
+			Type:               ConditionTypeReady,
+			Status:             metav1.ConditionTrue,
+			Reason:             "Synced",
+			Message:            "All Objects have been synced",
+			ObservedGeneration: configmapPropagator.Generation,
 		})
 	}
 
-	if !equality.Semantic.DeepEqual(configmapPropagator.Status, updateCmp.Status) {
-		if err := r.Status().Patch(ctx, updateCmp, client.MergeFrom(configmapPropagator)); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to update the status of configmappropagator")
+	setDriftCorrectedCondition(updateCmp, targetStatuses, configmapPropagator.Generation)
+	setKeysSkippedCondition(updateCmp, source, configmapPropagator.Generation)
+
+	// Failures used to be reported in a separate UnReady condition, Ready now covers both outcomes
+	meta.RemoveStatusCondition(&updateCmp.Status.Conditions, legacyConditionTypeUnReady)
+
+	if statusChanged(configmapPropagator.Status, updateCmp.Status) {
+		if err := r.patchStatus(ctx, configmapPropagator, updateCmp); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update the status of configmappropagator: %w", err)
 		}
 	}
 
-	if targetSummary.Failed > 0 {
+	// Failures carried from earlier batches don't hold up the remaining batches, they are retried once the pass ends
+	failed := targetSummary.Failed
+	if batchCursor != "" {
+		failed -= carriedFailures
+	}
+	if failed > 0 && failed == policyDenied+backingOff {
+		// Policy rejections do not go away on retry, wait instead of hammering the webhook
+		// Failing targets are retried when their backoff ends, the healthy ones are already synced
+		var requeue time.Duration
+		if policyDenied > 0 {
+			requeue = policyDeniedRequeueDelay
+		}
+		return ctrl.Result{RequeueAfter: earliestRetry(requeue, backoffRetry)}, nil
+	}
+	if failed > 0 {
 		return ctrl.Result{}, fmt.Errorf("failed to sync the targets")
 	}
+	if batchCursor != "" {
+		return ctrl.Result{RequeueAfter: batchRequeueDelay}, nil
+	}
+
+	// updateCmp carries the LastSyncedAt of this sync, the next Periodic sync is scheduled from it
+	result := r.withPeriodicRequeue(updateCmp, ctrl.Result{RequeueAfter: retireWait})
+	return r.withOverrideRequeue(configmapPropagator, withTTLRequeue(configmapPropagator, result)), nil
+}
+
+// keyCollisionStatus reports a target that failed because the KeyTransform maps source keys together.
+func keyCollisionStatus(t *PropagatorTarget, collisionErr *KeyCollisionError) syncv1alpha1.TargetStatus {
+	return syncv1alpha1.TargetStatus{
+		Namespace: t.Namespace,
+		Name:      t.ConfigmapName,
+		State:     "Failed",
+		Reason:    "KeyTransformCollision",
+		Message:   collisionErr.Error(),
+	}
+}
+
+// nextBatch narrows the pending creates, updates and deletes to the next BatchSize targets after the
+// status cursor, in namespace/name order. It returns the cursor to store, which is empty once the last
+// batch has been handed out.
+func nextBatch(configmapPropagator *syncv1alpha1.ConfigMapPropagation, toCreate, toUpdate, toDelete []*PropagatorTarget) ([]*PropagatorTarget, []*PropagatorTarget, []*PropagatorTarget, string) {
+	batchSize := int(configmapPropagator.Spec.BatchSize)
+	if batchSize <= 0 {
+		return toCreate, toUpdate, toDelete, ""
+	}
+
+	cursor := configmapPropagator.Status.BatchCursor
+	pending := make([]string, 0, len(toCreate)+len(toUpdate)+len(toDelete))
+	for _, targets := range [][]*PropagatorTarget{toCreate, toUpdate, toDelete} {
+		for _, t := range targets {
+			if key := t.Namespace + "/" + t.ConfigmapName; key > cursor {
+				pending = append(pending, key)
+			}
+		}
+	}
+	sort.Strings(pending)
+
+	nextCursor := ""
+	if len(pending) > batchSize {
+		pending = pending[:batchSize]
+		nextCursor = pending[batchSize-1]
+	}
+	inBatch := make(map[string]struct{}, len(pending))
+	for _, key := range pending {
+		inBatch[key] = struct{}{}
+	}
+
+	filter := func(targets []*PropagatorTarget) []*PropagatorTarget {
+		kept := make([]*PropagatorTarget, 0, len(targets))
+		for _, t := range targets {
+			if _, ok := inBatch[t.Namespace+"/"+t.ConfigmapName]; ok {
+				kept = append(kept, t)
+			}
+		}
+		return kept
+	}
+	return filter(toCreate), filter(toUpdate), filter(toDelete), nextCursor
+}
+
+// carriedBatchFailures returns the failed target statuses of the earlier batches of the batched sync in progress,
+// for the targets that are still pending. They are reported until the pass ends, so the last batch doesn't mark
+// the propagation as synced over them.
+func carriedBatchFailures(configmapPropagator *syncv1alpha1.ConfigMapPropagation, pending ...[]*PropagatorTarget) []syncv1alpha1.TargetStatus {
+	cursor := configmapPropagator.Status.BatchCursor
+	if cursor == "" {
+		return nil
+	}
+	pendingKeys := map[string]struct{}{}
+	for _, targets := range pending {
+		for _, t := range targets {
+			pendingKeys[t.Namespace+"/"+t.ConfigmapName] = struct{}{}
+		}
+	}
+	var carried []syncv1alpha1.TargetStatus
+	for _, status := range configmapPropagator.Status.TargetStatuses {
+		key := status.Namespace + "/" + status.Name
+		if !targetFailed(status) || key > cursor {
+			continue
+		}
+		if _, ok := pendingKeys[key]; ok {
+			carried = append(carried, status)
+		}
+	}
+	return carried
+}
+
+// abortCanary stops the propagation after a failed canary sync, leaving the remaining targets untouched.
+// A canary that is denied by policy or backing off is retried after retry instead of failing the reconcile.
+func (r *ConfigMapPropagationReconciler) abortCanary(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, canaryErr error, retry time.Duration) (ctrl.Result, error) {
+	r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "CanaryFailed", "%v", canaryErr)
+	if err := r.markNotReady(ctx, configmapPropagator, "CanaryFailed", canaryErr.Error()); err != nil {
+		return ctrl.Result{}, err
+	}
+	if retry > 0 {
+		return ctrl.Result{RequeueAfter: retry}, nil
+	}
+	return ctrl.Result{}, canaryErr
+}
+
+// canaryTarget returns the target in the canary namespace, nil when none of the targets is there.
+func canaryTarget(desiredMap map[string]*PropagatorTarget, canaryNs string) *PropagatorTarget {
+	var canary *PropagatorTarget
+	for _, t := range desiredMap {
+		// Several targets can share the namespace, the first by name is the canary so every reconcile picks the same
+		if t.Namespace == canaryNs && (canary == nil || t.ConfigmapName < canary.ConfigmapName) {
+			canary = t
+		}
+	}
+	return canary
+}
+
+// canaryFailure returns the status the canary sync reported when it was not written, a skipped canary
+// counts as failed since it proves nothing about the other targets.
+func canaryFailure(statuses []syncv1alpha1.TargetStatus) (syncv1alpha1.TargetStatus, bool) {
+	for _, status := range statuses {
+		if status.State != "Synced" {
+			return status, true
+		}
+	}
+	return syncv1alpha1.TargetStatus{}, false
+}
 
-	return ctrl.Result{}, nil
+// removeTarget returns targets without the entry pointing at the same ConfigMap as target.
+func removeTarget(targets []*PropagatorTarget, target *PropagatorTarget) []*PropagatorTarget {
+	kept := make([]*PropagatorTarget, 0, len(targets))
+	for _, t := range targets {
+		if t.Namespace == target.Namespace && t.ConfigmapName == target.ConfigmapName {
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept
+}
+
+// targetFailed reports whether a target status counts as a failure. Drifted is only reported for targets
+// whose fields are owned by other managers and were left untouched, restored drift is Synced.
+func targetFailed(t syncv1alpha1.TargetStatus) bool {
+	return t.State == "Failed" || t.State == "Drifted"
+}
+
+// targetCounts returns the number of distinct namespaces among the desired targets and the number
+// of desired targets that exist (before or after this sync) and have no failed or drifted status.
+func targetCounts(desiredMap, currentMap map[string]*PropagatorTarget, synced []*PropagatorTarget, targetStatuses []syncv1alpha1.TargetStatus) (int32, int32) {
+	namespaces := make(map[string]struct{})
+	for _, t := range desiredMap {
+		namespaces[t.Namespace] = struct{}{}
+	}
+
+	failed := make(map[string]struct{})
+	for _, t := range targetStatuses {
+		if targetFailed(t) {
+			failed[t.Namespace+"/"+t.Name] = struct{}{}
+		}
+	}
+	exists := make(map[string]struct{}, len(currentMap)+len(synced))
+	for key := range currentMap {
+		exists[key] = struct{}{}
+	}
+	for _, t := range synced {
+		exists[t.Namespace+"/"+t.ConfigmapName] = struct{}{}
+	}
+
+	var managed int32
+	for key := range desiredMap {
+		if _, ok := failed[key]; ok {
+			continue
+		}
+		if _, ok := exists[key]; ok {
+			managed++
+		}
+	}
+	return int32(len(namespaces)), managed
+}
+
+// backingOffStatus reports a target that is not written until its backoff after failures consecutive failures ends.
+func backingOffStatus(t *PropagatorTarget, failures int, wait time.Duration) syncv1alpha1.TargetStatus {
+	return syncv1alpha1.TargetStatus{
+		Namespace: t.Namespace,
+		Name:      t.ConfigmapName,
+		State:     "Failed",
+		Reason:    "BackingOff",
+		Message:   fmt.Sprintf("failed %d times in a row, retrying in %s", failures, wait.Round(time.Second)),
+	}
 }
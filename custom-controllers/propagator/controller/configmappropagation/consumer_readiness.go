@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// refreshConsumerReadiness recomputes the consumer readiness status from the consuming Deployments in the
+// namespaces of the desired targets and patches it when it changed. It is a no-op unless TrackConsumerReadiness
+// is enabled.
+func (r *ConfigMapPropagationReconciler) refreshConsumerReadiness(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, source *corev1.ConfigMap, desired []*PropagatorTarget) error {
+	var readiness *syncv1alpha1.ConsumerReadinessStatus
+	if r.TrackConsumerReadiness && configmapPropagator.Spec.ConsumerReadiness != nil {
+		var err error
+		readiness, err = r.consumerReadiness(ctx, configmapPropagator, source, desired)
+		if err != nil {
+			return err
+		}
+	}
+	if equality.Semantic.DeepEqual(configmapPropagator.Status.ConsumerReadiness, readiness) {
+		return nil
+	}
+
+	updateCmp := configmapPropagator.DeepCopy()
+	updateCmp.Status.ConsumerReadiness = readiness
+	if err := r.Status().Patch(ctx, updateCmp, client.MergeFrom(configmapPropagator)); err != nil {
+		return fmt.Errorf("failed to update the consumer readiness of configmappropagator: %w", err)
+	}
+	configmapPropagator.Status.ConsumerReadiness = readiness
+	return nil
+}
+
+// consumerReadiness counts the Deployments selected in the target namespaces that run the current data.
+func (r *ConfigMapPropagationReconciler) consumerReadiness(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, source *corev1.ConfigMap, desired []*PropagatorTarget) (*syncv1alpha1.ConsumerReadinessStatus, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&configmapPropagator.Spec.ConsumerReadiness.DeploymentSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid consumer deployment selector: %w", err)
+	}
+	configHash, err := sourceDataHash(configmapPropagator, source)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := map[string]struct{}{}
+	for _, t := range desired {
+		namespaces[t.Namespace] = struct{}{}
+	}
+
+	readiness := &syncv1alpha1.ConsumerReadinessStatus{ConfigHash: configHash}
+	for ns := range namespaces {
+		var deployments appsv1.DeploymentList
+		if err := r.List(ctx, &deployments, client.InNamespace(ns), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, fmt.Errorf("failed to list consumer deployments in %s: %w", ns, err)
+		}
+		for i := range deployments.Items {
+			readiness.Total++
+			if consumerReady(&deployments.Items[i], configHash) {
+				readiness.Ready++
+				continue
+			}
+			readiness.NotReady = append(readiness.NotReady, ns+"/"+deployments.Items[i].Name)
+		}
+	}
+	sort.Strings(readiness.NotReady)
+	return readiness, nil
+}
+
+// consumerReady reports whether the Deployment's pod template carries the config hash and its rollout completed.
+func consumerReady(deployment *appsv1.Deployment, configHash string) bool {
+	if deployment.Spec.Template.Annotations[ConfigHashAnnotation] != configHash {
+		return false
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.AvailableReplicas == replicas
+}
+
+// mapConsumerDeployment returns the propagations tracking consumer readiness that manage a target
+// in the namespace of the Deployment.
+func (r *ConfigMapPropagationReconciler) mapConsumerDeployment(ctx context.Context, obj client.Object) []reconcile.Request {
+	var managed corev1.ConfigMapList
+	if err := r.List(ctx, &managed, client.InNamespace(obj.GetNamespace()), client.MatchingLabels{ManagedByLabelKey: ManagedByLabelValue}); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list managed configmaps for deployment event")
+		return nil
+	}
+	owners := map[string]struct{}{}
+	for _, cm := range managed.Items {
+		if owner := cm.Labels[OwnerLabelKey]; owner != "" {
+			owners[owner] = struct{}{}
+		}
+	}
+
+	requests := make([]reconcile.Request, 0, len(owners))
+	for owner := range owners {
+		var cmp syncv1alpha1.ConfigMapPropagation
+		if err := r.Get(ctx, types.NamespacedName{Name: owner}, &cmp); err != nil || cmp.Spec.ConsumerReadiness == nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: owner}})
+	}
+	return requests
+}
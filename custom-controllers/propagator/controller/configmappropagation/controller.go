@@ -20,23 +20,55 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type PropagatorTarget struct {
 	ConfigmapName string
 	Namespace     string
+	// Drifted is set by updateIfNeeded when it restored data that was edited outside the controller
+	Drifted bool
+	// Unchanged is set by updateIfNeeded when the target was already up to date and nothing was written
+	Unchanged bool
+	// DriftedKeys are the keys restored on a drifted target, DriftCount how often the target drifted so far
+	DriftedKeys []string
+	DriftCount  int
+	// SyncMode and SyncInterval are the per-target overrides from the TargetRef, if any
+	SyncMode     syncv1alpha1.SyncMode
+	SyncInterval *metav1.Duration
+	// HashOf is the unsuffixed name of a hash suffixed target
+	HashOf string
+	// SourceHash is the sourceRevisionHash of this sync, targets already stamped with it are not updated
+	SourceHash string
+	// Source is the source read for this sync with the AdditionalSources merged in
+	Source *corev1.ConfigMap
 }
 
 // ConfigMapPropagationReconciler reconciles a ConfigMapPropagation object
@@ -44,16 +76,93 @@ type ConfigMapPropagationReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+
+	// DefaultSyncMode is used for propagations that arrive without a SyncMode,
+	// e.g. when the CRD default was not applied. Falls back to OnChange when unset.
+	DefaultSyncMode syncv1alpha1.SyncMode
+
+	// MinSyncInterval is the smallest SyncInterval honored in Periodic mode, smaller values are clamped to it.
+	MinSyncInterval time.Duration
+
+	// SyncJitter moves the Periodic schedule of every propagation by a stable offset of up to that fraction
+	// of its SyncInterval, e.g. 0.1 for ±10%, so propagations sharing an interval don't sync at once.
+	// 0 disables it.
+	SyncJitter float64
+
+	// Clock is the time source of the sync schedule, target expiry and the source breaker.
+	// SetupWithManager defaults it to the wall clock.
+	Clock clock.PassiveClock
+
+	// NamespaceCoalesceDelay is how long namespace events are coalesced before the affected propagations
+	// are reconciled. Defaults to defaultNamespaceCoalesceDelay when zero.
+	NamespaceCoalesceDelay time.Duration
+
+	// MaxConcurrentPerSource limits how many propagations sharing one source ConfigMap sync concurrently.
+	// Zero means no limit.
+	MaxConcurrentPerSource int
+
+	// MaxConcurrentReconciles is the number of propagations reconciled in parallel, defaults to 1.
+	// Raising it is safe: a request is only ever handled by one worker, targets are keyed by the owner
+	// label, status is patched with MergeFrom and the shared limiter and breaker are locked.
+	MaxConcurrentReconciles int
+
+	// SourceFailureThreshold is the number of consecutive source fetch errors after which the source is
+	// considered unavailable and only probed every SourceBreakerBackoff.
+	SourceFailureThreshold int
+	SourceBreakerBackoff   time.Duration
+
+	// TargetBackoffBase is the delay before a target whose write failed is retried, doubled on every
+	// consecutive failure up to TargetBackoffMax. The other targets are synced in the meantime.
+	TargetBackoffBase time.Duration
+	TargetBackoffMax  time.Duration
+
+	// AllowSecretToConfigMap allows propagations to read the allowlisted keys of a Secret as their source.
+	AllowSecretToConfigMap bool
+
+	// MaxConfigMapsPerNamespace skips creating targets in namespaces that already hold that many ConfigMaps.
+	// 0 disables the check.
+	MaxConfigMapsPerNamespace int
+
+	// TrackConsumerReadiness watches Deployments to report the readiness of the consumers of the targets
+	// for propagations that set ConsumerReadiness.
+	TrackConsumerReadiness bool
+
+	// OrphanSweepInterval is how often orphaned targets are checked for an expired TargetTTL,
+	// defaults to defaultOrphanSweepInterval when zero.
+	OrphanSweepInterval time.Duration
+
+	// ListPageSize is the page size of the target and namespace lists, read through BulkListReader.
+	// 0 reads them from the cache-backed client in one call.
+	ListPageSize int64
+	// BulkListReader is an uncached reader, e.g. the manager's API reader, used for the paginated lists.
+	BulkListReader client.Reader
+
+	limiterOnce sync.Once
+	limiter     *sourceLimiter
+	breakerOnce sync.Once
+	breaker     *sourceBreaker
+	backoffOnce sync.Once
+	backoff     *targetBackoff
+
+	// AllowedSourceNamespaces restricts the namespaces a source ConfigMap may live in. Empty allows all.
+	AllowedSourceNamespaces []string
 }
 
 // +kubebuilder:rbac:groups=sync.propagators.io,resources=configmappropagations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=sync.propagators.io,resources=configmappropagations/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=sync.propagators.io,resources=configmappropagations/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile syncs the target ConfigMaps of a ConfigMapPropagation with its source ConfigMap.
 func (r *ConfigMapPropagationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
-	log.Info("new sync request for configmap propagator", "configmap name", req.Name, "configmap ns", req.Namespace)
-	log.Info("getting the configmap propagator resource with the client")
+	log.V(1).Info("new sync request for configmap propagator", "configmap name", req.Name, "configmap ns", req.Namespace)
+	log.V(1).Info("getting the configmap propagator resource with the client")
 
 	var configmapPropagator syncv1alpha1.ConfigMapPropagation
 	err := r.Client.Get(ctx, req.NamespacedName, &configmapPropagator)
@@ -65,7 +174,10 @@ func (r *ConfigMapPropagationReconciler) Reconcile(ctx context.Context, req ctrl
 		return ctrl.Result{}, err
 	}
 
-	log.Info("spec of configmap propagator", "cr spec", configmapPropagator.Spec)
+	log.V(1).Info("spec of configmap propagator", "cr spec", configmapPropagator.Spec)
+	if r.syncMode(&configmapPropagator) == syncv1alpha1.SyncModePeriodic && configmapPropagator.Spec.SyncInterval == nil {
+		log.Info("warning: Periodic propagation has no syncInterval, using the default", "syncInterval", defaultSyncInterval)
+	}
 
 	// Checking for Deletion Timestamp and deleting the cr if present
 	if !configmapPropagator.DeletionTimestamp.IsZero() {
@@ -82,63 +194,368 @@ func (r *ConfigMapPropagationReconciler) Reconcile(ctx context.Context, req ctrl
 	}
 
 	// Add finalizer if it doesn't exist
-	if !controllerutil.ContainsFinalizer(&configmapPropagator, FinalizerName) {
-		controllerutil.AddFinalizer(&configmapPropagator, FinalizerName)
-		log.Info("Added the Finalizer for configmap propagator and updating using the client")
-		if err := r.Update(ctx, &configmapPropagator); err != nil {
+	if err := ownership.AddFinalizer(ctx, r.Client, &configmapPropagator); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Re-adopt targets whose owner label was stripped so they are not treated as gone
+	if !isDryRun(&configmapPropagator) {
+		if err := r.adoptStrippedTargets(ctx, &configmapPropagator); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
-	// Need to check if we should go forward or not (and need to add a logic based on policy to decide to go forward or not)
-	if !shouldRefresh(&configmapPropagator) {
-		return r.getRequeueResult(&configmapPropagator), nil
+	// Refuse to read sources from namespaces the controller is not allowed to propagate from
+	if ns, allowed := r.sourceNamespaceAllowed(&configmapPropagator); !allowed {
+		r.Recorder.Eventf(&configmapPropagator, corev1.EventTypeWarning, "DisallowedSource",
+			"source namespace %q is not in the allowed source namespaces", ns)
+		return ctrl.Result{}, r.markNotReady(ctx, &configmapPropagator, "DisallowedSource",
+			fmt.Sprintf("source namespace %q is not allowed, allowed namespaces: %s",
+				ns, strings.Join(r.AllowedSourceNamespaces, ",")))
+	}
+
+	// Copying Secret data into ConfigMaps has to be enabled for the whole controller
+	if configmapPropagator.Spec.SecretSource != nil && !r.AllowSecretToConfigMap {
+		r.Recorder.Eventf(&configmapPropagator, corev1.EventTypeWarning, "SecretSourceNotAllowed",
+			"secretSource requires the controller to run with --allow-secret-to-configmap")
+		return ctrl.Result{}, r.markNotReady(ctx, &configmapPropagator, "SecretSourceNotAllowed",
+			"secretSource requires the controller to run with --allow-secret-to-configmap")
+	}
+
+	if err := validateKeyFilters(&configmapPropagator); err != nil {
+		r.Recorder.Eventf(&configmapPropagator, corev1.EventTypeWarning, "InvalidKeyFilter", "%v", err)
+		return ctrl.Result{}, r.markNotReady(ctx, &configmapPropagator, "InvalidKeyFilter", err.Error())
 	}
 
-	// Check for intial ConfigMap
-	var sourceConfig corev1.ConfigMap
-	err = r.Client.Get(ctx, types.NamespacedName{
-		Name:      configmapPropagator.Spec.Source.Name,
-		Namespace: configmapPropagator.Spec.Source.Namespace,
-	}, &sourceConfig)
+	// An open circuit breaker skips the source until the next probe is due
+	if allowed, wait := r.sourceBreaker().allow(configmapPropagator.Name, configmapPropagator.Generation, r.now()); !allowed {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+	// Check for intial ConfigMap, a deleted source is handled regardless of the sync mode
+	sourceConfig, err := r.getSource(ctx, &configmapPropagator)
+	if apierrors.IsNotFound(err) {
+		r.sourceBreaker().success(configmapPropagator.Name)
+		return r.handleSourceDeleted(ctx, &configmapPropagator, err)
+	}
+	// Relabelling the ConfigMaps enqueues the propagation again, retrying before that can't pick a source
+	var ambiguousErr *AmbiguousSourceError
+	if errors.As(err, &ambiguousErr) {
+		r.sourceBreaker().success(configmapPropagator.Name)
+		r.Recorder.Eventf(&configmapPropagator, corev1.EventTypeWarning, "AmbiguousSource", "%v", err)
+		return ctrl.Result{}, r.markNotReady(ctx, &configmapPropagator, "AmbiguousSource", err.Error())
+	}
+	// Only a missing Source applies OnSourceDelete, the propagation waits for a missing AdditionalSource to be created
+	var additionalErr *AdditionalSourceNotFoundError
+	if errors.As(err, &additionalErr) {
+		r.sourceBreaker().success(configmapPropagator.Name)
+		r.Recorder.Eventf(&configmapPropagator, corev1.EventTypeWarning, "AdditionalSourceNotFound", "%v", err)
+		return ctrl.Result{}, r.markNotReady(ctx, &configmapPropagator, "AdditionalSourceNotFound", err.Error())
+	}
+	if err != nil {
+		r.Recorder.Eventf(&configmapPropagator, corev1.EventTypeWarning, "SourceConfigMap Get Failed", "%v", err)
+		if r.sourceBreaker().failure(configmapPropagator.Name, configmapPropagator.Generation, r.now()) {
+			_, wait := r.sourceBreaker().allow(configmapPropagator.Name, configmapPropagator.Generation, r.now())
+			if markErr := r.markNotReady(ctx, &configmapPropagator, "SourceUnavailable",
+				fmt.Sprintf("source ConfigMap keeps failing, retrying in %s: %v", wait, err)); markErr != nil {
+				return ctrl.Result{}, markErr
+			}
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	r.sourceBreaker().success(configmapPropagator.Name)
 
+	// The namespaces and targets are listed once, every check below and the sync share them
+	targets, err := r.loadTargets(ctx, &configmapPropagator)
 	if err != nil {
-		r.Recorder.Eventf(&configmapPropagator, corev1.EventTypeWarning, "SourceConfigMap Not Found", "%v", err)
-		return ctrl.Result{RequeueAfter: 5 * time.Minute}, err
+		r.Recorder.Eventf(&configmapPropagator, corev1.EventTypeWarning, "LoadTargetsFailed", "%v", err)
+		return ctrl.Result{}, err
+	}
+	if err := r.refreshConsumerReadiness(ctx, &configmapPropagator, sourceConfig, targets.desired); err != nil {
+		r.Recorder.Eventf(&configmapPropagator, corev1.EventTypeWarning, "ConsumerReadinessFailed", "%v", err)
+	}
+	due := r.propagationDue(&configmapPropagator, sourceConfig, targets)
+	overrideDue := r.overrideTargetsDue(&configmapPropagator, targets.desired)
+	duplicates := hasDuplicateTargets(targets.desired, targets.current)
+	targetSetChanged := hasTargetSetChanges(targets.desired, targets.current)
+	edited := editedTargets(&configmapPropagator, targets)
+	drifted := len(edited) > 0 && r.syncMode(&configmapPropagator) != syncv1alpha1.SyncModeCreatedOnce
+	sourceRestored := sourceWasMissing(&configmapPropagator)
+	if !due && !overrideDue && !duplicates && !targetSetChanged && !drifted && !sourceRestored {
+		result := withTTLRequeue(&configmapPropagator, r.getRequeueResult(&configmapPropagator))
+		return r.withOverrideRequeue(&configmapPropagator, result), nil
 	}
 
-	return r.SyncTargets(ctx, &configmapPropagator)
+	// Propagations sharing a busy source are retried shortly instead of all syncing at once
+	sourceKey := sourceConfig.Namespace + "/" + sourceConfig.Name
+	if !r.sourceLimiter().tryAcquire(sourceKey) {
+		log.Info("source is busy with other propagations, retrying later", "source", sourceKey)
+		return ctrl.Result{RequeueAfter: sourceBusyRequeueDelay}, nil
+	}
+	defer r.sourceLimiter().release(sourceKey)
+
+	return r.syncTargets(ctx, &configmapPropagator, sourceConfig, targets)
+}
+
+// sourceBreaker returns the source circuit breaker, created on first use.
+func (r *ConfigMapPropagationReconciler) sourceBreaker() *sourceBreaker {
+	r.breakerOnce.Do(func() {
+		r.breaker = newSourceBreaker(r.SourceFailureThreshold, r.SourceBreakerBackoff)
+	})
+	return r.breaker
 }
 
-func shouldRefresh(configmapPropagation *syncv1alpha1.ConfigMapPropagation) bool {
-	switch configmapPropagation.Spec.SyncMode {
+// targetBackoff returns the per-target backoff, created on first use.
+func (r *ConfigMapPropagationReconciler) targetBackoff() *targetBackoff {
+	r.backoffOnce.Do(func() {
+		r.backoff = newTargetBackoff(r.TargetBackoffBase, r.TargetBackoffMax)
+	})
+	return r.backoff
+}
+
+// sourceLimiter returns the per-source limiter, created on first use from MaxConcurrentPerSource.
+func (r *ConfigMapPropagationReconciler) sourceLimiter() *sourceLimiter {
+	r.limiterOnce.Do(func() {
+		r.limiter = newSourceLimiter(r.MaxConcurrentPerSource)
+	})
+	return r.limiter
+}
+
+// handleSourceDeleted applies the OnSourceDelete policy once the source ConfigMap is gone.
+func (r *ConfigMapPropagationReconciler) handleSourceDeleted(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, notFound error) (ctrl.Result, error) {
+	switch configmapPropagator.Spec.OnSourceDelete {
+	case syncv1alpha1.OnSourceDeleteDeleteTargets:
+		targets, err := r.getCurrentTargets(ctx, configmapPropagator)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		for _, t := range targets {
+			if err := r.deleteConfigMap(ctx, t.Namespace, t.ConfigmapName); err != nil {
+				r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "DeleteFailed", " %s/%s delete failed: %v", t.Namespace, t.ConfigmapName, err)
+				return ctrl.Result{}, err
+			}
+			r.Recorder.Eventf(configmapPropagator, corev1.EventTypeNormal, "DeletedTarget", "deleted propagated ConfigMap %s/%s", t.Namespace, t.ConfigmapName)
+		}
+		if err := r.markNotReady(ctx, configmapPropagator, "SourceDeleted",
+			fmt.Sprintf("source ConfigMap was deleted, removed %d targets", len(targets))); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	case syncv1alpha1.OnSourceDeleteMarkStale:
+		if err := r.markNotReady(ctx, configmapPropagator, "SourceDeleted",
+			"source ConfigMap was deleted, targets are stale"); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	default:
+		r.Recorder.Eventf(configmapPropagator, corev1.EventTypeWarning, "SourceConfigMap Not Found", "%v", notFound)
+		if err := r.markSourceNotFound(ctx, configmapPropagator, notFound); err != nil {
+			return ctrl.Result{}, err
+		}
+		// The condition reports the missing source, an error would replace the requeue with the rate limiter
+		return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	}
+}
+
+// markSourceNotFound sets the Ready condition of the propagation to False with reason SourceNotFound
+// and records the attempt in LastSyncedAt, so the status shows why nothing is propagated.
+func (r *ConfigMapPropagationReconciler) markSourceNotFound(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, notFound error) error {
+	updateCmp := configmapPropagator.DeepCopy()
+	updateCmp.Status.LastSyncedAt = metav1.NewTime(r.now())
+	meta.SetStatusCondition(&updateCmp.Status.Conditions, metav1.Condition{
+		Type:    ConditionTypeReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  "SourceNotFound",
+		Message: notFound.Error(),
+	})
+	if err := r.patchStatus(ctx, configmapPropagator, updateCmp); err != nil {
+		return fmt.Errorf("failed to update the status of configmappropagator: %w", err)
+	}
+	return nil
+}
+
+// sourceWasMissing reports whether the Ready condition still says the source is gone. Once the source
+// is back the propagation is synced again so the condition recovers even when nothing else is due.
+func sourceWasMissing(configmapPropagator *syncv1alpha1.ConfigMapPropagation) bool {
+	ready := meta.FindStatusCondition(configmapPropagator.Status.Conditions, ConditionTypeReady)
+	return ready != nil && ready.Status == metav1.ConditionFalse && (ready.Reason == "SourceNotFound" || ready.Reason == "SourceDeleted")
+}
+
+// markNotReady sets the Ready condition of the propagation to False with the given reason.
+func (r *ConfigMapPropagationReconciler) markNotReady(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, reason, message string) error {
+	updateCmp := configmapPropagator.DeepCopy()
+	meta.SetStatusCondition(&updateCmp.Status.Conditions, metav1.Condition{
+		Type:    ConditionTypeReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	})
+	if equality.Semantic.DeepEqual(configmapPropagator.Status, updateCmp.Status) {
+		return nil
+	}
+	if err := r.patchStatus(ctx, configmapPropagator, updateCmp); err != nil {
+		return fmt.Errorf("failed to update the status of configmappropagator: %w", err)
+	}
+	return nil
+}
+
+// patchStatus patches the status of updateCmp onto the propagation. On a conflict with a concurrent
+// update the latest propagation is fetched and the same status is patched onto it again.
+func (r *ConfigMapPropagationReconciler) patchStatus(ctx context.Context, configmapPropagator, updateCmp *syncv1alpha1.ConfigMapPropagation) error {
+	status := updateCmp.Status
+	base, patched := configmapPropagator, updateCmp
+	attempt := 0
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if attempt > 0 {
+			latest := &syncv1alpha1.ConfigMapPropagation{}
+			if err := r.Get(ctx, client.ObjectKeyFromObject(configmapPropagator), latest); err != nil {
+				return err
+			}
+			base, patched = latest, latest.DeepCopy()
+			patched.Status = status
+		}
+		attempt++
+		return r.Status().Patch(ctx, patched, client.MergeFrom(base))
+	})
+}
+
+// statusChanged reports whether the status differs from the stored one in more than LastSuccessfulSync.
+// Reconciles triggered by events between two due syncs change nothing and are not written. LastSyncedAt only
+// moves on a due sync, so it is still written once per SyncInterval and the next sync, also after a restart,
+// is scheduled from the stored value.
+func statusChanged(stored, updated syncv1alpha1.ConfigMapPropagationStatus) bool {
+	stored.LastSuccessfulSync, updated.LastSuccessfulSync = metav1.Time{}, metav1.Time{}
+	return !equality.Semantic.DeepEqual(stored, updated)
+}
+
+// ParseSyncMode validates s against the supported SyncMode values.
+func ParseSyncMode(s string) (syncv1alpha1.SyncMode, error) {
+	switch mode := syncv1alpha1.SyncMode(s); mode {
+	case syncv1alpha1.SyncModeCreatedOnce, syncv1alpha1.SyncModePeriodic, syncv1alpha1.SyncModeOnChange:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported sync mode %q, must be one of %s, %s, %s", s,
+			syncv1alpha1.SyncModeCreatedOnce, syncv1alpha1.SyncModePeriodic, syncv1alpha1.SyncModeOnChange)
+	}
+}
+
+// ParseNamespaceList splits a comma separated list of namespaces, dropping empty entries.
+func ParseNamespaceList(s string) []string {
+	namespaces := make([]string, 0)
+	for _, ns := range strings.Split(s, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// sourceNamespaceAllowed reports whether the namespaces of the source and of the AdditionalSources are
+// in AllowedSourceNamespaces, along with the first namespace that is not.
+func (r *ConfigMapPropagationReconciler) sourceNamespaceAllowed(configmapPropagation *syncv1alpha1.ConfigMapPropagation) (string, bool) {
+	if len(r.AllowedSourceNamespaces) == 0 {
+		return "", true
+	}
+	namespaces := []string{configmapPropagation.Spec.Source.NamespaceOrDefault()}
+	for _, additional := range configmapPropagation.Spec.AdditionalSources {
+		namespaces = append(namespaces, additionalSourceNamespace(configmapPropagation, additional))
+	}
+	for _, ns := range namespaces {
+		if !slices.Contains(r.AllowedSourceNamespaces, ns) {
+			return ns, false
+		}
+	}
+	return "", true
+}
+
+// syncMode returns the SyncMode of the propagation, falling back to the controller default when empty.
+func (r *ConfigMapPropagationReconciler) syncMode(configmapPropagation *syncv1alpha1.ConfigMapPropagation) syncv1alpha1.SyncMode {
+	if configmapPropagation.Spec.SyncMode != "" {
+		return configmapPropagation.Spec.SyncMode
+	}
+	if r.DefaultSyncMode != "" {
+		return r.DefaultSyncMode
+	}
+	return syncv1alpha1.SyncModeOnChange
+}
+
+// syncInterval returns the SyncInterval of the propagation clamped to MinSyncInterval,
+// so a tiny interval in Periodic mode can't hammer the API server.
+func (r *ConfigMapPropagationReconciler) syncInterval(configmapPropagation *syncv1alpha1.ConfigMapPropagation) time.Duration {
+	interval := defaultSyncInterval
+	if configmapPropagation.Spec.SyncInterval != nil {
+		interval = configmapPropagation.Spec.SyncInterval.Duration
+	}
+	return r.clampSyncInterval(interval)
+}
+
+// periodicInterval returns the syncInterval moved by the SyncJitter offset of the propagation. The offset is
+// derived from the UID, so every reconcile of a propagation agrees on when its next sync is due.
+func (r *ConfigMapPropagationReconciler) periodicInterval(configmapPropagation *syncv1alpha1.ConfigMapPropagation) time.Duration {
+	interval := r.syncInterval(configmapPropagation)
+	if interval == 0 || r.SyncJitter <= 0 {
+		return interval
+	}
+	h := fnv.New64a()
+	h.Write([]byte(configmapPropagation.UID))
+	// spread is in [-1, 1]
+	spread := float64(h.Sum64())/math.MaxUint64*2 - 1
+	return max(interval+time.Duration(spread*r.SyncJitter*float64(interval)), r.MinSyncInterval)
+}
+
+// clampSyncInterval raises interval to MinSyncInterval when it is smaller. Zero disables the
+// periodic syncs and is kept as is.
+func (r *ConfigMapPropagationReconciler) clampSyncInterval(interval time.Duration) time.Duration {
+	if interval != 0 && interval < r.MinSyncInterval {
+		return r.MinSyncInterval
+	}
+	return interval
+}
+
+// generationSynced reports whether the current spec generation was synced successfully. Statuses written
+// before ObservedGeneration existed only carry SyncedGeneration.
+func generationSynced(configmapPropagation *syncv1alpha1.ConfigMapPropagation) bool {
+	if configmapPropagation.Status.ObservedGeneration != 0 {
+		return configmapPropagation.Status.ObservedGeneration == configmapPropagation.Generation
+	}
+	return configmapPropagation.Status.SyncedGeneration == fmt.Sprintf("%d", configmapPropagation.Generation)
+}
+
+// shouldRefresh reports whether the SyncMode requires a sync at now.
+func shouldRefresh(configmapPropagation *syncv1alpha1.ConfigMapPropagation, mode syncv1alpha1.SyncMode, interval time.Duration, now time.Time) bool {
+	// A batched sync that is in progress always continues
+	if configmapPropagation.Status.BatchCursor != "" {
+		return true
+	}
+	switch mode {
 	case syncv1alpha1.SyncModeCreatedOnce:
 		if configmapPropagation.Status.SyncedGeneration == "" || configmapPropagation.Status.LastSuccessfulSync.IsZero() {
 			return true
 		}
 		return false
 	case syncv1alpha1.SyncModeOnChange:
-		expected := fmt.Sprintf("%d", configmapPropagation.Generation)
-		if configmapPropagation.Status.SyncedGeneration == "" || configmapPropagation.Status.SyncedGeneration != expected {
-			return true
-		}
-		return false
+		return !generationSynced(configmapPropagation)
 	case syncv1alpha1.SyncModePeriodic:
-		expected := fmt.Sprintf("%d", configmapPropagation.Generation)
-		if configmapPropagation.Status.SyncedGeneration == "" || configmapPropagation.Status.SyncedGeneration != expected {
+		if !generationSynced(configmapPropagation) {
 			return true
 		}
-		return configmapPropagation.Status.LastSyncedAt.Add(configmapPropagation.Spec.SyncInterval.Duration).Before(time.Now())
+		// A zero interval never syncs on a timer, only on changes
+		return interval > 0 && configmapPropagation.Status.LastSyncedAt.Add(interval).Before(now)
 	default:
 		return false
 	}
 }
 
+// getRequeueResult schedules the next reconcile for when the jittered SyncInterval has elapsed since LastSyncedAt.
+// OnChange propagations and Periodic ones with a zero SyncInterval are driven by watches and are not requeued.
 func (r *ConfigMapPropagationReconciler) getRequeueResult(configmapPropagation *syncv1alpha1.ConfigMapPropagation) ctrl.Result {
-	if configmapPropagation.Spec.SyncMode == syncv1alpha1.SyncModePeriodic || configmapPropagation.Spec.SyncMode == syncv1alpha1.SyncModeOnChange {
+	if r.syncMode(configmapPropagation) == syncv1alpha1.SyncModeOnChange {
+		return ctrl.Result{}
+	}
+	timeSinceLastSync, refreshInterval := r.now().Sub(configmapPropagation.Status.LastSyncedAt.Time), r.periodicInterval(configmapPropagation)
+	if refreshInterval == 0 {
 		return ctrl.Result{}
 	}
-	timeSinceLastSync, refreshInterval := time.Since(configmapPropagation.Status.LastSyncedAt.Time), configmapPropagation.Spec.SyncInterval.Duration
 	if timeSinceLastSync < 0 {
 		return ctrl.Result{Requeue: true}
 	}
@@ -148,11 +565,91 @@ func (r *ConfigMapPropagationReconciler) getRequeueResult(configmapPropagation *
 	return ctrl.Result{}
 }
 
+// withPeriodicRequeue makes sure a Periodic propagation is reconciled again when its next sync is due.
+func (r *ConfigMapPropagationReconciler) withPeriodicRequeue(configmapPropagation *syncv1alpha1.ConfigMapPropagation, result ctrl.Result) ctrl.Result {
+	if r.syncMode(configmapPropagation) != syncv1alpha1.SyncModePeriodic {
+		return result
+	}
+	next := r.getRequeueResult(configmapPropagation).RequeueAfter
+	if next > 0 && (result.RequeueAfter == 0 || next < result.RequeueAfter) {
+		result.RequeueAfter = next
+	}
+	return result
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ConfigMapPropagationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Recorder = mgr.GetEventRecorderFor("configmap-propagator")
-	return ctrl.NewControllerManagedBy(mgr).
+	if r.Clock == nil {
+		r.Clock = clock.RealClock{}
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&syncv1alpha1.ConfigMapPropagation{}).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.mapManagedConfigMap),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isManagedConfigMap))).
+		// Source edits are mapped back to every propagation reading the ConfigMap
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.mapSource),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool { return !isManagedConfigMap(obj) }))).
+		Watches(&corev1.Namespace{}, r.namespaceEventHandler())
+	if r.TrackConsumerReadiness {
+		b = b.Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(r.mapConsumerDeployment))
+	}
+	// Only the metadata of Secrets in the allowed source namespaces is watched, their data is read on demand
+	if r.AllowSecretToConfigMap {
+		b = b.Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.mapSecretSource),
+			builder.OnlyMetadata,
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return len(r.AllowedSourceNamespaces) == 0 || slices.Contains(r.AllowedSourceNamespaces, obj.GetNamespace())
+			})))
+	}
+	// Expired orphans are swept on their own, the propagation that orphaned them is usually gone
+	if err := mgr.Add(&orphanSweeper{Client: mgr.GetClient(), Recorder: r.Recorder, Clock: r.Clock, Interval: r.OrphanSweepInterval}); err != nil {
+		return err
+	}
+	return b.
+		WithOptions(r.controllerOptions()).
 		Named("configmappropagation").
 		Complete(r)
 }
+
+// controllerOptions returns the options the controller is built with.
+func (r *ConfigMapPropagationReconciler) controllerOptions() controller.Options {
+	workers := r.MaxConcurrentReconciles
+	if workers < 1 {
+		workers = 1
+	}
+	return controller.Options{MaxConcurrentReconciles: workers}
+}
+
+// isManagedConfigMap reports whether obj is a target of a propagation. The owner label alone is enough,
+// so a target whose managed-by label was stripped still reaches its propagation to be restored.
+func isManagedConfigMap(obj client.Object) bool {
+	labels := obj.GetLabels()
+	return labels[ManagedByLabelKey] == ManagedByLabelValue || labels[OwnerLabelKey] != ""
+}
+
+// mapManagedConfigMap enqueues the propagation owning a managed ConfigMap. The owner label is used
+// when present, otherwise the owner UID annotation is matched so stripped targets are still traced back.
+func (r *ConfigMapPropagationReconciler) mapManagedConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	if owner := obj.GetLabels()[OwnerLabelKey]; owner != "" {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: owner}}}
+	}
+	ownerUID := obj.GetAnnotations()[OwnerUIDAnnotation]
+	if ownerUID == "" {
+		return nil
+	}
+	var propagations syncv1alpha1.ConfigMapPropagationList
+	if err := r.List(ctx, &propagations); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list configmap propagators for managed configmap")
+		return nil
+	}
+	for _, cmp := range propagations.Items {
+		if string(cmp.UID) == ownerUID {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: cmp.Name}}}
+		}
+	}
+	return nil
+}
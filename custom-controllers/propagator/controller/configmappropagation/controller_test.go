@@ -0,0 +1,2448 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("SyncMode defaulting", func() {
+	It("uses the controller default when the CR has no SyncMode", func() {
+		cmp := newPropagation("no-mode", syncv1alpha1.ConfigMapPropagationSpec{
+			Source: syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+		})
+		cmp.Generation = 2
+		cmp.Status.SyncedGeneration = "1"
+		cmp.Status.LastSuccessfulSync = metav1.Now()
+
+		r := &ConfigMapPropagationReconciler{DefaultSyncMode: syncv1alpha1.SyncModeCreatedOnce}
+		Expect(r.syncMode(cmp)).To(Equal(syncv1alpha1.SyncModeCreatedOnce))
+		Expect(shouldRefresh(cmp, r.syncMode(cmp), r.syncInterval(cmp), time.Now())).To(BeFalse())
+
+		r.DefaultSyncMode = ""
+		Expect(r.syncMode(cmp)).To(Equal(syncv1alpha1.SyncModeOnChange))
+		Expect(shouldRefresh(cmp, r.syncMode(cmp), r.syncInterval(cmp), time.Now())).To(BeTrue())
+	})
+
+	It("prefers the SyncMode set on the CR", func() {
+		cmp := newPropagation("with-mode", syncv1alpha1.ConfigMapPropagationSpec{
+			SyncMode: syncv1alpha1.SyncModePeriodic,
+		})
+		r := &ConfigMapPropagationReconciler{DefaultSyncMode: syncv1alpha1.SyncModeCreatedOnce}
+		Expect(r.syncMode(cmp)).To(Equal(syncv1alpha1.SyncModePeriodic))
+	})
+
+	It("falls back to the default interval for a Periodic CR without a SyncInterval", func() {
+		cmp := newPropagation("no-interval", syncv1alpha1.ConfigMapPropagationSpec{
+			SyncMode: syncv1alpha1.SyncModePeriodic,
+		})
+		cmp.Status.SyncedGeneration = "1"
+		cmp.Status.ObservedGeneration = 1
+		cmp.Status.LastSyncedAt = metav1.Now()
+
+		r := &ConfigMapPropagationReconciler{}
+		Expect(cmp.Spec.SyncInterval).To(BeNil())
+		Expect(r.syncInterval(cmp)).To(Equal(defaultSyncInterval))
+		Expect(func() {
+			Expect(shouldRefresh(cmp, r.syncMode(cmp), r.syncInterval(cmp), time.Now())).To(BeFalse())
+		}).NotTo(Panic())
+		Expect(func() { r.getRequeueResult(cmp) }).NotTo(Panic())
+	})
+
+	It("rejects unknown sync modes", func() {
+		mode, err := ParseSyncMode("OnChange")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mode).To(Equal(syncv1alpha1.SyncModeOnChange))
+
+		_, err = ParseSyncMode("Sometimes")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Sync timing", func() {
+	lastSync := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	interval := 10 * time.Minute
+
+	// syncedPropagation returns a propagation last synced at lastSync, generation 2 was edited after it
+	syncedPropagation := func(mode syncv1alpha1.SyncMode, generation int64) *syncv1alpha1.ConfigMapPropagation {
+		cmp := newPropagation("timing", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:       syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			SyncMode:     mode,
+			SyncInterval: &metav1.Duration{Duration: interval},
+		})
+		cmp.Generation = generation
+		cmp.Status.ObservedGeneration = 1
+		cmp.Status.SyncedGeneration = "1"
+		cmp.Status.LastSyncedAt = metav1.NewTime(lastSync)
+		cmp.Status.LastSuccessfulSync = metav1.NewTime(lastSync)
+		return cmp
+	}
+
+	DescribeTable("shouldRefresh",
+		func(mode syncv1alpha1.SyncMode, generation int64, elapsed time.Duration, want bool) {
+			fakeClock := clocktesting.NewFakeClock(lastSync)
+			fakeClock.Step(elapsed)
+			r := &ConfigMapPropagationReconciler{Clock: fakeClock}
+			cmp := syncedPropagation(mode, generation)
+			Expect(shouldRefresh(cmp, r.syncMode(cmp), r.syncInterval(cmp), r.now())).To(Equal(want))
+		},
+		Entry("CreatedOnce short of the interval", syncv1alpha1.SyncModeCreatedOnce, int64(1), 5*time.Minute, false),
+		Entry("CreatedOnce past the interval", syncv1alpha1.SyncModeCreatedOnce, int64(1), 15*time.Minute, false),
+		Entry("CreatedOnce after a spec change", syncv1alpha1.SyncModeCreatedOnce, int64(2), 15*time.Minute, false),
+		Entry("OnChange short of the interval", syncv1alpha1.SyncModeOnChange, int64(1), 5*time.Minute, false),
+		Entry("OnChange past the interval", syncv1alpha1.SyncModeOnChange, int64(1), 15*time.Minute, false),
+		Entry("OnChange after a spec change", syncv1alpha1.SyncModeOnChange, int64(2), 5*time.Minute, true),
+		Entry("Periodic short of the interval", syncv1alpha1.SyncModePeriodic, int64(1), 5*time.Minute, false),
+		Entry("Periodic exactly at the interval", syncv1alpha1.SyncModePeriodic, int64(1), interval, false),
+		Entry("Periodic past the interval", syncv1alpha1.SyncModePeriodic, int64(1), 15*time.Minute, true),
+		Entry("Periodic after a spec change", syncv1alpha1.SyncModePeriodic, int64(2), 5*time.Minute, true),
+	)
+
+	It("refreshes a CreatedOnce propagation that never synced", func() {
+		cmp := syncedPropagation(syncv1alpha1.SyncModeCreatedOnce, 1)
+		cmp.Status.SyncedGeneration = ""
+		Expect(shouldRefresh(cmp, syncv1alpha1.SyncModeCreatedOnce, interval, lastSync)).To(BeTrue())
+	})
+
+	DescribeTable("getRequeueResult",
+		func(mode syncv1alpha1.SyncMode, elapsed time.Duration, want ctrl.Result) {
+			fakeClock := clocktesting.NewFakeClock(lastSync)
+			fakeClock.Step(elapsed)
+			r := &ConfigMapPropagationReconciler{Clock: fakeClock}
+			Expect(r.getRequeueResult(syncedPropagation(mode, 1))).To(Equal(want))
+		},
+		Entry("OnChange is not requeued", syncv1alpha1.SyncModeOnChange, 5*time.Minute, ctrl.Result{}),
+		Entry("Periodic waits for the rest of the interval", syncv1alpha1.SyncModePeriodic, 4*time.Minute, ctrl.Result{RequeueAfter: 6 * time.Minute}),
+		Entry("Periodic past the interval syncs now", syncv1alpha1.SyncModePeriodic, 15*time.Minute, ctrl.Result{}),
+		Entry("Periodic synced in the future requeues", syncv1alpha1.SyncModePeriodic, -time.Minute, ctrl.Result{Requeue: true}),
+	)
+
+	It("never refreshes or requeues a Periodic propagation with a zero interval on a timer", func() {
+		fakeClock := clocktesting.NewFakeClock(lastSync)
+		fakeClock.Step(24 * time.Hour)
+		r := &ConfigMapPropagationReconciler{Clock: fakeClock, MinSyncInterval: DefaultMinSyncInterval}
+		cmp := syncedPropagation(syncv1alpha1.SyncModePeriodic, 1)
+		cmp.Spec.SyncInterval = &metav1.Duration{}
+		Expect(r.syncInterval(cmp)).To(BeZero())
+		Expect(shouldRefresh(cmp, r.syncMode(cmp), r.syncInterval(cmp), r.now())).To(BeFalse())
+		Expect(r.getRequeueResult(cmp)).To(Equal(ctrl.Result{}))
+
+		By("still refreshing after a spec change")
+		cmp.Generation = 2
+		Expect(shouldRefresh(cmp, r.syncMode(cmp), r.syncInterval(cmp), r.now())).To(BeTrue())
+	})
+
+	It("spreads Periodic propagations sharing an interval by a stable jitter", func() {
+		r := &ConfigMapPropagationReconciler{Clock: clocktesting.NewFakeClock(lastSync), SyncJitter: 0.1}
+		intervals := map[time.Duration]struct{}{}
+		for i := range 10 {
+			cmp := syncedPropagation(syncv1alpha1.SyncModePeriodic, 1)
+			cmp.UID = types.UID(fmt.Sprintf("uid-%d", i))
+			jittered := r.periodicInterval(cmp)
+			Expect(jittered).To(BeNumerically("~", interval, interval/10))
+			Expect(r.periodicInterval(cmp)).To(Equal(jittered))
+			Expect(r.getRequeueResult(cmp)).To(Equal(ctrl.Result{RequeueAfter: jittered}))
+			intervals[jittered] = struct{}{}
+		}
+		Expect(len(intervals)).To(BeNumerically(">", 1))
+	})
+
+	It("writes the status of a no-op Periodic propagation only when its sync is due", func() {
+		ctx := context.Background()
+		cmp := newPropagation("no-op", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:       syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:      []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:     syncv1alpha1.SyncModePeriodic,
+			SyncInterval: &metav1.Duration{Duration: interval},
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		fakeClock := clocktesting.NewFakeClock(lastSync)
+		r.Clock = fakeClock
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		By("settling the summary on the first sync that finds the target up to date")
+		fakeClock.Step(interval + time.Minute)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		statusWrites := 0
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+				statusWrites++
+				return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+			},
+		})
+		By("reconciling on an event before the next sync is due")
+		fakeClock.Step(interval / 2)
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statusWrites).To(BeZero())
+		Expect(result.RequeueAfter).To(Equal(interval / 2))
+
+		By("syncing once the interval elapsed since the stored LastSyncedAt")
+		fakeClock.Step(interval/2 + time.Minute)
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statusWrites).To(Equal(1))
+		Expect(result.RequeueAfter).To(Equal(interval))
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.LastSyncedAt.Time).To(BeTemporally("==", fakeClock.Now()))
+	})
+})
+
+var _ = Describe("Controller options", func() {
+	It("honors MaxConcurrentReconciles and defaults to a single worker", func() {
+		r := &ConfigMapPropagationReconciler{MaxConcurrentReconciles: 8}
+		Expect(r.controllerOptions().MaxConcurrentReconciles).To(Equal(8))
+
+		r.MaxConcurrentReconciles = 0
+		Expect(r.controllerOptions().MaxConcurrentReconciles).To(Equal(1))
+	})
+})
+
+var _ = Describe("Reconcile", func() {
+	ctx := context.Background()
+
+	It("runs a full create, update and delete cycle", func() {
+		cmp := newPropagation("lifecycle", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:         syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:        []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}},
+			DeletionPolicy: syncv1alpha1.DeletionPolicyDelete,
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v1"})
+		r := newTestReconciler(cmp, src)
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		reconcile := func() *syncv1alpha1.ConfigMapPropagation {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			if err := r.Get(ctx, req.NamespacedName, got); apierrors.IsNotFound(err) {
+				return nil
+			}
+			return got
+		}
+		targetData := func(ns string) map[string]string {
+			target := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "app"}, target)).To(Succeed())
+			return target.Data
+		}
+
+		By("creating the targets")
+		got := reconcile()
+		Expect(got.Status.TargetsSummary).To(Equal(syncv1alpha1.TargetsSummary{Total: 2, Created: 2}))
+		Expect(targetData("team-a")).To(HaveKeyWithValue("k", "v1"))
+		Expect(targetData("team-b")).To(HaveKeyWithValue("k", "v1"))
+
+		By("updating them when the source changes")
+		src.Data["k"] = "v2"
+		Expect(r.Update(ctx, src)).To(Succeed())
+		got = reconcile()
+		Expect(got.Status.TargetsSummary).To(Equal(syncv1alpha1.TargetsSummary{Total: 2, Updated: 2}))
+		Expect(targetData("team-a")).To(HaveKeyWithValue("k", "v2"))
+		Expect(targetData("team-b")).To(HaveKeyWithValue("k", "v2"))
+
+		By("deleting a target removed from the spec")
+		got.Spec.Targets = got.Spec.Targets[:1]
+		got.Generation = 2
+		Expect(r.Update(ctx, got)).To(Succeed())
+		got = reconcile()
+		Expect(got.Status.TargetsSummary).To(Equal(syncv1alpha1.TargetsSummary{Total: 2, Unchanged: 1, Deleted: 1}))
+		err := r.Get(ctx, types.NamespacedName{Namespace: "team-b", Name: "app"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		By("deleting the remaining targets with the propagation")
+		Expect(r.Delete(ctx, got)).To(Succeed())
+		Expect(reconcile()).To(BeNil())
+		err = r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("re-adopts a target whose owner label was stripped out-of-band", func() {
+		cmp := newPropagation("adopt", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:         syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:        []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:       syncv1alpha1.SyncModeOnChange,
+			DeletionPolicy: syncv1alpha1.DeletionPolicyDelete,
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		stripped := newConfigMap("team-a", "app", map[string]string{"k": "v"})
+		stripped.Labels = map[string]string{ManagedByLabelKey: ManagedByLabelValue}
+		stripped.Annotations = map[string]string{OwnerUIDAnnotation: string(cmp.UID)}
+		r := newTestReconciler(cmp, src, stripped)
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+		Expect(err).NotTo(HaveOccurred())
+
+		got := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, got)).To(Succeed())
+		Expect(got.Labels).To(HaveKeyWithValue(OwnerLabelKey, cmp.Name))
+		Expect(r.Recorder.(*record.FakeRecorder).Events).To(Receive(ContainSubstring("OwnershipStripped")))
+	})
+
+	It("restores the managed-by label, owner UID annotation and copied labels removed from a synced target", func() {
+		cmp := newPropagation("restore-metadata", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:     syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:    []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:   syncv1alpha1.SyncModeOnChange,
+			CopyLabels: []string{"team"},
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		src.Labels = map[string]string{"team": "platform"}
+		r := newTestReconciler(cmp, src)
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		target := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, target)).To(Succeed())
+		Expect(isManagedConfigMap(target)).To(BeTrue())
+		delete(target.Labels, ManagedByLabelKey)
+		delete(target.Labels, "team")
+		delete(target.Annotations, OwnerUIDAnnotation)
+		Expect(r.Update(ctx, target)).To(Succeed())
+		Expect(isManagedConfigMap(target)).To(BeTrue())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, target)).To(Succeed())
+		Expect(target.Labels).To(HaveKeyWithValue(ManagedByLabelKey, ManagedByLabelValue))
+		Expect(target.Labels).To(HaveKeyWithValue(OwnerLabelKey, cmp.Name))
+		Expect(target.Labels).To(HaveKeyWithValue("team", "platform"))
+		Expect(target.Annotations).To(HaveKeyWithValue(OwnerUIDAnnotation, string(cmp.UID)))
+		Expect(target.Data).To(HaveKeyWithValue("k", "v"))
+	})
+
+	It("reclaims a target whose owner UID annotation was stripped on the update path", func() {
+		cmp := newPropagation("stripped-uid", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:   syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:  []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode: syncv1alpha1.SyncModeOnChange,
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v1"})
+		r := newTestReconciler(cmp, src)
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		target := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, target)).To(Succeed())
+		delete(target.Annotations, OwnerUIDAnnotation)
+		Expect(r.Update(ctx, target)).To(Succeed())
+		src.Data["k"] = "v2"
+		Expect(r.Update(ctx, src)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, target)).To(Succeed())
+		Expect(target.Annotations).To(HaveKeyWithValue(OwnerUIDAnnotation, string(cmp.UID)))
+		Expect(target.Data).To(HaveKeyWithValue("k", "v2"))
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.TargetsSummary.Updated).To(Equal(int32(1)))
+		Expect(got.Status.TargetsSummary.Created).To(BeZero())
+	})
+
+	It("ignores ConfigMaps carrying a forged owner label without the owner UID", func() {
+		cmp := newPropagation("forged", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:         syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:        []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-d"}},
+			DeletionPolicy: syncv1alpha1.DeletionPolicyDelete,
+		})
+		noUID := newConfigMap("team-b", "app", map[string]string{"k": "mine"})
+		noUID.Labels = map[string]string{OwnerLabelKey: cmp.Name, ManagedByLabelKey: ManagedByLabelValue}
+		wrongUID := newConfigMap("team-c", "app", map[string]string{"k": "mine"})
+		wrongUID.Labels = map[string]string{OwnerLabelKey: cmp.Name, ManagedByLabelKey: ManagedByLabelValue}
+		wrongUID.Annotations = map[string]string{OwnerUIDAnnotation: "uid-someone-else"}
+		desiredWrongUID := wrongUID.DeepCopy()
+		desiredWrongUID.Namespace = "team-d"
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}), noUID, wrongUID, desiredWrongUID)
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		current, err := r.getCurrentTargets(ctx, cmp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(current).To(ConsistOf(HaveField("Namespace", "team-a")))
+
+		By("not claiming a desired ConfigMap with the owner label and the UID of another propagation")
+		forged := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-d", Name: "app"}, forged)).To(Succeed())
+		Expect(forged.Data).To(HaveKeyWithValue("k", "mine"))
+		Expect(forged.Annotations).To(HaveKeyWithValue(OwnerUIDAnnotation, "uid-someone-else"))
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.TargetStatuses).To(ContainElement(And(HaveField("Namespace", "team-d"), HaveField("Reason", "OwnedByAnother"))))
+
+		By("not deleting them when the propagation is deleted")
+		Expect(r.Delete(ctx, got)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		err = r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		for _, ns := range []string{"team-b", "team-c", "team-d"} {
+			forged := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "app"}, forged)).To(Succeed())
+			Expect(forged.Data).To(HaveKeyWithValue("k", "mine"))
+		}
+	})
+
+	It("does not take over a target managed by another propagation", func() {
+		spec := syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			PropagationPolicy: syncv1alpha1.PropagationPolicyOverwrite,
+		}
+		first := newPropagation("first", spec)
+		second := newPropagation("second", spec)
+		r := newTestReconciler(first, second, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		recorder := record.NewFakeRecorder(100)
+		r.Recorder = recorder
+		targetKey := types.NamespacedName{Namespace: "team-a", Name: "app"}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: first.Name}})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: second.Name}})
+		Expect(err).NotTo(HaveOccurred())
+
+		target := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Labels).To(HaveKeyWithValue(OwnerLabelKey, first.Name))
+		Expect(target.Annotations).To(HaveKeyWithValue(OwnerUIDAnnotation, string(first.UID)))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, types.NamespacedName{Name: second.Name}, got)).To(Succeed())
+		Expect(got.Status.TargetStatuses).To(ConsistOf(And(
+			HaveField("Namespace", "team-a"),
+			HaveField("State", "Skipped"),
+			HaveField("Reason", "OwnedByAnother"),
+			HaveField("Message", ContainSubstring("ConfigMapPropagation first")),
+		)))
+		Expect(got.Status.TargetsSummary.Failed).To(BeZero())
+		Expect(got.Status.ManagedCount).To(BeZero())
+
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		Expect(events).To(ContainElement(And(ContainSubstring("OwnedByAnother"), ContainSubstring("first"))))
+
+		By("still reporting the conflict when the owner label was stripped")
+		delete(target.Labels, OwnerLabelKey)
+		Expect(r.Update(ctx, target)).To(Succeed())
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: second.Name}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Annotations).To(HaveKeyWithValue(OwnerUIDAnnotation, string(first.UID)))
+	})
+
+	It("clamps a tiny Periodic syncInterval to the minimum and warns", func() {
+		cmp := newPropagation("too-fast", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:       syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:      []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:     syncv1alpha1.SyncModePeriodic,
+			SyncInterval: &metav1.Duration{Duration: time.Second},
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		r := newTestReconciler(cmp, src)
+		r.MinSyncInterval = DefaultMinSyncInterval
+
+		Expect(r.syncInterval(cmp)).To(Equal(DefaultMinSyncInterval))
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+		Expect(err).NotTo(HaveOccurred())
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, types.NamespacedName{Name: cmp.Name}, got)).To(Succeed())
+		Expect(got.Status.EffectiveSyncInterval).NotTo(BeNil())
+		Expect(got.Status.EffectiveSyncInterval.Duration).To(Equal(DefaultMinSyncInterval))
+		Expect(r.Recorder.(*record.FakeRecorder).Events).To(Receive(ContainSubstring("SyncIntervalClamped")))
+	})
+
+	It("requeues a Periodic propagation for its next sync", func() {
+		cmp := newPropagation("periodic", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:       syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:      []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:     syncv1alpha1.SyncModePeriodic,
+			SyncInterval: &metav1.Duration{Duration: 10 * time.Minute},
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", 10*time.Minute))
+
+		By("requeueing again when the propagation is not due yet")
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", 10*time.Minute))
+	})
+
+	It("lists the namespaces and the managed targets once per reconcile", func() {
+		cmp := newPropagation("list-once", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "true"}},
+			Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-c", SyncMode: syncv1alpha1.SyncModeOnChange}},
+			TargetTTL:         &metav1.Duration{Duration: time.Hour},
+		})
+		teamA := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "true"}}}
+		teamB := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"team": "true"}}}
+		r := newTestReconciler(cmp, teamA, teamB, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		var namespaceLists, targetLists int
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				listOpts := &client.ListOptions{}
+				listOpts.ApplyOptions(opts)
+				switch list.(type) {
+				case *corev1.NamespaceList:
+					namespaceLists++
+				case *corev1.ConfigMapList:
+					if listOpts.LabelSelector != nil && listOpts.LabelSelector.String() == OwnerLabelKey+"="+cmp.Name {
+						targetLists++
+					}
+				}
+				return c.List(ctx, list, opts...)
+			},
+		})
+
+		for range 2 {
+			namespaceLists, targetLists = 0, 0
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceLists).To(Equal(1))
+			Expect(targetLists).To(Equal(1))
+		}
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.TargetsSummary.Total).To(Equal(int32(3)))
+	})
+
+	Describe("when the source ConfigMap is deleted", func() {
+		newManagedTarget := func(cmp *syncv1alpha1.ConfigMapPropagation) *corev1.ConfigMap {
+			target := newConfigMap("team-a", "app", map[string]string{"k": "v"})
+			target.Labels = map[string]string{OwnerLabelKey: cmp.Name, ManagedByLabelKey: ManagedByLabelValue}
+			target.Annotations = map[string]string{OwnerUIDAnnotation: string(cmp.UID)}
+			return target
+		}
+		newDeletedSource := func(name string, policy syncv1alpha1.OnSourceDelete) *syncv1alpha1.ConfigMapPropagation {
+			cmp := newPropagation(name, syncv1alpha1.ConfigMapPropagationSpec{
+				Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+				Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+				PropagationPolicy: syncv1alpha1.PropagationPolicyMerge,
+				OnSourceDelete:    policy,
+			})
+			cmp.Finalizers = []string{FinalizerName}
+			return cmp
+		}
+		readyCondition := func(r *ConfigMapPropagationReconciler, name string) *metav1.Condition {
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, types.NamespacedName{Name: name}, got)).To(Succeed())
+			return meta.FindStatusCondition(got.Status.Conditions, ConditionTypeReady)
+		}
+
+		It("reports the missing source in the Ready condition and recovers once it is back", func() {
+			cmp := newPropagation("source-not-found", syncv1alpha1.ConfigMapPropagationSpec{
+				Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+				Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			})
+			source := newConfigMap("default", "app", map[string]string{"k": "v"})
+			r := newTestReconciler(cmp, source)
+			now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+			r.Clock = clocktesting.NewFakeClock(now)
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(readyCondition(r, cmp.Name)).To(HaveField("Status", metav1.ConditionTrue))
+
+			By("deleting the source")
+			Expect(r.Delete(ctx, source)).To(Succeed())
+			r.Clock = clocktesting.NewFakeClock(now.Add(time.Minute))
+			result, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(5 * time.Minute))
+			Expect(r.Recorder.(*record.FakeRecorder).Events).To(Receive(ContainSubstring("SourceConfigMap Not Found")))
+			cond := readyCondition(r, cmp.Name)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal("SourceNotFound"))
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+			Expect(got.Status.LastSyncedAt.Time).To(BeTemporally("==", now.Add(time.Minute)))
+
+			By("recreating the source with the same data")
+			Expect(r.Create(ctx, newConfigMap("default", "app", map[string]string{"k": "v"}))).To(Succeed())
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			cond = readyCondition(r, cmp.Name)
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal("Synced"))
+		})
+
+		It("keeps the targets and reports the missing source under Retain", func() {
+			cmp := newDeletedSource("retain", syncv1alpha1.OnSourceDeleteRetain)
+			r := newTestReconciler(cmp, newManagedTarget(cmp))
+
+			result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(5 * time.Minute))
+			Expect(readyCondition(r, cmp.Name)).To(HaveField("Reason", "SourceNotFound"))
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})).To(Succeed())
+		})
+
+		It("deletes the managed targets under DeleteTargets even with Merge", func() {
+			cmp := newDeletedSource("delete-targets", syncv1alpha1.OnSourceDeleteDeleteTargets)
+			r := newTestReconciler(cmp, newManagedTarget(cmp))
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+			Expect(err).NotTo(HaveOccurred())
+			err = r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(readyCondition(r, cmp.Name)).To(HaveField("Reason", "SourceDeleted"))
+		})
+
+		It("keeps the targets and marks the propagation not Ready under MarkStale", func() {
+			cmp := newDeletedSource("mark-stale", syncv1alpha1.OnSourceDeleteMarkStale)
+			r := newTestReconciler(cmp, newManagedTarget(cmp))
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})).To(Succeed())
+			cond := readyCondition(r, cmp.Name)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal("SourceDeleted"))
+		})
+	})
+
+	Describe("with additionalSources", func() {
+		It("merges the sources in order so the later source wins", func() {
+			cmp := newPropagation("layered", syncv1alpha1.ConfigMapPropagationSpec{
+				Source:  syncv1alpha1.PropagationSource{Name: "common", Namespace: "default"},
+				Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a", Name: "app"}},
+				AdditionalSources: []syncv1alpha1.AdditionalSource{
+					{Name: "staging"},
+					{Name: "overrides", Namespace: "config"},
+				},
+			})
+			r := newTestReconciler(cmp,
+				newConfigMap("default", "common", map[string]string{"log": "info", "region": "eu", "timeout": "5s"}),
+				newConfigMap("default", "staging", map[string]string{"log": "debug", "region": "us"}),
+				newConfigMap("config", "overrides", map[string]string{"region": "ap"}),
+			)
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+			Expect(err).NotTo(HaveOccurred())
+
+			target := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, target)).To(Succeed())
+			Expect(target.Data).To(Equal(map[string]string{"log": "debug", "region": "ap", "timeout": "5s"}))
+		})
+
+		It("waits for a missing required source without applying OnSourceDelete", func() {
+			cmp := newPropagation("missing-layer", syncv1alpha1.ConfigMapPropagationSpec{
+				Source:            syncv1alpha1.PropagationSource{Name: "common", Namespace: "default"},
+				Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+				AdditionalSources: []syncv1alpha1.AdditionalSource{{Name: "overrides"}},
+				OnSourceDelete:    syncv1alpha1.OnSourceDeleteDeleteTargets,
+			})
+			overrides := newConfigMap("default", "overrides", map[string]string{"level": "debug"})
+			r := newTestReconciler(cmp, newConfigMap("default", "common", map[string]string{"k": "v"}), overrides)
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Delete(ctx, overrides)).To(Succeed())
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			target := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "common"}, target)).To(Succeed())
+			Expect(target.Data).To(Equal(map[string]string{"k": "v", "level": "debug"}))
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+			cond := meta.FindStatusCondition(got.Status.Conditions, ConditionTypeReady)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal("AdditionalSourceNotFound"))
+			Expect(cond.Message).To(ContainSubstring("default/overrides"))
+		})
+
+		It("reads the additional sources once per sync", func() {
+			targets := []syncv1alpha1.TargetRef{}
+			for _, ns := range []string{"team-a", "team-b", "team-c"} {
+				targets = append(targets, syncv1alpha1.TargetRef{Namespace: ns})
+			}
+			cmp := newPropagation("layered-reads", syncv1alpha1.ConfigMapPropagationSpec{
+				Source:            syncv1alpha1.PropagationSource{Name: "common", Namespace: "default"},
+				Targets:           targets,
+				AdditionalSources: []syncv1alpha1.AdditionalSource{{Name: "overrides"}},
+			})
+			r := newTestReconciler(cmp,
+				newConfigMap("default", "common", map[string]string{"k": "v"}),
+				newConfigMap("default", "overrides", map[string]string{"level": "debug"}))
+			overrideReads := 0
+			r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if key == (client.ObjectKey{Namespace: "default", Name: "overrides"}) {
+						overrideReads++
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			})
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(overrideReads).To(Equal(1))
+			target := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-c", Name: "common"}, target)).To(Succeed())
+			Expect(target.Data).To(HaveKeyWithValue("level", "debug"))
+		})
+
+		It("skips a missing optional source", func() {
+			cmp := newPropagation("optional-layer", syncv1alpha1.ConfigMapPropagationSpec{
+				Source:            syncv1alpha1.PropagationSource{Name: "common", Namespace: "default"},
+				Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+				AdditionalSources: []syncv1alpha1.AdditionalSource{{Name: "overrides", Optional: true}},
+			})
+			r := newTestReconciler(cmp, newConfigMap("default", "common", map[string]string{"k": "v"}))
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+			Expect(err).NotTo(HaveOccurred())
+			target := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "common"}, target)).To(Succeed())
+			Expect(target.Data).To(HaveKeyWithValue("k", "v"))
+		})
+
+		It("enqueues the propagation when an additional source changes", func() {
+			cmp := newPropagation("layered-watch", syncv1alpha1.ConfigMapPropagationSpec{
+				Source:            syncv1alpha1.PropagationSource{Name: "common", Namespace: "default"},
+				Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+				AdditionalSources: []syncv1alpha1.AdditionalSource{{Name: "overrides", Namespace: "config"}},
+			})
+			r := newTestReconciler(cmp)
+
+			Expect(r.mapSource(ctx, newConfigMap("config", "overrides", nil))).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}))
+			Expect(r.mapSource(ctx, newConfigMap("default", "overrides", nil))).To(BeEmpty())
+		})
+	})
+
+	It("does not touch other targets when the canary write fails", func() {
+		cmp := newPropagation("canary", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:          syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:         []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "canary"}, {Namespace: "team-b"}},
+			CanaryNamespace: "canary",
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		r := newTestReconciler(cmp, src)
+
+		writes := make([]string, 0)
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				writes = append(writes, obj.GetNamespace())
+				if obj.GetNamespace() == "canary" {
+					return errors.New("admission denied")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+
+		// The failed canary backs off like any other target, the propagation waits for its retry
+		result, err := r.SyncTargets(ctx, cmp, src)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(writes).To(Equal([]string{"canary"}))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, types.NamespacedName{Name: cmp.Name}, got)).To(Succeed())
+		cond := meta.FindStatusCondition(got.Status.Conditions, ConditionTypeReady)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal("CanaryFailed"))
+
+		// Until the backoff ends the canary is not written again and the other targets still wait
+		_, err = r.SyncTargets(ctx, got, src)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writes).To(Equal([]string{"canary"}))
+	})
+
+	It("does not sync the other targets while the canary namespace is terminating", func() {
+		cmp := newPropagation("canary-terminating", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:          syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:         []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "canary"}},
+			CanaryNamespace: "canary",
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		r := newTestReconciler(cmp, src, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "canary"},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+		})
+
+		_, err := r.SyncTargets(ctx, cmp, src)
+		Expect(err).To(MatchError(ContainSubstring("canary namespace canary is terminating")))
+		err = r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("processes targets in batches across reconciles", func() {
+		targets := []syncv1alpha1.TargetRef{}
+		for _, ns := range []string{"ns-1", "ns-2", "ns-3", "ns-4", "ns-5"} {
+			targets = append(targets, syncv1alpha1.TargetRef{Namespace: ns})
+		}
+		cmp := newPropagation("batched", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:    syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:   targets,
+			BatchSize: 2,
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		r := newTestReconciler(cmp, src)
+
+		countTargets := func() int {
+			list := &corev1.ConfigMapList{}
+			Expect(r.List(ctx, list, client.MatchingLabels{OwnerLabelKey: cmp.Name})).To(Succeed())
+			return len(list.Items)
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		for _, expected := range []int{2, 4} {
+			result, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(countTargets()).To(Equal(expected))
+		}
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(countTargets()).To(Equal(5))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.BatchCursor).To(BeEmpty())
+		Expect(got.Status.SyncedGeneration).To(Equal("1"))
+	})
+
+	It("does not report a batched sync as Synced when an earlier batch failed", func() {
+		cmp := newPropagation("batched-failure", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:    syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:   []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}},
+			BatchSize: 1,
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetNamespace() == "team-a" {
+					return errors.New("apiserver unavailable")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		// The failed target backs off, the next batch follows
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.BatchCursor).To(Equal("team-a/app"))
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.BatchCursor).To(BeEmpty())
+		Expect(got.Status.SyncedGeneration).To(BeEmpty())
+		Expect(got.Status.TargetsSummary.Failed).To(Equal(int32(1)))
+		Expect(got.Status.TargetsSummary.Created).To(Equal(int32(1)))
+		ready := meta.FindStatusCondition(got.Status.Conditions, ConditionTypeReady)
+		Expect(ready).NotTo(BeNil())
+		Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		Expect(ready.Message).To(ContainSubstring("team-a/app"))
+	})
+
+	It("writes a summary ConfigMap of the managed targets and removes it on deletion", func() {
+		cmp := newPropagation("summary", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:                syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:               []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b", Name: "app-copy"}},
+			DeletionPolicy:        syncv1alpha1.DeletionPolicyDelete,
+			WriteSummaryConfigMap: true,
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		r := newTestReconciler(cmp, src)
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		summary := &corev1.ConfigMap{}
+		summaryKey := types.NamespacedName{Namespace: "default", Name: "summary-propagation-summary"}
+		Expect(r.Get(ctx, summaryKey, summary)).To(Succeed())
+		Expect(summary.Data).To(Equal(map[string]string{
+			"team-a.app":      "Synced",
+			"team-b.app-copy": "Synced",
+		}))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(r.Delete(ctx, got)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apierrors.IsNotFound(r.Get(ctx, summaryKey, summary))).To(BeTrue())
+	})
+
+	It("does not propagate a source from a disallowed namespace", func() {
+		cmp := newPropagation("disallowed", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "tenant"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+		})
+		src := newConfigMap("tenant", "app", map[string]string{"k": "v"})
+		r := newTestReconciler(cmp, src)
+		r.AllowedSourceNamespaces = ParseNamespaceList("config, platform")
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, types.NamespacedName{Name: cmp.Name}, got)).To(Succeed())
+		cond := meta.FindStatusCondition(got.Status.Conditions, ConditionTypeReady)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal("DisallowedSource"))
+	})
+
+	It("reads a source without a namespace from the default namespace", func() {
+		cmp := newPropagation("no-source-namespace", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+		})
+		r := newTestReconciler(cmp, newConfigMap(syncv1alpha1.DefaultSourceNamespace, "app", map[string]string{"k": "v"}))
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		target := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, target)).To(Succeed())
+		Expect(target.Data).To(Equal(map[string]string{"k": "v"}))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(got.Status.Conditions, ConditionTypeReady)).To(BeTrue())
+		Expect(r.mapSource(ctx, newConfigMap(syncv1alpha1.DefaultSourceNamespace, "app", nil))).To(ConsistOf(req))
+	})
+
+	It("refreshes a target past its TTL even in CreatedOnce mode", func() {
+		cmp := newPropagation("ttl", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:          syncv1alpha1.SyncModeCreatedOnce,
+			PropagationPolicy: syncv1alpha1.PropagationPolicyOverwrite,
+			TargetTTL:         &metav1.Duration{Duration: time.Hour},
+		})
+		src := newConfigMap("default", "app", map[string]string{"seed": "1"})
+		r := newTestReconciler(cmp, src)
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		r.Clock = fakeClock
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		targetKey := types.NamespacedName{Namespace: "team-a", Name: "app"}
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Hour))
+
+		target := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Annotations).To(HaveKeyWithValue(ExpiresAtAnnotation, "2025-01-01T01:00:00Z"))
+
+		By("not refreshing before the TTL passes")
+		src.Data = map[string]string{"seed": "2"}
+		Expect(r.Update(ctx, src)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("seed", "1"))
+
+		By("refreshing once the TTL passed")
+		fakeClock.Step(time.Hour + time.Minute)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("seed", "2"))
+		Expect(target.Annotations).To(HaveKeyWithValue(ExpiresAtAnnotation, "2025-01-01T02:01:00Z"))
+	})
+
+	It("sweeps the expired orphans of a deleted propagation", func() {
+		cmp := newPropagation("ttl-orphans", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:               syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:              []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			DeletionPolicy:       syncv1alpha1.DeletionPolicyOrphan,
+			TargetTTL:            &metav1.Duration{Duration: time.Hour},
+			DeleteExpiredOrphans: true,
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		r.Clock = fakeClock
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		targetKey := types.NamespacedName{Namespace: "team-a", Name: "app"}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(r.Delete(ctx, got)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apierrors.IsNotFound(r.Get(ctx, req.NamespacedName, got))).To(BeTrue())
+
+		target := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Labels).To(HaveKeyWithValue(OrphanedLabelKey, "true"))
+
+		sweeper := &orphanSweeper{Client: r.Client, Recorder: r.Recorder, Clock: fakeClock}
+		Expect(sweeper.sweep(ctx)).To(Succeed())
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+
+		fakeClock.Step(time.Hour + time.Minute)
+		Expect(sweeper.sweep(ctx)).To(Succeed())
+		Expect(apierrors.IsNotFound(r.Get(ctx, targetKey, target))).To(BeTrue())
+	})
+
+	It("refreshes targets with their own SyncMode on their own schedule", func() {
+		cmp := newPropagation("per-target", syncv1alpha1.ConfigMapPropagationSpec{
+			Source: syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{
+				{Namespace: "team-a"},
+				{Namespace: "team-b", SyncMode: syncv1alpha1.SyncModePeriodic, SyncInterval: &metav1.Duration{Duration: time.Minute}},
+			},
+			SyncMode:          syncv1alpha1.SyncModeCreatedOnce,
+			PropagationPolicy: syncv1alpha1.PropagationPolicyOverwrite,
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v1"})
+		r := newTestReconciler(cmp, src)
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		r.Clock = fakeClock
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		valueIn := func(ns string) string {
+			target := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "app"}, target)).To(Succeed())
+			return target.Data["k"]
+		}
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		Expect(valueIn("team-a")).To(Equal("v1"))
+		Expect(valueIn("team-b")).To(Equal("v1"))
+
+		src.Data = map[string]string{"k": "v2"}
+		Expect(r.Update(ctx, src)).To(Succeed())
+
+		By("skipping both targets before the Periodic target is due")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(valueIn("team-a")).To(Equal("v1"))
+		Expect(valueIn("team-b")).To(Equal("v1"))
+
+		By("only refreshing the Periodic target once its interval passed")
+		fakeClock.Step(2 * time.Minute)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(valueIn("team-a")).To(Equal("v1"))
+		Expect(valueIn("team-b")).To(Equal("v2"))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.TargetSyncTimes).To(ConsistOf(And(
+			HaveField("Namespace", "team-b"),
+			HaveField("LastSyncedAt.Time", BeTemporally("==", fakeClock.Now())),
+		)))
+	})
+
+	It("cleans up the old copy when a target is renamed in the spec", func() {
+		cmp := newPropagation("rename", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:         syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:        []syncv1alpha1.TargetRef{{Namespace: "team-a", Name: "app-new"}},
+			SyncMode:       syncv1alpha1.SyncModeOnChange,
+			DeletionPolicy: syncv1alpha1.DeletionPolicyDelete,
+		})
+		// Status says the current generation is synced, only the duplicate forces a reconcile
+		cmp.Status.SyncedGeneration = "1"
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		oldCopy := newConfigMap("team-a", "app-old", map[string]string{"k": "v"})
+		oldCopy.Labels = map[string]string{OwnerLabelKey: cmp.Name, ManagedByLabelKey: ManagedByLabelValue}
+		oldCopy.Annotations = map[string]string{OwnerUIDAnnotation: string(cmp.UID)}
+		r := newTestReconciler(cmp, src, oldCopy)
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app-old"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app-new"}, &corev1.ConfigMap{})).To(Succeed())
+		Expect(r.Recorder.(*record.FakeRecorder).Events).To(Receive(ContainSubstring("DuplicateTarget")))
+	})
+
+	It("bounds how many propagations sharing a source sync concurrently", func() {
+		const limit = 2
+		src := newConfigMap("default", "shared", map[string]string{"k": "v"})
+		objs := []client.Object{src}
+		for i := range 5 {
+			objs = append(objs, newPropagation(fmt.Sprintf("shared-%d", i), syncv1alpha1.ConfigMapPropagationSpec{
+				Source:  syncv1alpha1.PropagationSource{Name: "shared", Namespace: "default"},
+				Targets: []syncv1alpha1.TargetRef{{Namespace: fmt.Sprintf("team-%d", i)}},
+			}))
+		}
+		r := newTestReconciler(objs...)
+		r.MaxConcurrentPerSource = limit
+
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				mu.Lock()
+				inFlight++
+				maxInFlight = max(maxInFlight, inFlight)
+				mu.Unlock()
+				time.Sleep(20 * time.Millisecond)
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+
+		var wg sync.WaitGroup
+		for i := range 5 {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				req := ctrl.Request{NamespacedName: types.NamespacedName{Name: fmt.Sprintf("shared-%d", i)}}
+				for {
+					result, err := r.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					if result.RequeueAfter != sourceBusyRequeueDelay {
+						return
+					}
+					time.Sleep(5 * time.Millisecond)
+				}
+			}()
+		}
+		wg.Wait()
+
+		Expect(maxInFlight).To(BeNumerically("<=", limit))
+		for i := range 5 {
+			key := types.NamespacedName{Namespace: fmt.Sprintf("team-%d", i), Name: "shared"}
+			Expect(r.Get(ctx, key, &corev1.ConfigMap{})).To(Succeed())
+		}
+	})
+
+	It("reports more matched namespaces than managed targets when some targets fail", func() {
+		cmp := newPropagation("counts", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "web"}},
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		objs := []client.Object{cmp, src}
+		for _, name := range []string{"web-1", "web-2", "web-3"} {
+			objs = append(objs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"team": "web"}}})
+		}
+		r := newTestReconciler(objs...)
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetNamespace() == "web-2" {
+					return errors.New("quota exceeded")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+
+		result, err := r.SyncTargets(ctx, cmp, src)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(defaultTargetBackoffBase))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, types.NamespacedName{Name: cmp.Name}, got)).To(Succeed())
+		Expect(got.Status.MatchedNamespaceCount).To(Equal(int32(3)))
+		Expect(got.Status.ManagedCount).To(Equal(int32(2)))
+	})
+
+	It("backs off a failing target while the healthy targets keep syncing", func() {
+		cmp := newPropagation("poison", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}, {Namespace: "locked"}},
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v1"})
+		r := newTestReconciler(cmp, src)
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+		r.Clock = fakeClock
+		attempts := 0
+		healthy := r.Client
+		r.Client = interceptor.NewClient(healthy.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetNamespace() == "locked" {
+					attempts++
+					return apierrors.NewForbidden(corev1.Resource("configmaps"), obj.GetName(), errors.New("RBAC denies writes"))
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		targetData := func(ns string) map[string]string {
+			target := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "app"}, target)).To(Succeed())
+			return target.Data
+		}
+		lockedStatus := func() syncv1alpha1.TargetStatus {
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+			for _, status := range got.Status.TargetStatuses {
+				if status.Namespace == "locked" {
+					return status
+				}
+			}
+			return syncv1alpha1.TargetStatus{}
+		}
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(defaultTargetBackoffBase))
+		Expect(attempts).To(Equal(1))
+		Expect(targetData("team-a")).To(Equal(map[string]string{"k": "v1"}))
+		Expect(targetData("team-b")).To(Equal(map[string]string{"k": "v1"}))
+
+		By("syncing a source change to the healthy targets without retrying the backing off one")
+		src.Data = map[string]string{"k": "v2"}
+		Expect(r.Update(ctx, src)).To(Succeed())
+		fakeClock.Step(time.Second)
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(defaultTargetBackoffBase - time.Second))
+		Expect(attempts).To(Equal(1))
+		Expect(targetData("team-a")).To(Equal(map[string]string{"k": "v2"}))
+		Expect(targetData("team-b")).To(Equal(map[string]string{"k": "v2"}))
+		Expect(lockedStatus()).To(And(HaveField("State", "Failed"), HaveField("Reason", "BackingOff")))
+
+		By("doubling the backoff after the retry fails again")
+		fakeClock.Step(defaultTargetBackoffBase)
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts).To(Equal(2))
+		Expect(result.RequeueAfter).To(Equal(2 * defaultTargetBackoffBase))
+
+		By("forgetting the failures once the target can be written")
+		r.Client = healthy
+		fakeClock.Step(result.RequeueAfter)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(targetData("locked")).To(Equal(map[string]string{"k": "v2"}))
+		_, _, backingOff := r.targetBackoff().wait(targetBackoffKey(cmp, &PropagatorTarget{Namespace: "locked", ConfigmapName: "app"}), r.now())
+		Expect(backingOff).To(BeFalse())
+	})
+
+	It("creates a new hash suffixed target on data change and removes the old one after the grace period", func() {
+		cmp := newPropagation("hashed", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:                  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:                 []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			DeletionPolicy:          syncv1alpha1.DeletionPolicyDelete,
+			HashSuffixTargetNames:   true,
+			HashedTargetGracePeriod: &metav1.Duration{Duration: 10 * time.Minute},
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v1"})
+		r := newTestReconciler(cmp, src)
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		r.Clock = fakeClock
+		currentName := func() string {
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, types.NamespacedName{Name: cmp.Name}, got)).To(Succeed())
+			return got.Status.CurrentTargetName
+		}
+		syncNow := func() {
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, types.NamespacedName{Name: cmp.Name}, got)).To(Succeed())
+			_, err := r.SyncTargets(ctx, got, src)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		syncNow()
+		firstName := currentName()
+		Expect(firstName).To(MatchRegexp(`^app-[0-9a-f]{10}$`))
+		first := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: firstName}, first)).To(Succeed())
+		Expect(first.Immutable).To(HaveValue(BeTrue()))
+
+		src.Data = map[string]string{"k": "v2"}
+		Expect(r.Update(ctx, src)).To(Succeed())
+		syncNow()
+		secondName := currentName()
+		Expect(secondName).NotTo(Equal(firstName))
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: secondName}, &corev1.ConfigMap{})).To(Succeed())
+
+		By("keeping the old copy during the grace period")
+		fakeClock.Step(5 * time.Minute)
+		syncNow()
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: firstName}, &corev1.ConfigMap{})).To(Succeed())
+
+		By("removing the old copy once the grace period passed")
+		fakeClock.Step(6 * time.Minute)
+		syncNow()
+		err := r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: firstName}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("excludes the source from the targets when the selector matches its namespace", func() {
+		cmp := newPropagation("loop", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"config": "shared"}},
+			PropagationPolicy: syncv1alpha1.PropagationPolicyOverwrite,
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		objs := []client.Object{cmp, src}
+		for _, name := range []string{"default", "team-a"} {
+			objs = append(objs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"config": "shared"}}})
+		}
+		r := newTestReconciler(objs...)
+
+		before := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "app"}, before)).To(Succeed())
+
+		for range 2 {
+			_, err := r.SyncTargets(ctx, cmp, src)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		after := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "app"}, after)).To(Succeed())
+		Expect(after.ResourceVersion).To(Equal(before.ResourceVersion))
+		Expect(after.Labels).NotTo(HaveKey(OwnerLabelKey))
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})).To(Succeed())
+		Expect(r.Recorder.(*record.FakeRecorder).Events).To(Receive(ContainSubstring("SourceInTargetSet")))
+	})
+
+	It("opens the circuit breaker after repeated source errors and probes after the backoff", func() {
+		cmp := newPropagation("flaky-source", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		r := newTestReconciler(cmp, src)
+		r.SourceFailureThreshold = 3
+		r.SourceBreakerBackoff = 10 * time.Minute
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		r.Clock = fakeClock
+
+		failing, sourceGets := true, 0
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if key.Namespace == "default" && key.Name == "app" {
+					sourceGets++
+					if failing {
+						return apierrors.NewInternalError(errors.New("etcd timeout"))
+					}
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		})
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		for range 2 {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).To(HaveOccurred())
+		}
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(10 * time.Minute))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(meta.FindStatusCondition(got.Status.Conditions, ConditionTypeReady)).To(HaveField("Reason", "SourceUnavailable"))
+
+		By("not touching the source while open")
+		fakeClock.Step(time.Minute)
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(9 * time.Minute))
+		Expect(sourceGets).To(Equal(3))
+
+		By("probing once the backoff passed and closing on success")
+		failing = false
+		fakeClock.Step(9 * time.Minute)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sourceGets).To(BeNumerically(">", 3))
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})).To(Succeed())
+	})
+
+	It("closes the circuit breaker when the spec changes or the propagation is deleted", func() {
+		cmp := newPropagation("fixed-source", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "missing", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		r.SourceFailureThreshold = 1
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		r.Clock = fakeClock
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if key.Namespace == "default" && key.Name == "missing" {
+					return apierrors.NewInternalError(errors.New("etcd timeout"))
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		})
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		allowed, _ := r.sourceBreaker().allow(cmp.Name, 1, r.now())
+		Expect(allowed).To(BeFalse())
+
+		By("syncing right away once the spec points at a working source")
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		got.Spec.Source.Name = "app"
+		got.Generation = 2
+		Expect(r.Update(ctx, got)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})).To(Succeed())
+
+		By("forgetting the breaker of a deleted propagation")
+		Expect(r.sourceBreaker().failure(cmp.Name, 2, r.now())).To(BeTrue())
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(r.HandleDelete(ctx, got)).To(Succeed())
+		allowed, _ = r.sourceBreaker().allow(cmp.Name, 2, r.now())
+		Expect(allowed).To(BeTrue())
+	})
+
+	It("records events against the target ConfigMap when targetEvents is set", func() {
+		cmp := newPropagation("target-events", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:       syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:      []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			TargetEvents: true,
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		r := newTestReconciler(cmp, src)
+		recorder := &objectRecorder{}
+		r.Recorder = recorder
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.events).To(ContainElement(SatisfyAll(
+			HaveField("Reason", "Propagated"),
+			HaveField("Object", SatisfyAll(
+				BeAssignableToTypeOf(&corev1.ConfigMap{}),
+				HaveField("Namespace", "team-a"),
+				HaveField("Name", "app"),
+			)),
+		)))
+	})
+
+	It("records targets rejected by an admission webhook as TargetPolicyDenied and backs off", func() {
+		cmp := newPropagation("policy-denied", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		r := newTestReconciler(cmp, src)
+		denial := `admission webhook "configmaps.policy.example.com" denied the request: key k is not allowed`
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*corev1.ConfigMap); ok && obj.GetNamespace() == "team-a" {
+					return &apierrors.StatusError{ErrStatus: metav1.Status{
+						Status:  metav1.StatusFailure,
+						Code:    403,
+						Reason:  metav1.StatusReasonForbidden,
+						Message: denial,
+					}}
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(policyDeniedRequeueDelay))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, types.NamespacedName{Name: cmp.Name}, got)).To(Succeed())
+		Expect(got.Status.TargetStatuses).To(ConsistOf(SatisfyAll(
+			HaveField("Namespace", "team-a"),
+			HaveField("State", "Failed"),
+			HaveField("Reason", "TargetPolicyDenied"),
+			HaveField("Message", denial),
+		)))
+	})
+
+	It("reports how many consuming Deployments run the current data", func() {
+		cmp := newPropagation("consumers", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}},
+			ConsumerReadiness: &syncv1alpha1.ConsumerReadiness{
+				DeploymentSelector: metav1.LabelSelector{MatchLabels: map[string]string{"uses": "app-config"}},
+			},
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		hash := dataHash(src.Data, nil)
+		consumer := func(ns, name, configHash string, available int32) *appsv1.Deployment {
+			d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: map[string]string{"uses": "app-config"}}}
+			d.Spec.Replicas = ptr.To(int32(2))
+			d.Spec.Template.Annotations = map[string]string{ConfigHashAnnotation: configHash}
+			d.Status.UpdatedReplicas = available
+			d.Status.AvailableReplicas = available
+			return d
+		}
+		unrelated := consumer("team-a", "other", "", 0)
+		unrelated.Labels = nil
+		r := newTestReconciler(cmp, src,
+			consumer("team-a", "web", hash, 2),
+			consumer("team-b", "rolling", hash, 1),
+			consumer("team-b", "stale", "0123456789", 2),
+			unrelated,
+		)
+		r.TrackConsumerReadiness = true
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+		Expect(err).NotTo(HaveOccurred())
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, types.NamespacedName{Name: cmp.Name}, got)).To(Succeed())
+		Expect(got.Status.ConsumerReadiness).To(Equal(&syncv1alpha1.ConsumerReadinessStatus{
+			ConfigHash: hash,
+			Ready:      1,
+			Total:      3,
+			NotReady:   []string{"team-b/rolling", "team-b/stale"},
+		}))
+
+		By("mapping consumer Deployment events to the propagation")
+		Expect(r.mapConsumerDeployment(ctx, consumer("team-b", "new", "", 0))).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}))
+	})
+
+	It("increments the revision only when the propagated data changes", func() {
+		cmp := newPropagation("revision", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:   syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:  []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode: syncv1alpha1.SyncModePeriodic,
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v1"})
+		r := newTestReconciler(cmp, src)
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		expectRevision := func(revision int64) {
+			GinkgoHelper()
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+			Expect(got.Status.Revision).To(Equal(revision))
+			target := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, target)).To(Succeed())
+			Expect(target.Annotations).To(HaveKeyWithValue(RevisionAnnotation, fmt.Sprint(revision)))
+		}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		expectRevision(1)
+
+		// Every sync below is due as the last one is pushed past the interval
+		syncNow := func() {
+			GinkgoHelper()
+			current := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, req.NamespacedName, current)).To(Succeed())
+			current.Status.LastSyncedAt = metav1.NewTime(time.Now().Add(-time.Hour))
+			source := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "app"}, source)).To(Succeed())
+			_, err := r.SyncTargets(ctx, current, source)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		syncNow()
+		syncNow()
+		expectRevision(1)
+
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "app"}, src)).To(Succeed())
+		src.Data["k"] = "v2"
+		Expect(r.Update(ctx, src)).To(Succeed())
+		syncNow()
+		expectRevision(2)
+		syncNow()
+		expectRevision(2)
+	})
+
+	It("skips creating targets in namespaces at their ConfigMap budget", func() {
+		cmp := newPropagation("budget", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "full"}, {Namespace: "roomy"}},
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		r := newTestReconciler(cmp, src,
+			newConfigMap("full", "one", nil),
+			newConfigMap("full", "two", nil),
+			newConfigMap("roomy", "one", nil),
+		)
+		r.MaxConfigMapsPerNamespace = 2
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(apierrors.IsNotFound(r.Get(ctx, types.NamespacedName{Namespace: "full", Name: "app"}, &corev1.ConfigMap{}))).To(BeTrue())
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "roomy", Name: "app"}, &corev1.ConfigMap{})).To(Succeed())
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, types.NamespacedName{Name: cmp.Name}, got)).To(Succeed())
+		Expect(got.Status.TargetStatuses).To(ConsistOf(SatisfyAll(
+			HaveField("Namespace", "full"),
+			HaveField("State", "Skipped"),
+			HaveField("Reason", "NamespaceConfigMapBudgetExceeded"),
+		)))
+	})
+
+	It("propagates source edits in OnChange mode without touching the propagation", func() {
+		cmp := newPropagation("source-edit", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:          syncv1alpha1.SyncModeOnChange,
+			PropagationPolicy: syncv1alpha1.PropagationPolicyOverwrite,
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v1"})
+		r := newTestReconciler(cmp, src)
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		targetKey := types.NamespacedName{Namespace: "team-a", Name: "app"}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		src.Data = map[string]string{"k": "v2"}
+		Expect(r.Update(ctx, src)).To(Succeed())
+		Expect(r.mapSource(ctx, src)).To(ConsistOf(req))
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		target := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("k", "v2"))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Generation).To(Equal(int64(1)))
+		Expect(got.Status.Revision).To(Equal(int64(2)))
+
+		By("not mapping ConfigMaps that are not a source")
+		Expect(r.mapSource(ctx, newConfigMap("default", "other", nil))).To(BeEmpty())
+		Expect(r.mapSource(ctx, newConfigMap("team-a", "app", nil))).To(BeEmpty())
+	})
+
+	It("restores and reports a target edited outside the controller in OnChange mode", func() {
+		cmp := newPropagation("drift", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:          syncv1alpha1.SyncModeOnChange,
+			PropagationPolicy: syncv1alpha1.PropagationPolicyOverwrite,
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		recorder := record.NewFakeRecorder(100)
+		r.Recorder = recorder
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		targetKey := types.NamespacedName{Namespace: "team-a", Name: "app"}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		target := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Annotations).To(HaveKey(AppliedHashAnnotation))
+
+		target.Data["k"] = "edited"
+		target.Data["extra"] = "x"
+		Expect(r.Update(ctx, target)).To(Succeed())
+		Expect(r.mapManagedConfigMap(ctx, target)).To(ConsistOf(req))
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Data).To(Equal(map[string]string{"k": "v"}))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.TargetStatuses).To(ContainElement(And(
+			HaveField("Namespace", "team-a"),
+			HaveField("State", "Synced"),
+			HaveField("Reason", "DriftDetected"),
+		)))
+		Expect(meta.IsStatusConditionTrue(got.Status.Conditions, ConditionTypeReady)).To(BeTrue())
+		Expect(got.Status.ManagedCount).To(Equal(int32(1)))
+		Expect(got.Status.TargetsSummary.Drifted).To(Equal(int32(1)))
+		Expect(meta.IsStatusConditionTrue(got.Status.Conditions, ConditionTypeDriftCorrected)).To(BeTrue())
+		Expect(target.Annotations).To(HaveKeyWithValue(DriftCountAnnotation, "1"))
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("DriftDetected"), ContainSubstring("team-a/app"), ContainSubstring("extra, k"))))
+
+		By("leaving the restored target alone afterwards")
+		restored := target.ResourceVersion
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.ResourceVersion).To(Equal(restored))
+	})
+
+	It("reports a target that keeps drifting on every sync", func() {
+		cmp := newPropagation("recurring-drift", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:          syncv1alpha1.SyncModeOnChange,
+			PropagationPolicy: syncv1alpha1.PropagationPolicyOverwrite,
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		recorder := record.NewFakeRecorder(100)
+		r.Recorder = recorder
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		targetKey := types.NamespacedName{Namespace: "team-a", Name: "app"}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		target := &corev1.ConfigMap{}
+		for i := 1; i <= recurringDriftThreshold; i++ {
+			Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+			target.Data["k"] = "edited"
+			Expect(r.Update(ctx, target)).To(Succeed())
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("k", "v"))
+		Expect(target.Annotations).To(HaveKeyWithValue(DriftCountAnnotation, strconv.Itoa(recurringDriftThreshold)))
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		Expect(events).To(ContainElement(ContainSubstring("RecurringDrift")))
+	})
+
+	It("starts the drift count over once the last drift left the window", func() {
+		cmp := newPropagation("sporadic-drift", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:          syncv1alpha1.SyncModeOnChange,
+			PropagationPolicy: syncv1alpha1.PropagationPolicyOverwrite,
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		recorder := record.NewFakeRecorder(100)
+		r.Recorder = recorder
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		r.Clock = fakeClock
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		targetKey := types.NamespacedName{Namespace: "team-a", Name: "app"}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		target := &corev1.ConfigMap{}
+		for i := 1; i <= recurringDriftThreshold; i++ {
+			fakeClock.Step(recurringDriftWindow + time.Minute)
+			Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+			target.Data["k"] = "edited"
+			Expect(r.Update(ctx, target)).To(Succeed())
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Annotations).To(HaveKeyWithValue(DriftCountAnnotation, "1"))
+		Expect(target.Annotations).To(HaveKeyWithValue(DriftedAtAnnotation, fakeClock.Now().UTC().Format(time.RFC3339)))
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		Expect(events).To(ContainElement(ContainSubstring("DriftDetected")))
+		Expect(events).NotTo(ContainElement(ContainSubstring("RecurringDrift")))
+	})
+
+	It("does not report target-local keys as drift under Merge", func() {
+		cmp := newPropagation("merge-local", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:          syncv1alpha1.SyncModeOnChange,
+			PropagationPolicy: syncv1alpha1.PropagationPolicyMerge,
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		targetKey := types.NamespacedName{Namespace: "team-a", Name: "app"}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		target := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		target.Data["local"] = "mine"
+		Expect(r.Update(ctx, target)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Data).To(Equal(map[string]string{"k": "v", "local": "mine"}))
+		Expect(target.Annotations[AppliedHashAnnotation]).To(Equal(dataHash(target.Data, target.BinaryData)))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.TargetStatuses).To(BeEmpty())
+	})
+
+	It("only advances ObservedGeneration after a successful sync", func() {
+		cmp := newPropagation("observed", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		r.Clock = fakeClock
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		observed := func() int64 {
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+			return got.Status.ObservedGeneration
+		}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(observed()).To(Equal(int64(1)))
+
+		By("keeping the old generation while the new spec fails to sync")
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		got.Spec.Targets = append(got.Spec.Targets, syncv1alpha1.TargetRef{Namespace: "team-b"})
+		got.Generation = 2
+		Expect(r.Update(ctx, got)).To(Succeed())
+		healthy := r.Client
+		r.Client = interceptor.NewClient(healthy.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetNamespace() == "team-b" {
+					return errors.New("boom")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(observed()).To(Equal(int64(1)))
+
+		By("catching up once the sync succeeds after the backoff")
+		r.Client = healthy
+		fakeClock.Step(result.RequeueAfter)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(observed()).To(Equal(int64(2)))
+	})
+
+	It("exports target operation metrics for each sync", func() {
+		cmp := newPropagation("metrics", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:         syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:        []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}},
+			DeletionPolicy: syncv1alpha1.DeletionPolicyDelete,
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		operations := func(operation string) float64 {
+			return testutil.ToFloat64(targetOperationsTotal.WithLabelValues("", cmp.Name, operation))
+		}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(operations("created")).To(Equal(2.0))
+		Expect(testutil.ToFloat64(managedTargetsGauge.WithLabelValues("", cmp.Name))).To(Equal(2.0))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		got.Spec.Targets = got.Spec.Targets[:1]
+		got.Generation = 2
+		Expect(r.Update(ctx, got)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(operations("created")).To(Equal(2.0))
+		Expect(operations("deleted")).To(Equal(1.0))
+		Expect(testutil.ToFloat64(managedTargetsGauge.WithLabelValues("", cmp.Name))).To(Equal(1.0))
+		Expect(testutil.CollectAndCount(syncDurationSeconds, "configmappropagation_sync_duration_seconds")).To(BeNumerically(">", 0))
+	})
+
+	It("retries the status patch when it conflicts with a concurrent update", func() {
+		cmp := newPropagation("status-conflict", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		patches := 0
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+				patches++
+				if patches == 1 {
+					// Someone else updated the propagation in the meantime
+					latest := &syncv1alpha1.ConfigMapPropagation{}
+					Expect(c.Get(ctx, req.NamespacedName, latest)).To(Succeed())
+					latest.Labels = map[string]string{"edited": "true"}
+					Expect(c.Update(ctx, latest)).To(Succeed())
+					return apierrors.NewConflict(syncv1alpha1.GroupVersion.WithResource("configmappropagations").GroupResource(), obj.GetName(), errors.New("the object has been modified"))
+				}
+				return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+			},
+		})
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patches).To(BeNumerically(">=", 2))
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Labels).To(HaveKeyWithValue("edited", "true"))
+		Expect(got.Status.ObservedGeneration).To(Equal(int64(1)))
+		Expect(meta.IsStatusConditionTrue(got.Status.Conditions, ConditionTypeReady)).To(BeTrue())
+	})
+
+	It("reports the planned changes without touching ConfigMaps in dry-run", func() {
+		cmp := newPropagation("dry-run", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:         syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:        []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}},
+			DeletionPolicy: syncv1alpha1.DeletionPolicyDelete,
+		})
+		cmp.Annotations = map[string]string{DryRunAnnotation: "true"}
+		stale := newConfigMap("team-c", "app", map[string]string{"k": "v"})
+		stale.Labels = map[string]string{OwnerLabelKey: cmp.Name, ManagedByLabelKey: ManagedByLabelValue}
+		stale.Annotations = map[string]string{OwnerUIDAnnotation: string(cmp.UID)}
+		r := newTestReconciler(cmp, stale, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, ns := range []string{"team-a", "team-b"} {
+			err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "app"}, &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-c", Name: "app"}, &corev1.ConfigMap{})).To(Succeed())
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.TargetsSummary).To(Equal(syncv1alpha1.TargetsSummary{Total: 3, Created: 2, Deleted: 1}))
+		Expect(got.Status.TargetStatuses).To(HaveLen(3))
+		Expect(got.Status.TargetStatuses).To(HaveEach(And(HaveField("State", "Skipped"), HaveField("Reason", "DryRun"))))
+		Expect(meta.FindStatusCondition(got.Status.Conditions, ConditionTypeReady)).To(HaveField("Reason", "DryRun"))
+
+		By("syncing once the annotation is removed")
+		got.Annotations = nil
+		Expect(r.Update(ctx, got)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})).To(Succeed())
+		err = r.Get(ctx, types.NamespacedName{Namespace: "team-c", Name: "app"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("counts unchanged targets so the summary adds up to the total", func() {
+		cmp := newPropagation("summary-counts", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}},
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("editing team-b and adding team-c")
+		edited := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-b", Name: "app"}, edited)).To(Succeed())
+		edited.Data["k"] = "edited"
+		Expect(r.Update(ctx, edited)).To(Succeed())
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		got.Spec.Targets = append(got.Spec.Targets, syncv1alpha1.TargetRef{Namespace: "team-c"})
+		got.Generation = 2
+		Expect(r.Update(ctx, got)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		summary := got.Status.TargetsSummary
+		Expect(summary.Unchanged).To(Equal(int32(1)))
+		Expect(summary.Updated).To(Equal(int32(1)))
+		Expect(summary.Created).To(Equal(int32(1)))
+		Expect(summary.Total).To(Equal(int32(3)))
+		Expect(summary.Unchanged + summary.Updated + summary.Created + summary.Deleted + summary.Orphaned + summary.Failed).To(Equal(summary.Total))
+	})
+
+	It("counts skipped targets so the summary adds up to the total", func() {
+		spec := syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:           []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			PropagationPolicy: syncv1alpha1.PropagationPolicyOverwrite,
+		}
+		first := newPropagation("first-owner", spec)
+		spec.Targets = []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}, {Namespace: "team-c"}, {Namespace: "team-d"}}
+		second := newPropagation("skipping", spec)
+		full := []client.Object{newConfigMap("team-c", "one", nil), newConfigMap("team-c", "two", nil)}
+		r := newTestReconciler(append(full, first, second, newConfigMap("default", "app", map[string]string{"k": "v"}))...)
+		r.MaxConfigMapsPerNamespace = 2
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: second.Name}}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: first.Name}})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("editing team-b so it drifts")
+		edited := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-b", Name: "app"}, edited)).To(Succeed())
+		edited.Data["k"] = "edited"
+		Expect(r.Update(ctx, edited)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		summary := got.Status.TargetsSummary
+		Expect(summary.Skipped).To(Equal(int32(2)))
+		Expect(summary.Updated).To(Equal(int32(1)))
+		Expect(summary.Drifted).To(Equal(int32(1)))
+		Expect(summary.Unchanged).To(Equal(int32(1)))
+		Expect(summary.Total).To(Equal(int32(4)))
+		Expect(summary.Created + summary.Updated + summary.Unchanged + summary.Deleted + summary.Orphaned + summary.Failed + summary.Skipped).To(Equal(summary.Total))
+	})
+
+	It("stamps the source hash on targets and skips updating them while it matches", func() {
+		cmp := newPropagation("source-hash", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}},
+		})
+		src := newConfigMap("default", "app", map[string]string{"k": "v"})
+		r := newTestReconciler(cmp, src)
+		updates := 0
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if _, ok := obj.(*corev1.ConfigMap); ok {
+					updates++
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		})
+		resync := func() {
+			latest := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, types.NamespacedName{Name: cmp.Name}, latest)).To(Succeed())
+			_, err := r.SyncTargets(ctx, latest, src)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		resync()
+		target := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, target)).To(Succeed())
+		Expect(target.Annotations).To(HaveKey(SourceHashAnnotation))
+
+		By("not updating the targets when the source is unchanged")
+		resync()
+		Expect(updates).To(BeZero())
+
+		By("updating them again once the source changes")
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "app"}, src)).To(Succeed())
+		src.Data["k"] = "v2"
+		Expect(r.Update(ctx, src)).To(Succeed())
+		updates = 0
+		resync()
+		Expect(updates).To(Equal(2))
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("k", "v2"))
+	})
+
+	It("syncs a Periodic propagation with a zero syncInterval only on changes", func() {
+		cmp := newPropagation("periodic-zero", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:       syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:      []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:     syncv1alpha1.SyncModePeriodic,
+			SyncInterval: &metav1.Duration{},
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		r.Clock = fakeClock
+		writes := 0
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*corev1.ConfigMap); ok {
+					writes++
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if _, ok := obj.(*corev1.ConfigMap); ok {
+					writes++
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		})
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(writes).To(Equal(1))
+
+		By("not resyncing or requeueing as time passes")
+		for range 3 {
+			fakeClock.Step(time.Hour)
+			result, err = r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+		}
+		Expect(writes).To(Equal(1))
+
+		By("syncing once the spec changes")
+		got := &syncv1alpha1.ConfigMapPropagation{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		got.Spec.Targets = append(got.Spec.Targets, syncv1alpha1.TargetRef{Namespace: "team-b"})
+		got.Generation = 2
+		Expect(r.Update(ctx, got)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-b", Name: "app"}, &corev1.ConfigMap{})).To(Succeed())
+		Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.ObservedGeneration).To(Equal(int64(2)))
+	})
+
+	Describe("when an Orphan propagation is deleted", func() {
+		It("releases the targets, records them in status and keeps the finalizer until all are orphaned", func() {
+			cmp := newPropagation("orphan-on-delete", syncv1alpha1.ConfigMapPropagationSpec{
+				Source:         syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+				Targets:        []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}},
+				DeletionPolicy: syncv1alpha1.DeletionPolicyOrphan,
+			})
+			cmp.Finalizers = []string{FinalizerName}
+			cmp.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			objs := []client.Object{cmp}
+			for _, ns := range []string{"team-a", "team-b"} {
+				target := newConfigMap(ns, "app", map[string]string{"k": "v"})
+				target.Labels = map[string]string{OwnerLabelKey: cmp.Name, ManagedByLabelKey: ManagedByLabelValue}
+				target.Annotations = map[string]string{OwnerUIDAnnotation: string(cmp.UID)}
+				objs = append(objs, target)
+			}
+			r := newTestReconciler(objs...)
+			failTeamB := true
+			r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					if failTeamB && obj.GetNamespace() == "team-b" {
+						return apierrors.NewInternalError(errors.New("boom"))
+					}
+					return c.Update(ctx, obj, opts...)
+				},
+			})
+			events := r.Recorder.(*record.FakeRecorder).Events
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+			By("requeueing while a target can't be orphaned")
+			result, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(events).To(Receive(And(ContainSubstring("OrphanedTargets"), ContainSubstring("team-a/app"))))
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+			Expect(got.Finalizers).To(ContainElement(FinalizerName))
+			Expect(got.Status.TargetsSummary.Orphaned).To(Equal(int32(1)))
+
+			By("removing the finalizer once every target is orphaned")
+			failTeamB = false
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(Receive(ContainSubstring("Delete Failed")))
+			Expect(events).To(Receive(And(ContainSubstring("OrphanedTargets"), ContainSubstring("team-b/app"))))
+			err = r.Get(ctx, req.NamespacedName, &syncv1alpha1.ConfigMapPropagation{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			for _, ns := range []string{"team-a", "team-b"} {
+				target := &corev1.ConfigMap{}
+				Expect(r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "app"}, target)).To(Succeed())
+				Expect(target.Data).To(HaveKeyWithValue("k", "v"))
+				Expect(target.Labels).NotTo(HaveKey(OwnerLabelKey))
+			}
+		})
+	})
+
+	Describe("with a target namespace being deleted", func() {
+		It("skips targets in a terminating namespace without failing the sync", func() {
+			cmp := newPropagation("terminating", syncv1alpha1.ConfigMapPropagationSpec{
+				Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+				Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}, {Namespace: "team-c"}},
+			})
+			src := newConfigMap("default", "app", map[string]string{"k": "new"})
+			leaving := newConfigMap("team-b", "app", map[string]string{"k": "old"})
+			leaving.Labels = map[string]string{OwnerLabelKey: cmp.Name, ManagedByLabelKey: ManagedByLabelValue}
+			terminating := func(name string) *corev1.Namespace {
+				return &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: name},
+					Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+				}
+			}
+			r := newTestReconciler(cmp, src, leaving, terminating("team-b"), terminating("team-c"))
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, &corev1.ConfigMap{})).To(Succeed())
+			err = r.Get(ctx, types.NamespacedName{Namespace: "team-c", Name: "app"}, &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			untouched := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-b", Name: "app"}, untouched)).To(Succeed())
+			Expect(untouched.Data).To(HaveKeyWithValue("k", "old"))
+
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+			Expect(got.Status.TargetStatuses).To(ConsistOf(
+				And(HaveField("Namespace", "team-b"), HaveField("State", "Skipped"), HaveField("Reason", "NamespaceTerminating")),
+				And(HaveField("Namespace", "team-c"), HaveField("State", "Skipped"), HaveField("Reason", "NamespaceTerminating")),
+			))
+			Expect(got.Status.TargetsSummary.Failed).To(BeZero())
+			Expect(got.Status.ManagedCount).To(Equal(int32(1)))
+			Expect(meta.IsStatusConditionTrue(got.Status.Conditions, ConditionTypeReady)).To(BeTrue())
+		})
+
+		It("skips a target whose namespace is gone by the time it is created", func() {
+			cmp := newPropagation("gone", syncv1alpha1.ConfigMapPropagationSpec{
+				Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+				Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			})
+			r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+			r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if obj.GetNamespace() == "team-a" {
+						return apierrors.NewNotFound(corev1.Resource("namespaces"), "team-a")
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			})
+
+			_, err := r.SyncTargets(ctx, cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+			Expect(err).NotTo(HaveOccurred())
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, types.NamespacedName{Name: cmp.Name}, got)).To(Succeed())
+			Expect(got.Status.TargetStatuses).To(ConsistOf(HaveField("Reason", "NamespaceNotFound")))
+			Expect(got.Status.TargetsSummary.Failed).To(BeZero())
+		})
+	})
+
+	Describe("with an immutable target", func() {
+		newImmutableTarget := func(cmp *syncv1alpha1.ConfigMapPropagation, ns string) *corev1.ConfigMap {
+			target := newConfigMap(ns, "app", map[string]string{"k": "old"})
+			target.Labels = map[string]string{OwnerLabelKey: cmp.Name, ManagedByLabelKey: ManagedByLabelValue}
+			target.Annotations = map[string]string{OwnerUIDAnnotation: string(cmp.UID)}
+			target.Immutable = ptr.To(true)
+			return target
+		}
+
+		It("skips the target and still syncs the others", func() {
+			cmp := newPropagation("immutable", syncv1alpha1.ConfigMapPropagationSpec{
+				Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+				Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "team-b"}},
+			})
+			src := newConfigMap("default", "app", map[string]string{"k": "new"})
+			r := newTestReconciler(cmp, src, newImmutableTarget(cmp, "team-a"))
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			frozen := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, frozen)).To(Succeed())
+			Expect(frozen.Data).To(HaveKeyWithValue("k", "old"))
+			synced := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-b", Name: "app"}, synced)).To(Succeed())
+			Expect(synced.Data).To(HaveKeyWithValue("k", "new"))
+
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+			Expect(got.Status.TargetStatuses).To(ContainElement(And(
+				HaveField("Namespace", "team-a"),
+				HaveField("State", "Skipped"),
+				HaveField("Reason", "Immutable"),
+			)))
+			Expect(got.Status.TargetsSummary.Failed).To(BeZero())
+			Expect(meta.IsStatusConditionTrue(got.Status.Conditions, ConditionTypeReady)).To(BeTrue())
+			Expect(r.Recorder.(*record.FakeRecorder).Events).To(Receive(ContainSubstring("TargetImmutable")))
+		})
+
+		It("recreates the target with recreateImmutableTargets", func() {
+			cmp := newPropagation("recreate-immutable", syncv1alpha1.ConfigMapPropagationSpec{
+				Source:                   syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+				Targets:                  []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+				RecreateImmutableTargets: true,
+			})
+			src := newConfigMap("default", "app", map[string]string{"k": "new"})
+			r := newTestReconciler(cmp, src, newImmutableTarget(cmp, "team-a"))
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+			Expect(err).NotTo(HaveOccurred())
+
+			target := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app"}, target)).To(Succeed())
+			Expect(target.Data).To(HaveKeyWithValue("k", "new"))
+			Expect(target.Immutable).To(BeNil())
+		})
+	})
+
+	It("flips the Ready condition to False on a failed sync and back to True on recovery", func() {
+		cmp := newPropagation("ready", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+		})
+		cmp.Status.Conditions = []metav1.Condition{{
+			Type:               "UnReady",
+			Status:             metav1.ConditionFalse,
+			Reason:             "SyncFailed",
+			LastTransitionTime: metav1.Now(),
+		}}
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		r.Clock = fakeClock
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+		conditions := func() []metav1.Condition {
+			got := &syncv1alpha1.ConfigMapPropagation{}
+			Expect(r.Get(ctx, req.NamespacedName, got)).To(Succeed())
+			return got.Status.Conditions
+		}
+
+		healthy := r.Client
+		r.Client = interceptor.NewClient(healthy.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetNamespace() == "team-a" {
+					return errors.New("boom")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(conditions()).To(ConsistOf(And(
+			HaveField("Type", ConditionTypeReady),
+			HaveField("Status", metav1.ConditionFalse),
+			HaveField("Reason", "SyncFailed"),
+		)))
+
+		r.Client = healthy
+		fakeClock.Step(result.RequeueAfter)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conditions()).To(ConsistOf(And(
+			HaveField("Type", ConditionTypeReady),
+			HaveField("Status", metav1.ConditionTrue),
+			HaveField("Reason", "Synced"),
+		)))
+	})
+})
+
+var _ = Describe("Namespace watch", func() {
+	ctx := context.Background()
+
+	It("coalesces a burst of namespace events into one reconcile per propagation", func() {
+		selected := newPropagation("selected", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ci"}},
+		})
+		unrelated := newPropagation("unrelated", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+		})
+		r := newTestReconciler(selected, unrelated)
+		r.NamespaceCoalesceDelay = 50 * time.Millisecond
+
+		q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+		defer q.ShutDown()
+		h := r.namespaceEventHandler()
+		for i := range 50 {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("ci-%d", i),
+				Labels: map[string]string{"team": "ci"},
+			}}
+			h.Create(ctx, event.CreateEvent{Object: ns}, q)
+			h.Delete(ctx, event.DeleteEvent{Object: ns}, q)
+		}
+
+		Eventually(q.Len).Should(Equal(1))
+		Consistently(q.Len, 200*time.Millisecond).Should(Equal(1))
+		req, _ := q.Get()
+		Expect(req.Name).To(Equal("selected"))
+	})
+
+	It("creates the target in a matching namespace created after the propagation synced", func() {
+		cmp := newPropagation("late-namespace", syncv1alpha1.ConfigMapPropagationSpec{
+			Source:            syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "backend"}},
+			SyncMode:          syncv1alpha1.SyncModeCreatedOnce,
+		})
+		r := newTestReconciler(cmp, newConfigMap("default", "app", map[string]string{"k": "v"}))
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments", Labels: map[string]string{"team": "backend"}}}
+		Expect(r.Create(ctx, ns)).To(Succeed())
+		Expect(r.mapNamespace(ctx, ns)).To(ConsistOf(req))
+		Expect(r.mapNamespace(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web"}})).To(BeEmpty())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		target := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "payments", Name: "app"}, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("k", "v"))
+	})
+})
+
+// objectRecorder keeps the involved object of every recorded event.
+type objectRecorder struct {
+	events []recordedEvent
+}
+
+type recordedEvent struct {
+	Object client.Object
+	Reason string
+}
+
+func (o *objectRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if obj, ok := object.(client.Object); ok {
+		o.events = append(o.events, recordedEvent{Object: obj, Reason: reason})
+	}
+}
+
+func (o *objectRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	o.Event(object, eventtype, reason, "")
+}
+
+func (o *objectRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	o.Event(object, eventtype, reason, "")
+}
@@ -6,6 +6,9 @@ import (
 	"strings"
 
 	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
@@ -20,6 +23,7 @@ func (r *ConfigMapPropagationReconciler) HandleDelete(ctx context.Context, confi
 	}
 
 	failedTargets := make([]*PropagatorTarget, 0)
+	orphaned := make([]string, 0)
 
 	for _, target := range targets {
 		var err error
@@ -32,6 +36,16 @@ func (r *ConfigMapPropagationReconciler) HandleDelete(ctx context.Context, confi
 
 		if err != nil {
 			failedTargets = append(failedTargets, target)
+		} else if configmapPropagator.Spec.DeletionPolicy == "Orphan" {
+			orphaned = append(orphaned, fmt.Sprintf("%s/%s", target.Namespace, target.ConfigmapName))
+		}
+	}
+
+	// Released targets no longer carry the owner labels, so a retry after a partial failure can't
+	// find them again: record them now rather than only once the finalizer is removed
+	if len(orphaned) > 0 {
+		if err := r.recordOrphanedTargets(ctx, configmapPropagator, orphaned); err != nil {
+			return err
 		}
 	}
 
@@ -40,14 +54,31 @@ func (r *ConfigMapPropagationReconciler) HandleDelete(ctx context.Context, confi
 		for _, t := range failedTargets {
 			parts = append(parts, fmt.Sprintf("%s/%s", t.Namespace, t.ConfigmapName))
 		}
-		errMsg := fmt.Errorf("%v: %s", ErrDeletingTargets, strings.Join(parts, ","))
+		errMsg := fmt.Errorf("%w: %s", ErrDeletingTargets, strings.Join(parts, ","))
 		return errMsg
 	}
 
-	controllerutil.RemoveFinalizer(configmapPropagator, FinalizerName)
-	if err := r.Update(ctx, configmapPropagator); err != nil {
+	if err := r.deleteSummaryConfigMap(ctx, configmapPropagator); err != nil {
 		return err
 	}
+	forgetPropagationMetrics(configmapPropagator)
+	r.targetBackoff().forget(configmapPropagator)
+	r.sourceBreaker().forget(configmapPropagator.Name)
+
+	return ownership.RemoveFinalizer(ctx, r.Client, configmapPropagator)
+}
+
+// recordOrphanedTargets emits a summary event listing the targets released by an Orphan deletion and
+// adds them to TargetsSummary.Orphaned.
+func (r *ConfigMapPropagationReconciler) recordOrphanedTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, orphaned []string) error {
+	r.Recorder.Eventf(configmapPropagator, corev1.EventTypeNormal, "OrphanedTargets",
+		"orphaned %d target(s), left in place without owner labels: %s", len(orphaned), strings.Join(orphaned, ","))
 
-	return nil
+	updateCmp := configmapPropagator.DeepCopy()
+	updateCmp.Status.TargetsSummary.Orphaned += int32(len(orphaned))
+	if err := r.patchStatus(ctx, configmapPropagator, updateCmp); err != nil {
+		return err
+	}
+	// Pick up the resourceVersion of the patch so removing the finalizer doesn't conflict with it
+	return r.Get(ctx, client.ObjectKeyFromObject(configmapPropagator), configmapPropagator)
 }
@@ -2,16 +2,26 @@ package controller
 
 import (
 	"context"
+	"slices"
 
 	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// getDesiredTargets computes the desired targets from spec.targets and spec.namespaceSelector.
-// It returns a deduplicated slice of PropagatorTarget.
+// getDesiredTargets computes the desired targets from spec.targets, spec.namespaceSelector and
+// spec.namespaceNamePattern, minus the selected namespaces matching spec.namespaceExcludeSelector and
+// every namespace listed in spec.excludeNamespaces. It returns a deduplicated slice of PropagatorTarget.
 func (r *ConfigMapPropagationReconciler) getDesiredTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) ([]*PropagatorTarget, error) {
+	targets, _, err := r.resolveTargets(ctx, configmapPropagator)
+	return targets, err
+}
+
+// resolveTargets computes the desired targets and reports whether the source ConfigMap itself was
+// resolved as a target. The source is always left out, writing to it would feed the output back in.
+func (r *ConfigMapPropagationReconciler) resolveTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) ([]*PropagatorTarget, bool, error) {
 	targets := make([]*PropagatorTarget, 0)
 	sourceName := configmapPropagator.Spec.Source.Name
 	allowSystem := true
@@ -38,37 +48,120 @@ func (r *ConfigMapPropagationReconciler) getDesiredTargets(ctx context.Context,
 		targets = append(targets, &PropagatorTarget{
 			ConfigmapName: name,
 			Namespace:     ns,
+			SyncMode:      t.SyncMode,
+			SyncInterval:  t.SyncInterval,
 		})
 	}
 
 	nsSel := configmapPropagator.Spec.NamespaceSelector
+	namePattern, err := compileNamespaceNamePattern(configmapPropagator.Spec.NamespaceNamePattern)
+	if err != nil {
+		return nil, false, err
+	}
 
-	if nsSel != nil {
-		sel, err := metav1.LabelSelectorAsSelector(nsSel)
+	if nsSel != nil || namePattern != nil {
+		sel, err := namespaceSelector(nsSel)
 		if err != nil {
-			return nil, err
+			return nil, false, err
+		}
+		// A name pattern can match any namespace, the selector can then only be applied after listing
+		var listOpts []client.ListOption
+		if namePattern == nil {
+			listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: sel})
+		}
+		excludeSel := labels.Nothing()
+		if configmapPropagator.Spec.NamespaceExcludeSelector != nil {
+			excludeSel, err = metav1.LabelSelectorAsSelector(configmapPropagator.Spec.NamespaceExcludeSelector)
+			if err != nil {
+				return nil, false, err
+			}
 		}
 
+		// A restarted list visits the namespaces seen before again, seen already skips them
 		var nsList corev1.NamespaceList
-		if err := r.List(ctx, &nsList, client.MatchingLabelsSelector{Selector: sel}); err != nil {
-			return nil, err
+		if err := r.listInPages(ctx, &nsList, nil, func() error {
+			for _, ns := range nsList.Items {
+				if _, isSys := defaultSystemNamespaces[ns.Name]; !allowSystem && isSys {
+					continue
+				}
+				if !sel.Matches(labels.Set(ns.Labels)) && !namePattern.Matches(ns.Name) {
+					continue
+				}
+				if excludeSel.Matches(labels.Set(ns.Labels)) {
+					continue
+				}
+				key := ns.Name + "/" + sourceName
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				targets = append(targets, &PropagatorTarget{
+					ConfigmapName: sourceName,
+					Namespace:     ns.Name,
+				})
+			}
+			return nil
+		}, listOpts...); err != nil {
+			return nil, false, err
 		}
+	}
 
-		for _, ns := range nsList.Items {
-			if _, isSys := defaultSystemNamespaces[ns.Name]; !allowSystem && isSys {
-				continue
-			}
-			key := ns.Name + "/" + sourceName
-			if _, ok := seen[key]; ok {
-				continue
-			}
-			seen[key] = struct{}{}
-			targets = append(targets, &PropagatorTarget{
-				ConfigmapName: sourceName,
-				Namespace:     ns.Name,
-			})
+	targets = withoutExcludedNamespaces(configmapPropagator, targets)
+
+	if configmapPropagator.Spec.HashSuffixTargetNames {
+		suffix, err := r.sourceHashSuffix(ctx, configmapPropagator)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, t := range targets {
+			t.HashOf = t.ConfigmapName
+			t.ConfigmapName = t.ConfigmapName + "-" + suffix
 		}
 	}
 
-	return targets, nil
+	srcNS := configmapPropagator.Spec.Source.NamespaceOrDefault()
+	sourceExcluded := false
+	withoutSource := make([]*PropagatorTarget, 0, len(targets))
+	for _, t := range targets {
+		if t.Namespace == srcNS && t.ConfigmapName == sourceName {
+			sourceExcluded = true
+			continue
+		}
+		withoutSource = append(withoutSource, t)
+	}
+
+	return withoutSource, sourceExcluded, nil
+}
+
+// withoutExcludedNamespaces drops the targets in a namespace listed in ExcludeNamespaces.
+func withoutExcludedNamespaces(configmapPropagator *syncv1alpha1.ConfigMapPropagation, targets []*PropagatorTarget) []*PropagatorTarget {
+	if len(configmapPropagator.Spec.ExcludeNamespaces) == 0 {
+		return targets
+	}
+	kept := make([]*PropagatorTarget, 0, len(targets))
+	for _, t := range targets {
+		if namespaceExcluded(configmapPropagator, t.Namespace) {
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept
+}
+
+// namespaceExcluded reports whether ns is listed in ExcludeNamespaces.
+func namespaceExcluded(configmapPropagator *syncv1alpha1.ConfigMapPropagation, ns string) bool {
+	return slices.Contains(configmapPropagator.Spec.ExcludeNamespaces, ns)
+}
+
+// namespaceSelector converts a NamespaceSelector to a labels.Selector. A nil selector selects no
+// namespace while an empty one selects every namespace, the two cases are handled here explicitly
+// instead of relying on metav1.LabelSelectorAsSelector to tell them apart.
+func namespaceSelector(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if selector == nil {
+		return labels.Nothing(), nil
+	}
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
 }
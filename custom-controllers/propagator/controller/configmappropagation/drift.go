@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setAppliedHashAnnotation records the hash of the data written to a target and reports whether the
+// annotations changed.
+func setAppliedHashAnnotation(annotations map[string]string, data map[string]string, binaryData map[string][]byte) bool {
+	hash := dataHash(data, binaryData)
+	if annotations[AppliedHashAnnotation] == hash {
+		return false
+	}
+	annotations[AppliedHashAnnotation] = hash
+	return true
+}
+
+// editedOutOfBand reports whether the target data no longer matches the hash recorded on the last write.
+// Targets written before the annotation existed are not considered edited.
+func editedOutOfBand(target *corev1.ConfigMap) bool {
+	applied, ok := target.Annotations[AppliedHashAnnotation]
+	return ok && applied != dataHash(target.Data, target.BinaryData)
+}
+
+// editedTargets returns the managed targets, keyed by namespace/name, whose data was changed since the
+// controller last wrote it or whose managed-by label was removed or changed. Only the targets are read,
+// the source is left alone until one was edited. Only the labeled ConfigMaps trusted as current targets
+// are considered.
+func editedTargets(configmapPropagator *syncv1alpha1.ConfigMapPropagation, targets *propagationTargets) map[string]struct{} {
+	current := make(map[string]struct{}, len(targets.current))
+	for _, t := range targets.current {
+		current[t.Namespace+"/"+t.ConfigmapName] = struct{}{}
+	}
+	edited := make(map[string]struct{})
+	for i := range targets.labeled {
+		key := targets.labeled[i].Namespace + "/" + targets.labeled[i].Name
+		if _, ok := current[key]; !ok {
+			continue
+		}
+		if editedOutOfBand(&targets.labeled[i]) || ownershipStripped(&targets.labeled[i], configmapPropagator) {
+			edited[key] = struct{}{}
+		}
+	}
+	return edited
+}
+
+// ownershipStripped reports whether a managed target lost its managed-by label or owner UID annotation,
+// or had them changed.
+func ownershipStripped(target *corev1.ConfigMap, configmapPropagator *syncv1alpha1.ConfigMapPropagation) bool {
+	return target.Labels[ManagedByLabelKey] != ManagedByLabelValue || !ownership.IsOwnedBy(target, configmapPropagator)
+}
+
+// driftCorrectable reports whether a target's drift is corrected, CreatedOnce targets are never updated.
+func (r *ConfigMapPropagationReconciler) driftCorrectable(configmapPropagator *syncv1alpha1.ConfigMapPropagation, t *PropagatorTarget) bool {
+	mode, _ := r.targetSyncSettings(configmapPropagator, t)
+	return mode != syncv1alpha1.SyncModeCreatedOnce
+}
+
+const (
+	// recurringDriftThreshold is the drift count from which a target is reported as drifting on every sync
+	recurringDriftThreshold = 3
+	// recurringDriftWindow is how long after a drift the next one still counts towards recurringDriftThreshold
+	recurringDriftWindow = 24 * time.Hour
+)
+
+// driftedKeys returns the sorted keys whose value on the target differs from the desired data,
+// including keys that were added to or removed from the target.
+func driftedKeys(target *corev1.ConfigMap, desiredData map[string]string, desiredBinaryData map[string][]byte) []string {
+	keys := map[string]struct{}{}
+	for k, v := range target.Data {
+		if want, ok := desiredData[k]; !ok || want != v {
+			keys[k] = struct{}{}
+		}
+	}
+	for k := range desiredData {
+		if _, ok := target.Data[k]; !ok {
+			keys[k] = struct{}{}
+		}
+	}
+	for k, v := range target.BinaryData {
+		if want, ok := desiredBinaryData[k]; !ok || string(want) != string(v) {
+			keys[k] = struct{}{}
+		}
+	}
+	for k := range desiredBinaryData {
+		if _, ok := target.BinaryData[k]; !ok {
+			keys[k] = struct{}{}
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// bumpDriftCount increments the drift counter annotation of a target and returns the new count.
+// The counter starts over at one when it is missing or malformed, or when the previous drift recorded
+// in DriftedAtAnnotation is older than recurringDriftWindow, so only drifts close together recur.
+func bumpDriftCount(annotations map[string]string, now time.Time) int {
+	count, err := strconv.Atoi(annotations[DriftCountAnnotation])
+	if err != nil || count < 0 {
+		count = 0
+	}
+	if last, err := time.Parse(time.RFC3339, annotations[DriftedAtAnnotation]); err != nil || now.Sub(last) > recurringDriftWindow {
+		count = 0
+	}
+	count++
+	annotations[DriftCountAnnotation] = strconv.Itoa(count)
+	annotations[DriftedAtAnnotation] = now.UTC().Format(time.RFC3339)
+	return count
+}
+
+// driftStatus reports a target whose data was edited outside the controller and restored from the source.
+// The target is in sync again, the DriftDetected reason tells it apart from the targets that were not edited.
+func driftStatus(t *PropagatorTarget) syncv1alpha1.TargetStatus {
+	message := "target data was modified outside the controller and was restored from the source"
+	if len(t.DriftedKeys) > 0 {
+		message = fmt.Sprintf("keys %s were modified outside the controller and were restored from the source", strings.Join(t.DriftedKeys, ", "))
+	}
+	if t.DriftCount >= recurringDriftThreshold {
+		message += fmt.Sprintf(", the target has drifted %d times within %s", t.DriftCount, recurringDriftWindow)
+	}
+	return syncv1alpha1.TargetStatus{
+		Namespace: t.Namespace,
+		Name:      t.ConfigmapName,
+		State:     "Synced",
+		Reason:    "DriftDetected",
+		Message:   message,
+	}
+}
+
+// setDriftCorrectedCondition reports the targets whose drift was corrected by this sync,
+// the condition is removed again by a sync that found no drifted target.
+func setDriftCorrectedCondition(cmp *syncv1alpha1.ConfigMapPropagation, targetStatuses []syncv1alpha1.TargetStatus, generation int64) {
+	var drifted []string
+	for _, t := range targetStatuses {
+		if t.Reason == "DriftDetected" {
+			drifted = append(drifted, t.Namespace+"/"+t.Name)
+		}
+	}
+	if len(drifted) == 0 {
+		meta.RemoveStatusCondition(&cmp.Status.Conditions, ConditionTypeDriftCorrected)
+		return
+	}
+	meta.SetStatusCondition(&cmp.Status.Conditions, metav1.Condition{
+		Type:               ConditionTypeDriftCorrected,
+		Status:             metav1.ConditionTrue,
+		Reason:             "DriftCorrected",
+		Message:            fmt.Sprintf("Restored from the source: %s", strings.Join(drifted, ",")),
+		ObservedGeneration: generation,
+	})
+}
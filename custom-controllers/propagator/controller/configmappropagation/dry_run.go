@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// isDryRun reports whether the propagation asks for a preview of the sync instead of a sync.
+func isDryRun(configmapPropagator *syncv1alpha1.ConfigMapPropagation) bool {
+	return configmapPropagator.Annotations[DryRunAnnotation] == "true"
+}
+
+// previewSync reports the targets a sync would create, update, delete or orphan in the status without
+// touching any ConfigMap. The generation is not marked as synced, so removing the annotation syncs for real.
+func (r *ConfigMapPropagationReconciler) previewSync(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, toCreate, toUpdate, toDelete []*PropagatorTarget) (ctrl.Result, error) {
+	summary := syncv1alpha1.TargetsSummary{}
+	statuses := make([]syncv1alpha1.TargetStatus, 0, len(toCreate)+len(toUpdate)+len(toDelete))
+	plan := func(t *PropagatorTarget, action string) {
+		statuses = append(statuses, syncv1alpha1.TargetStatus{
+			Namespace: t.Namespace,
+			Name:      t.ConfigmapName,
+			State:     "Skipped",
+			Reason:    "DryRun",
+			Message:   "would be " + action,
+		})
+		summary.Total += 1
+	}
+
+	for _, t := range toCreate {
+		plan(t, "created")
+		summary.Created += 1
+	}
+	for _, t := range toUpdate {
+		plan(t, "updated")
+		summary.Updated += 1
+	}
+	for _, t := range toDelete {
+		switch configmapPropagator.Spec.DeletionPolicy {
+		case "Delete":
+			plan(t, "deleted")
+			summary.Deleted += 1
+		case "Orphan":
+			plan(t, "orphaned")
+			summary.Orphaned += 1
+		}
+	}
+
+	updateCmp := configmapPropagator.DeepCopy()
+	updateCmp.Status.TargetsSummary = summary
+	updateCmp.Status.TargetStatuses = statuses
+	meta.SetStatusCondition(&updateCmp.Status.Conditions, metav1.Condition{
+		Type:   ConditionTypeReady,
+		Status: metav1.ConditionFalse,
+		Reason: "DryRun",
+		Message: fmt.Sprintf("dry run, %d to create, %d to update, %d to delete, %d to orphan. Remove the %s annotation to sync",
+			summary.Created, summary.Updated, summary.Deleted, summary.Orphaned, DryRunAnnotation),
+	})
+	if !equality.Semantic.DeepEqual(configmapPropagator.Status, updateCmp.Status) {
+		if err := r.patchStatus(ctx, configmapPropagator, updateCmp); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update the status of configmappropagator: %w", err)
+		}
+	}
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,40 @@
+package controller
+
+// staleDuplicates returns the stale targets that live in a namespace which also has a desired target,
+// keyed by the stale target and pointing at the desired one. These are left behind when a target is
+// renamed in the spec, so two managed copies exist in the same namespace.
+func staleDuplicates(desired []*PropagatorTarget, stale []*PropagatorTarget) map[*PropagatorTarget]*PropagatorTarget {
+	desiredByNamespace := make(map[string]*PropagatorTarget, len(desired))
+	for _, t := range desired {
+		if _, ok := desiredByNamespace[t.Namespace]; !ok {
+			desiredByNamespace[t.Namespace] = t
+		}
+	}
+	duplicates := make(map[*PropagatorTarget]*PropagatorTarget)
+	for _, t := range stale {
+		// Superseded hash suffixed copies are expected and retired after their grace period
+		if t.HashOf != "" {
+			continue
+		}
+		if d, ok := desiredByNamespace[t.Namespace]; ok {
+			duplicates[t] = d
+		}
+	}
+	return duplicates
+}
+
+// hasDuplicateTargets reports whether a managed target duplicates a desired target in the same
+// namespace, in which case the propagation is reconciled even if its SyncMode would skip it.
+func hasDuplicateTargets(desired, current []*PropagatorTarget) bool {
+	desiredKeys := make(map[string]struct{}, len(desired))
+	for _, t := range desired {
+		desiredKeys[t.Namespace+"/"+t.ConfigmapName] = struct{}{}
+	}
+	stale := make([]*PropagatorTarget, 0)
+	for _, t := range current {
+		if _, ok := desiredKeys[t.Namespace+"/"+t.ConfigmapName]; !ok {
+			stale = append(stale, t)
+		}
+	}
+	return len(staleDuplicates(desired, stale)) > 0
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// sourceHashSuffix returns the hash suffix of the data the source propagates with the current spec.
+func (r *ConfigMapPropagationReconciler) sourceHashSuffix(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) (string, error) {
+	src, err := r.getSource(ctx, configmapPropagator)
+	if err != nil {
+		return "", fmt.Errorf("failed to get source configmap to hash: %w", err)
+	}
+	return sourceDataHash(configmapPropagator, src)
+}
+
+// sourceDataHash returns the hash of the data src propagates with the current spec.
+func sourceDataHash(configmapPropagator *syncv1alpha1.ConfigMapPropagation, src *corev1.ConfigMap) (string, error) {
+	data, _, err := prepareSourceData(configmapPropagator, src)
+	if err != nil {
+		return "", err
+	}
+	binaryData, err := prepareSourceBinaryData(configmapPropagator, src)
+	if err != nil {
+		return "", err
+	}
+	return dataHash(data, binaryData), nil
+}
+
+// dataHash returns the first 10 hex characters of a sha256 over the sorted Data and BinaryData entries.
+func dataHash(data map[string]string, binaryData map[string][]byte) string {
+	h := sha256.New()
+	writeEntries := func(prefix string, keys []string, value func(string) []byte) {
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(h, "%s%d:%s", prefix, len(k), k)
+			v := value(k)
+			fmt.Fprintf(h, "%d:", len(v))
+			h.Write(v)
+		}
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	writeEntries("d", keys, func(k string) []byte { return []byte(data[k]) })
+	keys = make([]string, 0, len(binaryData))
+	for k := range binaryData {
+		keys = append(keys, k)
+	}
+	writeEntries("b", keys, func(k string) []byte { return binaryData[k] })
+	return hex.EncodeToString(h.Sum(nil))[:10]
+}
+
+// retireHashedTargets holds back superseded hash suffixed targets from deletion until the grace period
+// has passed since they were first seen superseded. It returns the targets to delete now and the time
+// until the next held back target may be deleted, zero when none is waiting.
+func (r *ConfigMapPropagationReconciler) retireHashedTargets(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, toDelete []*PropagatorTarget) ([]*PropagatorTarget, time.Duration, error) {
+	if !configmapPropagator.Spec.HashSuffixTargetNames {
+		return toDelete, 0, nil
+	}
+	grace := defaultHashedTargetGracePeriod
+	if g := configmapPropagator.Spec.HashedTargetGracePeriod; g != nil {
+		grace = g.Duration
+	}
+
+	now := r.now()
+	remaining := make([]*PropagatorTarget, 0, len(toDelete))
+	var wait time.Duration
+	for _, t := range toDelete {
+		if t.HashOf == "" {
+			remaining = append(remaining, t)
+			continue
+		}
+		cm := &corev1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: t.Namespace, Name: t.ConfigmapName}, cm); err != nil {
+			return nil, 0, err
+		}
+		supersededAt, err := time.Parse(time.RFC3339, cm.Annotations[SupersededAtAnnotation])
+		if err != nil {
+			supersededAt = now
+			if cm.Annotations == nil {
+				cm.Annotations = map[string]string{}
+			}
+			cm.Annotations[SupersededAtAnnotation] = now.UTC().Format(time.RFC3339)
+			if err := r.Update(ctx, cm); err != nil {
+				return nil, 0, fmt.Errorf("failed to mark %s/%s as superseded: %w", t.Namespace, t.ConfigmapName, err)
+			}
+		}
+		if left := supersededAt.Add(grace).Sub(now); left > 0 {
+			if wait == 0 || left < wait {
+				wait = left
+			}
+			continue
+		}
+		remaining = append(remaining, t)
+	}
+	return remaining, wait, nil
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
+)
+
+// ImmutableTargetError is returned when the data of an existing target has to change but the target is immutable.
+type ImmutableTargetError struct {
+	Namespace string
+	Name      string
+}
+
+func (e *ImmutableTargetError) Error() string {
+	return fmt.Sprintf("target configmap %s/%s is immutable, its data can't be updated", e.Namespace, e.Name)
+}
+
+// isImmutableRejection reports whether err is the API server refusing a data change on an immutable ConfigMap.
+func isImmutableRejection(err error) bool {
+	return apierrors.IsInvalid(err) && strings.Contains(err.Error(), "field is immutable")
+}
+
+// immutableFromSource reports whether the targets have to be immutable because the source is and
+// PropagateImmutable is set.
+func immutableFromSource(cmp *syncv1alpha1.ConfigMapPropagation, src *corev1.ConfigMap) bool {
+	return cmp.Spec.PropagateImmutable && ptr.Deref(src.Immutable, false)
+}
+
+// recreateImmutableTarget replaces an immutable target by a new one holding the current source data.
+func (r *ConfigMapPropagationReconciler) recreateImmutableTarget(ctx context.Context, cmp *syncv1alpha1.ConfigMapPropagation, t *PropagatorTarget, target *corev1.ConfigMap) error {
+	if err := r.Delete(ctx, target); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete immutable target configmap %s/%s: %w", t.Namespace, t.ConfigmapName, err)
+	}
+	return r.ensureConfigMap(ctx, cmp, t)
+}
+
+// immutableStatus reports a target that was skipped because it is immutable.
+func immutableStatus(t *PropagatorTarget, immutableErr *ImmutableTargetError) syncv1alpha1.TargetStatus {
+	return syncv1alpha1.TargetStatus{
+		Namespace: t.Namespace,
+		Name:      t.ConfigmapName,
+		State:     "Skipped",
+		Reason:    "Immutable",
+		Message:   immutableErr.Error() + ", set recreateImmutableTargets to replace it",
+	}
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+)
+
+// managedKeysAnnotation returns the value of the managed keys annotation for the propagated data.
+func managedKeysAnnotation(cmp *syncv1alpha1.ConfigMapPropagation, data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	return cappedKeyList(cmp, keys)
+}
+
+// cappedKeyList returns the sorted keys joined by commas. Lists longer than maxManagedKeysAnnotationLength
+// are replaced by a reference to the propagation, which never reads as a key since keys can't hold a slash.
+func cappedKeyList(cmp *syncv1alpha1.ConfigMapPropagation, keys []string) string {
+	sort.Strings(keys)
+	value := strings.Join(keys, ",")
+	if len(value) > maxManagedKeysAnnotationLength {
+		return fmt.Sprintf("configmappropagation/%s", cmp.Name)
+	}
+	return value
+}
+
+// setManagedKeysAnnotation stamps the managed keys and their count on the annotations when
+// AnnotateManagedKeys is set and removes them otherwise. It reports whether the annotations changed.
+func setManagedKeysAnnotation(cmp *syncv1alpha1.ConfigMapPropagation, annotations map[string]string, data map[string]string) bool {
+	desired := map[string]string{}
+	if cmp.Spec.AnnotateManagedKeys {
+		desired[ManagedKeysAnnotation] = managedKeysAnnotation(cmp, data)
+		desired[ManagedKeyCountAnnotation] = strconv.Itoa(len(data))
+	}
+
+	changed := false
+	for _, key := range []string{ManagedKeysAnnotation, ManagedKeyCountAnnotation} {
+		current, exists := annotations[key]
+		value, wanted := desired[key]
+		switch {
+		case wanted && (!exists || current != value):
+			annotations[key] = value
+			changed = true
+		case !wanted && exists:
+			delete(annotations, key)
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"time"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	managedTargetsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "configmappropagation_managed_targets",
+		Help: "Number of target ConfigMaps in sync with the source, per ConfigMapPropagation.",
+	}, []string{"namespace", "name"})
+
+	targetOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "configmappropagation_target_operations_total",
+		Help: "Target ConfigMaps created, updated, deleted, orphaned, failed, skipped or restored from drift by SyncTargets, per ConfigMapPropagation.",
+	}, []string{"namespace", "name", "operation"})
+
+	syncDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "configmappropagation_sync_duration_seconds",
+		Help:    "Duration of SyncTargets, per ConfigMapPropagation.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(managedTargetsGauge, targetOperationsTotal, syncDurationSeconds)
+}
+
+// recordSyncMetrics adds the outcome of a sync to the metrics of the propagation.
+func recordSyncMetrics(configmapPropagator *syncv1alpha1.ConfigMapPropagation, summary syncv1alpha1.TargetsSummary, managed int32) {
+	ns, name := configmapPropagator.Namespace, configmapPropagator.Name
+	managedTargetsGauge.WithLabelValues(ns, name).Set(float64(managed))
+	for operation, count := range map[string]int32{
+		"created":  summary.Created,
+		"updated":  summary.Updated,
+		"deleted":  summary.Deleted,
+		"orphaned": summary.Orphaned,
+		"failed":   summary.Failed,
+		"skipped":  summary.Skipped,
+		"drifted":  summary.Drifted,
+	} {
+		targetOperationsTotal.WithLabelValues(ns, name, operation).Add(float64(count))
+	}
+}
+
+// observeSyncDuration records how long a sync of the propagation took since start.
+func observeSyncDuration(configmapPropagator *syncv1alpha1.ConfigMapPropagation, start time.Time) {
+	syncDurationSeconds.WithLabelValues(configmapPropagator.Namespace, configmapPropagator.Name).Observe(time.Since(start).Seconds())
+}
+
+// forgetPropagationMetrics drops the series of a deleted propagation.
+func forgetPropagationMetrics(configmapPropagator *syncv1alpha1.ConfigMapPropagation) {
+	labels := prometheus.Labels{"namespace": configmapPropagator.Namespace, "name": configmapPropagator.Name}
+	managedTargetsGauge.DeletePartialMatch(labels)
+	targetOperationsTotal.DeletePartialMatch(labels)
+	syncDurationSeconds.DeletePartialMatch(labels)
+}
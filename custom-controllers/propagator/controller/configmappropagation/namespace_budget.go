@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// namespaceBudgetExceeded reports whether creating one more ConfigMap in ns would exceed
+// MaxConfigMapsPerNamespace, along with the current count. A zero budget disables the check.
+func (r *ConfigMapPropagationReconciler) namespaceBudgetExceeded(ctx context.Context, ns string) (bool, int, error) {
+	if r.MaxConfigMapsPerNamespace <= 0 {
+		return false, 0, nil
+	}
+	var list corev1.ConfigMapList
+	if err := r.List(ctx, &list, client.InNamespace(ns)); err != nil {
+		return false, 0, fmt.Errorf("failed to count configmaps in namespace %s: %w", ns, err)
+	}
+	return len(list.Items)+1 > r.MaxConfigMapsPerNamespace, len(list.Items), nil
+}
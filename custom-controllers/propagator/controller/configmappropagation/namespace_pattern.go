@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// namespaceNamePatternRegexPrefix marks a NamespaceNamePattern as a regular expression instead of a glob
+const namespaceNamePatternRegexPrefix = "re:"
+
+// namespaceNamePattern matches namespace names against a NamespaceNamePattern.
+// A nil pattern matches no namespace.
+type namespaceNamePattern struct {
+	glob  string
+	regex *regexp.Regexp
+}
+
+// compileNamespaceNamePattern parses a NamespaceNamePattern, it returns nil for an empty pattern.
+// Regular expressions must match the whole name.
+func compileNamespaceNamePattern(pattern string) (*namespaceNamePattern, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if expr, ok := strings.CutPrefix(pattern, namespaceNamePatternRegexPrefix); ok {
+		regex, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespaceNamePattern %q: %w", pattern, err)
+		}
+		return &namespaceNamePattern{regex: regex}, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid namespaceNamePattern %q: %w", pattern, err)
+	}
+	return &namespaceNamePattern{glob: pattern}, nil
+}
+
+// Matches reports whether the namespace name matches the pattern.
+func (p *namespaceNamePattern) Matches(name string) bool {
+	if p == nil {
+		return false
+	}
+	if p.regex != nil {
+		return p.regex.MatchString(name)
+	}
+	// Namespace names never contain a slash, path.Match treats the whole name as one segment
+	matched, _ := path.Match(p.glob, name)
+	return matched
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"slices"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// namespaceEventHandler enqueues the propagations targeting a namespace on namespace events.
+// Requests are added after a short coalescing delay, the workqueue keeps a single entry per
+// propagation while it waits, so a burst of namespace churn results in one reconcile per propagation.
+func (r *ConfigMapPropagationReconciler) namespaceEventHandler() handler.Funcs {
+	delay := r.NamespaceCoalesceDelay
+	if delay <= 0 {
+		delay = defaultNamespaceCoalesceDelay
+	}
+	enqueue := func(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], objs ...client.Object) {
+		for _, req := range r.mapNamespace(ctx, objs...) {
+			q.AddAfter(req, delay)
+		}
+	}
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			// Both label sets matter: a label change can add or remove the namespace from a selector
+			enqueue(ctx, q, e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+	}
+}
+
+// mapNamespace returns the propagations whose targets or namespace selector cover any of the namespaces.
+func (r *ConfigMapPropagationReconciler) mapNamespace(ctx context.Context, namespaces ...client.Object) []reconcile.Request {
+	var list syncv1alpha1.ConfigMapPropagationList
+	if err := r.List(ctx, &list); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list configmap propagators for namespace event")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0)
+	for i := range list.Items {
+		cmp := &list.Items[i]
+		if slices.ContainsFunc(namespaces, func(ns client.Object) bool { return targetsNamespace(cmp, ns) }) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+		}
+	}
+	return requests
+}
+
+// targetsNamespace reports whether the propagation lists the namespace as a target or selects it
+// by label or name.
+func targetsNamespace(configmapPropagator *syncv1alpha1.ConfigMapPropagation, ns client.Object) bool {
+	if namespaceExcluded(configmapPropagator, ns.GetName()) {
+		return false
+	}
+	for _, t := range configmapPropagator.Spec.Targets {
+		if t.Namespace == ns.GetName() {
+			return true
+		}
+	}
+	if namePattern, err := compileNamespaceNamePattern(configmapPropagator.Spec.NamespaceNamePattern); err == nil && namePattern.Matches(ns.GetName()) {
+		return true
+	}
+	sel, err := namespaceSelector(configmapPropagator.Spec.NamespaceSelector)
+	if err != nil {
+		return false
+	}
+	return sel.Matches(labels.Set(ns.GetLabels()))
+}
+
+// hasTargetSetChanges reports whether a desired target is missing or a managed target is no longer desired,
+// e.g. after a matching namespace was created or a namespace lost its labels. These are applied even when
+// the SyncMode would skip the reconcile, CreatedOnce and OnChange have no requeue that would catch them.
+func hasTargetSetChanges(desired, current []*PropagatorTarget) bool {
+	currentKeys := make(map[string]struct{}, len(current))
+	for _, t := range current {
+		currentKeys[t.Namespace+"/"+t.ConfigmapName] = struct{}{}
+	}
+	desiredKeys := make(map[string]struct{}, len(desired))
+	for _, t := range desired {
+		key := t.Namespace + "/" + t.ConfigmapName
+		desiredKeys[key] = struct{}{}
+		if _, ok := currentKeys[key]; !ok {
+			return true
+		}
+	}
+	for _, t := range current {
+		// Superseded hash suffixed copies are retired on their own schedule
+		if t.HashOf != "" {
+			continue
+		}
+		if _, ok := desiredKeys[t.Namespace+"/"+t.ConfigmapName]; !ok {
+			return true
+		}
+	}
+	return false
+}
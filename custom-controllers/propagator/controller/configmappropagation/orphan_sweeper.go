@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// orphanSweeper deletes the targets orphaned with DeleteExpiredOrphans once their expiry has passed.
+// It runs next to the controller rather than in a Reconcile, since the propagation that orphaned a
+// target has usually been deleted by the time the target expires.
+type orphanSweeper struct {
+	Client   client.Client
+	Recorder record.EventRecorder
+	Clock    clock.PassiveClock
+	// Interval is how often the orphaned targets are listed, defaults to defaultOrphanSweepInterval
+	Interval time.Duration
+}
+
+// Start sweeps the expired orphans every Interval until ctx is done.
+func (s *orphanSweeper) Start(ctx context.Context) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultOrphanSweepInterval
+	}
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.sweep(ctx); err != nil {
+			logf.FromContext(ctx).Error(err, "failed to sweep expired orphans")
+		}
+	}, interval)
+	return nil
+}
+
+// NeedLeaderElection makes only the elected replica delete orphans.
+func (s *orphanSweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// sweep deletes every orphaned target whose expiry has passed.
+func (s *orphanSweeper) sweep(ctx context.Context) error {
+	var configmapList corev1.ConfigMapList
+	if err := s.Client.List(ctx, &configmapList, client.MatchingLabels{OrphanedLabelKey: "true"}); err != nil {
+		return err
+	}
+	now := time.Now()
+	if s.Clock != nil {
+		now = s.Clock.Now()
+	}
+	for i := range configmapList.Items {
+		configmap := &configmapList.Items[i]
+		// A propagation adopted the target again since it was orphaned
+		owned := configmap.Labels[OwnerLabelKey] != "" || configmap.Annotations[OwnerUIDAnnotation] != ""
+		if owned || !targetExpired(configmap, now) {
+			continue
+		}
+		if err := s.Client.Delete(ctx, configmap); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete expired orphan %s/%s: %w", configmap.Namespace, configmap.Name, err)
+		}
+		s.Recorder.Eventf(configmap, corev1.EventTypeNormal, "DeletedExpiredOrphan",
+			"deleted orphaned ConfigMap %s/%s after its TTL expired", configmap.Namespace, configmap.Name)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package controller
+
+import (
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
+)
+
+// ownedByAnotherError reports a target ConfigMap already managed by the propagation owner.
+func ownedByAnotherError(t *PropagatorTarget, owner string) *ownership.OwnedByAnotherError {
+	return &ownership.OwnedByAnotherError{Kind: "configmap", Namespace: t.Namespace, Name: t.ConfigmapName, OwnerKind: "ConfigMapPropagation", Owner: owner}
+}
+
+// ownedByAnotherStatus reports a target that was left to the propagation already managing it.
+func ownedByAnotherStatus(t *PropagatorTarget, ownedErr *ownership.OwnedByAnotherError) syncv1alpha1.TargetStatus {
+	return syncv1alpha1.TargetStatus{
+		Namespace: t.Namespace,
+		Name:      t.ConfigmapName,
+		State:     "Skipped",
+		Reason:    "OwnedByAnother",
+		Message:   ownedErr.Error() + ", remove it from one of the propagations",
+	}
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// listInPages lists into list and calls visit once per page. With ListPageSize and an uncached
+// BulkListReader the list is read ListPageSize objects at a time following the continue token, so
+// only one page of list copies is allocated at a time. The informer still caches every object, the
+// pages only bound the copies made per list, at the cost of API server calls. Otherwise the whole list is read from the client in one page,
+// the cache-backed client doesn't support continue tokens.
+// A continue token that expired mid-list (410 Gone) restarts the list from the first page, reset is
+// called first so visit can drop what it collected from the earlier pages. reset may be nil when
+// visiting a page twice is harmless.
+func (r *ConfigMapPropagationReconciler) listInPages(ctx context.Context, list client.ObjectList, reset func(), visit func() error, opts ...client.ListOption) error {
+	if r.ListPageSize <= 0 || r.BulkListReader == nil {
+		if err := r.List(ctx, list, opts...); err != nil {
+			return err
+		}
+		return visit()
+	}
+
+	continueToken := ""
+	restarts := 0
+	for {
+		pageOpts := append([]client.ListOption{client.Limit(r.ListPageSize), client.Continue(continueToken)}, opts...)
+		if err := r.BulkListReader.List(ctx, list, pageOpts...); err != nil {
+			if continueToken == "" || !apierrors.IsResourceExpired(err) || restarts >= maxListRestarts {
+				return err
+			}
+			logf.FromContext(ctx).V(1).Info("continue token expired, restarting the list")
+			restarts++
+			continueToken = ""
+			if reset != nil {
+				reset()
+			}
+			continue
+		}
+		if err := visit(); err != nil {
+			return err
+		}
+		continueToken = list.GetContinue()
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
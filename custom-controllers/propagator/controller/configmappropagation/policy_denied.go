@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// admissionDenial returns the denial message when err is a rejection by a validating admission webhook
+// in the target namespace, e.g. a policy on the data content. Retrying such errors does not help.
+func admissionDenial(err error) (string, bool) {
+	var status apierrors.APIStatus
+	if err == nil || !errors.As(err, &status) {
+		return "", false
+	}
+	message := status.Status().Message
+	if !strings.Contains(message, "admission webhook") || !strings.Contains(message, "denied the request") {
+		return "", false
+	}
+	return message, true
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// prunesRemovedSourceKeys reports whether Merge targets drop the keys the source no longer carries.
+func prunesRemovedSourceKeys(cmp *syncv1alpha1.ConfigMapPropagation) bool {
+	return cmp.Spec.PruneRemovedSourceKeys && cmp.Spec.PropagationPolicy != "Overwrite"
+}
+
+// removedSourceKey reports whether key was propagated to the target on the last write but is no longer
+// part of the source keys, so it has to be pruned. PreserveTargetKeys are never pruned.
+func removedSourceKey(cmp *syncv1alpha1.ConfigMapPropagation, target *corev1.ConfigMap, key string, inSource bool) bool {
+	if inSource || !prunesRemovedSourceKeys(cmp) || slices.Contains(cmp.Spec.PreserveTargetKeys, key) {
+		return false
+	}
+	propagated, ok := target.Annotations[PropagatedKeysAnnotation]
+	return ok && slices.Contains(strings.Split(propagated, ","), key)
+}
+
+// setPropagatedKeysAnnotation records the keys propagated from the source when PruneRemovedSourceKeys
+// applies and removes the annotation otherwise. The list is capped like the managed keys annotation,
+// keys are not pruned from a target whose list was too long to record. It reports whether the
+// annotations changed.
+func setPropagatedKeysAnnotation(cmp *syncv1alpha1.ConfigMapPropagation, annotations map[string]string, data map[string]string, binaryData map[string][]byte) bool {
+	current, exists := annotations[PropagatedKeysAnnotation]
+	if !prunesRemovedSourceKeys(cmp) {
+		if exists {
+			delete(annotations, PropagatedKeysAnnotation)
+		}
+		return exists
+	}
+	keys := make([]string, 0, len(data)+len(binaryData))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	for k := range binaryData {
+		keys = append(keys, k)
+	}
+	value := cappedKeyList(cmp, keys)
+	if exists && current == value {
+		return false
+	}
+	annotations[PropagatedKeysAnnotation] = value
+	return true
+}
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"strconv"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// propagatedRevision returns the revision and data hash of the data src propagates. The revision
+// in status is kept while the data hash is unchanged and is incremented when the data changed.
+func propagatedRevision(configmapPropagator *syncv1alpha1.ConfigMapPropagation, src *corev1.ConfigMap) (int64, string, error) {
+	hash, err := sourceDataHash(configmapPropagator, src)
+	if err != nil {
+		return 0, "", err
+	}
+	if hash == configmapPropagator.Status.RevisionHash {
+		return configmapPropagator.Status.Revision, hash, nil
+	}
+	return configmapPropagator.Status.Revision + 1, hash, nil
+}
+
+// setRevisionAnnotation stamps the revision on the annotations and reports whether they changed.
+func setRevisionAnnotation(annotations map[string]string, revision int64) bool {
+	value := strconv.FormatInt(revision, 10)
+	if annotations[RevisionAnnotation] == value {
+		return false
+	}
+	annotations[RevisionAnnotation] = value
+	return true
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FieldManagerConflictError is returned when applying a target conflicts with fields owned by other managers.
+type FieldManagerConflictError struct {
+	Namespace string
+	Name      string
+	// Conflicts describes each conflicting field and the manager owning it.
+	Conflicts []string
+}
+
+func (e *FieldManagerConflictError) Error() string {
+	return fmt.Sprintf("apply conflict on configmap %s/%s: %s", e.Namespace, e.Name, strings.Join(e.Conflicts, "; "))
+}
+
+// applyTarget applies the desired Data, the copied source metadata and the ownership metadata to the target
+// with Server-Side Apply. Conflicts are forced only when ForceConflicts is set, otherwise they surface as a
+// FieldManagerConflictError. Keys the target still holds outside the desired data are removed afterwards,
+// since an apply only drops the fields this manager owned.
+func (r *ConfigMapPropagationReconciler) applyTarget(ctx context.Context, cmp *syncv1alpha1.ConfigMapPropagation, target *corev1.ConfigMap,
+	desiredData map[string]string, desiredBinaryData map[string][]byte, copiedLabels, copiedAnnotations map[string]string) error {
+	labels := map[string]string{}
+	for k, v := range copiedLabels {
+		labels[k] = v
+	}
+	labels[OwnerLabelKey] = cmp.Name
+	labels[ManagedByLabelKey] = ManagedByLabelValue
+	annotations := map[string]string{}
+	for k, v := range copiedAnnotations {
+		annotations[k] = v
+	}
+	annotations[OwnerUIDAnnotation] = string(cmp.UID)
+	for _, key := range []string{GzipBase64KeysAnnotation, ExpiresAtAnnotation, ManagedKeysAnnotation, ManagedKeyCountAnnotation, PropagatedKeysAnnotation, RevisionAnnotation, AppliedHashAnnotation, DriftCountAnnotation, DriftedAtAnnotation} {
+		if v, ok := target.Annotations[key]; ok {
+			annotations[key] = v
+		}
+	}
+	cm := corev1ac.ConfigMap(target.Name, target.Namespace).
+		WithLabels(labels).
+		WithAnnotations(annotations).
+		WithData(desiredData)
+	if len(desiredBinaryData) > 0 {
+		cm = cm.WithBinaryData(desiredBinaryData)
+	}
+	if ptr.Deref(target.Immutable, false) {
+		cm = cm.WithImmutable(true)
+	}
+
+	opts := []client.ApplyOption{client.FieldOwner(FieldManager)}
+	if cmp.Spec.ServerSideApply.ForceConflicts {
+		opts = append(opts, client.ForceOwnership)
+	}
+
+	err := r.Apply(ctx, cm, opts...)
+	if err != nil {
+		if conflicts := applyConflicts(err); len(conflicts) > 0 {
+			return &FieldManagerConflictError{Namespace: target.Namespace, Name: target.Name, Conflicts: conflicts}
+		}
+		return fmt.Errorf("failed to apply target configmap %s/%s: %w", target.Namespace, target.Name, err)
+	}
+	return r.removeStrayKeys(ctx, target, desiredData, desiredBinaryData)
+}
+
+// removeStrayKeys deletes the Data and BinaryData keys of the target that are not desired, such as keys
+// set by another field manager that an apply of the desired data leaves in place.
+func (r *ConfigMapPropagationReconciler) removeStrayKeys(ctx context.Context, target *corev1.ConfigMap,
+	desiredData map[string]string, desiredBinaryData map[string][]byte) error {
+	data := map[string]interface{}{}
+	for k := range target.Data {
+		if _, ok := desiredData[k]; !ok {
+			data[k] = nil
+		}
+	}
+	binaryData := map[string]interface{}{}
+	for k := range target.BinaryData {
+		if _, ok := desiredBinaryData[k]; !ok {
+			binaryData[k] = nil
+		}
+	}
+	if len(data) == 0 && len(binaryData) == 0 {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{"data": data, "binaryData": binaryData})
+	if err != nil {
+		return err
+	}
+	if err := r.Patch(ctx, target, client.RawPatch(types.MergePatchType, patch), client.FieldOwner(FieldManager)); err != nil {
+		return fmt.Errorf("failed to remove stray keys from target configmap %s/%s: %w", target.Namespace, target.Name, err)
+	}
+	return nil
+}
+
+// applyConflicts returns the field manager conflicts reported by an apply error, if any.
+func applyConflicts(err error) []string {
+	if !apierrors.IsConflict(err) {
+		return nil
+	}
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil {
+		return nil
+	}
+	conflicts := make([]string, 0)
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			conflicts = append(conflicts, cause.Message)
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,273 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// additionalSourceNamespace returns the namespace of an additional source, defaulting to the source namespace.
+func additionalSourceNamespace(configmapPropagator *syncv1alpha1.ConfigMapPropagation, additional syncv1alpha1.AdditionalSource) string {
+	if additional.Namespace == "" {
+		return configmapPropagator.Spec.Source.NamespaceOrDefault()
+	}
+	return additional.Namespace
+}
+
+// getSource returns the source ConfigMap of the propagation with the AdditionalSources merged over it.
+func (r *ConfigMapPropagationReconciler) getSource(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) (*corev1.ConfigMap, error) {
+	src, err := r.getBaseSource(ctx, configmapPropagator)
+	if err != nil {
+		return nil, err
+	}
+	return r.mergeAdditionalSources(ctx, configmapPropagator, src)
+}
+
+// targetSource returns the source a target is written from. SyncTargets reads the source once and hands
+// it to every target, the source and its AdditionalSources are only fetched here when it didn't.
+func (r *ConfigMapPropagationReconciler) targetSource(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, t *PropagatorTarget) (*corev1.ConfigMap, error) {
+	if t.Source != nil {
+		return t.Source, nil
+	}
+	return r.getSource(ctx, configmapPropagator)
+}
+
+// mergeAdditionalSources layers the data of the AdditionalSources over src in order, so the later source
+// wins on a key conflict. A missing source is skipped when it is Optional, otherwise an
+// AdditionalSourceNotFoundError is returned.
+func (r *ConfigMapPropagationReconciler) mergeAdditionalSources(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, src *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	if len(configmapPropagator.Spec.AdditionalSources) == 0 {
+		return src, nil
+	}
+	merged := src.DeepCopy()
+	for _, additional := range configmapPropagator.Spec.AdditionalSources {
+		cm := &corev1.ConfigMap{}
+		key := types.NamespacedName{Namespace: additionalSourceNamespace(configmapPropagator, additional), Name: additional.Name}
+		if err := r.Get(ctx, key, cm); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			if additional.Optional {
+				continue
+			}
+			return nil, &AdditionalSourceNotFoundError{Namespace: key.Namespace, Name: key.Name}
+		}
+		for k, v := range cm.Data {
+			if merged.Data == nil {
+				merged.Data = map[string]string{}
+			}
+			merged.Data[k] = v
+		}
+		for k, v := range cm.BinaryData {
+			if merged.BinaryData == nil {
+				merged.BinaryData = map[string][]byte{}
+			}
+			merged.BinaryData[k] = v
+		}
+	}
+	return merged, nil
+}
+
+// getBaseSource returns the source ConfigMap named by Source or selected by SourceSelector. In the Merge
+// SourceSelectorMode the matching ConfigMaps are merged into a single ConfigMap named after Source.Name, in name
+// order so the alphabetically later ConfigMap wins on a key conflict. A NotFound error is returned when nothing matches.
+func (r *ConfigMapPropagationReconciler) getBaseSource(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) (*corev1.ConfigMap, error) {
+	ns := configmapPropagator.Spec.Source.NamespaceOrDefault()
+	if configmapPropagator.Spec.SecretSource != nil {
+		return r.getSecretSource(ctx, configmapPropagator)
+	}
+	if configmapPropagator.Spec.SourceSelector == nil {
+		src := &corev1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: configmapPropagator.Spec.Source.Name}, src); err != nil {
+			return nil, err
+		}
+		return src, nil
+	}
+	if configmapPropagator.Spec.SourceSelectorMode == syncv1alpha1.SourceSelectorModeSingle {
+		return r.getSelectedSource(ctx, configmapPropagator)
+	}
+
+	sel, err := metav1.LabelSelectorAsSelector(configmapPropagator.Spec.SourceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sourceSelector: %w", err)
+	}
+	var list corev1.ConfigMapList
+	if err := r.List(ctx, &list, client.InNamespace(ns), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+		return nil, err
+	}
+	sources := make([]corev1.ConfigMap, 0, len(list.Items))
+	for _, cm := range list.Items {
+		// Targets written into the source namespace must not be read back as sources
+		if isManagedConfigMap(&cm) {
+			continue
+		}
+		sources = append(sources, cm)
+	}
+	if len(sources) == 0 {
+		return nil, apierrors.NewNotFound(corev1.Resource("configmaps"), fmt.Sprintf("%s (selector %s)", configmapPropagator.Spec.Source.Name, sel))
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Name < sources[j].Name })
+
+	merged := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: configmapPropagator.Spec.Source.Name},
+	}
+	for _, src := range sources {
+		for k, v := range src.Data {
+			if merged.Data == nil {
+				merged.Data = map[string]string{}
+			}
+			merged.Data[k] = v
+		}
+		for k, v := range src.BinaryData {
+			if merged.BinaryData == nil {
+				merged.BinaryData = map[string][]byte{}
+			}
+			merged.BinaryData[k] = v
+		}
+	}
+	return merged, nil
+}
+
+// getSelectedSource returns the single ConfigMap in the source namespace matching SourceSelector.
+// A NotFound error is returned when nothing matches and an AmbiguousSourceError when several do.
+func (r *ConfigMapPropagationReconciler) getSelectedSource(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) (*corev1.ConfigMap, error) {
+	ns := configmapPropagator.Spec.Source.NamespaceOrDefault()
+	sel, err := metav1.LabelSelectorAsSelector(configmapPropagator.Spec.SourceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sourceSelector: %w", err)
+	}
+	var list corev1.ConfigMapList
+	if err := r.List(ctx, &list, client.InNamespace(ns), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+		return nil, err
+	}
+	matched := make([]string, 0, len(list.Items))
+	var src *corev1.ConfigMap
+	for i := range list.Items {
+		// Targets written into the source namespace must not be read back as the source
+		if isManagedConfigMap(&list.Items[i]) {
+			continue
+		}
+		src = &list.Items[i]
+		matched = append(matched, src.Name)
+	}
+	switch len(matched) {
+	case 0:
+		return nil, apierrors.NewNotFound(corev1.Resource("configmaps"), fmt.Sprintf("%s (selector %s)", configmapPropagator.Spec.Source.Name, sel))
+	case 1:
+		return src, nil
+	}
+	sort.Strings(matched)
+	return nil, &AmbiguousSourceError{Namespace: ns, Selector: sel.String(), Names: matched}
+}
+
+// getSecretSource reads the Secret named Source.Name and returns a ConfigMap holding only the
+// keys allowlisted in SecretSource, as data or binaryData.
+func (r *ConfigMapPropagationReconciler) getSecretSource(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) (*corev1.ConfigMap, error) {
+	ns := configmapPropagator.Spec.Source.NamespaceOrDefault()
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: configmapPropagator.Spec.Source.Name}, secret); err != nil {
+		return nil, err
+	}
+
+	src := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: secret.Name},
+	}
+	for _, k := range configmapPropagator.Spec.SecretSource.DataKeys {
+		v, ok := secret.Data[k]
+		if !ok {
+			continue
+		}
+		if !utf8.Valid(v) {
+			return nil, fmt.Errorf("secret key %q is not valid UTF-8, list it in binaryDataKeys instead", k)
+		}
+		if src.Data == nil {
+			src.Data = map[string]string{}
+		}
+		src.Data[k] = string(v)
+	}
+	for _, k := range configmapPropagator.Spec.SecretSource.BinaryDataKeys {
+		v, ok := secret.Data[k]
+		if !ok {
+			continue
+		}
+		if _, inData := src.Data[k]; inData {
+			return nil, fmt.Errorf("secret key %q is listed in both dataKeys and binaryDataKeys", k)
+		}
+		if src.BinaryData == nil {
+			src.BinaryData = map[string][]byte{}
+		}
+		src.BinaryData[k] = v
+	}
+	return src, nil
+}
+
+// mapSecretSource enqueues the propagations reading the Secret through a SecretSource, so that edits to the
+// allowlisted keys reach the targets without a spec change.
+func (r *ConfigMapPropagationReconciler) mapSecretSource(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list syncv1alpha1.ConfigMapPropagationList
+	if err := r.List(ctx, &list); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list configmap propagators for secret source event")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0)
+	for _, cmp := range list.Items {
+		if cmp.Spec.SecretSource != nil && cmp.Spec.Source.NamespaceOrDefault() == obj.GetNamespace() && cmp.Spec.Source.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+		}
+	}
+	return requests
+}
+
+// mapSource enqueues the propagations reading the ConfigMap as their source, either by name, through the
+// a SourceSelector or as one of the AdditionalSources, so that edits to the source reach the targets without a spec change.
+func (r *ConfigMapPropagationReconciler) mapSource(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list syncv1alpha1.ConfigMapPropagationList
+	if err := r.List(ctx, &list); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list configmap propagators for source event")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0)
+	for i := range list.Items {
+		cmp := &list.Items[i]
+		if readsAdditionalSource(cmp, obj) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+			continue
+		}
+		if cmp.Spec.SecretSource != nil || cmp.Spec.Source.NamespaceOrDefault() != obj.GetNamespace() {
+			continue
+		}
+		if cmp.Spec.SourceSelector == nil {
+			if cmp.Spec.Source.Name == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+			}
+			continue
+		}
+		sel, err := metav1.LabelSelectorAsSelector(cmp.Spec.SourceSelector)
+		if err != nil || !sel.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cmp.Name}})
+	}
+	return requests
+}
+
+// readsAdditionalSource reports whether the ConfigMap is one of the AdditionalSources of the propagation.
+func readsAdditionalSource(configmapPropagator *syncv1alpha1.ConfigMapPropagation, obj client.Object) bool {
+	for _, additional := range configmapPropagator.Spec.AdditionalSources {
+		if additional.Name == obj.GetName() && additionalSourceNamespace(configmapPropagator, additional) == obj.GetNamespace() {
+			return true
+		}
+	}
+	return false
+}
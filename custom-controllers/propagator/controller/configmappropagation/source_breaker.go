@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// sourceBreaker is a per-propagation circuit breaker around fetching the source ConfigMap.
+// After threshold consecutive failures it opens and the source is not fetched until the backoff
+// has passed, then a single probe is let through (half-open). A success closes it again, and so does a
+// new generation of the propagation, since the edited spec may point at another source.
+type sourceBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	backoff   time.Duration
+	states    map[string]*breakerState
+}
+
+type breakerState struct {
+	generation int64
+	failures   int
+	openUntil  time.Time
+}
+
+func newSourceBreaker(threshold int, backoff time.Duration) *sourceBreaker {
+	if threshold <= 0 {
+		threshold = defaultSourceFailureThreshold
+	}
+	if backoff <= 0 {
+		backoff = defaultSourceBreakerBackoff
+	}
+	return &sourceBreaker{threshold: threshold, backoff: backoff, states: map[string]*breakerState{}}
+}
+
+// allow reports whether the source may be fetched now, and if not how long until the next probe.
+// The failures recorded for an older generation are dropped.
+func (b *sourceBreaker) allow(key string, generation int64, now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.states[key]
+	if ok && state.generation != generation {
+		delete(b.states, key)
+		return true, 0
+	}
+	if !ok || state.openUntil.IsZero() || !now.Before(state.openUntil) {
+		return true, 0
+	}
+	return false, state.openUntil.Sub(now)
+}
+
+// failure records a failed fetch and returns true when the breaker is (re)opened by it.
+func (b *sourceBreaker) failure(key string, generation int64, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.states[key]
+	if !ok || state.generation != generation {
+		state = &breakerState{generation: generation}
+		b.states[key] = state
+	}
+	state.failures++
+	if state.failures < b.threshold {
+		return false
+	}
+	state.openUntil = now.Add(b.backoff)
+	return true
+}
+
+// success closes the breaker and forgets the failures.
+func (b *sourceBreaker) success(key string) {
+	b.forget(key)
+}
+
+// forget drops the state of a deleted propagation, so a new one with the same name starts closed.
+func (b *sourceBreaker) forget(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.states, key)
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// prepareSourceData returns the source Data as it should land in the targets: keys not matching
+// the KeyFormat or the key filters are dropped, the ValueTransforms are applied and the keys are
+// renamed by the KeyTransform. The encoded keys are returned as well, under their target names.
+func prepareSourceData(cmp *syncv1alpha1.ConfigMapPropagation, src *corev1.ConfigMap) (map[string]string, []string, error) {
+	data, _ := filterKeyFormat(cmp.Spec.KeyFormat, src.Data)
+	transformed, encodedKeys, err := applyValueTransforms(cmp, filterKeys(cmp, data))
+	if err != nil {
+		return nil, nil, err
+	}
+	binaryData, _ := filterKeyFormat(cmp.Spec.KeyFormat, src.BinaryData)
+	renamed, _, err := transformKeys(cmp.Spec.KeyTransform, transformed, filterKeys(cmp, binaryData))
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, k := range encodedKeys {
+		encodedKeys[i] = targetKey(cmp.Spec.KeyTransform, k)
+	}
+	sort.Strings(encodedKeys)
+	return renamed, encodedKeys, nil
+}
+
+// prepareSourceBinaryData returns the source BinaryData as it should land in the targets.
+func prepareSourceBinaryData(cmp *syncv1alpha1.ConfigMapPropagation, src *corev1.ConfigMap) (map[string][]byte, error) {
+	data, _ := filterKeyFormat(cmp.Spec.KeyFormat, src.Data)
+	binaryData, _ := filterKeyFormat(cmp.Spec.KeyFormat, src.BinaryData)
+	_, renamed, err := transformKeys(cmp.Spec.KeyTransform, filterKeys(cmp, data), filterKeys(cmp, binaryData))
+	return renamed, err
+}
+
+// KeyCollisionError is returned when the KeyTransform maps two source keys to the same target key.
+type KeyCollisionError struct {
+	Key     string
+	Sources []string
+}
+
+func (e *KeyCollisionError) Error() string {
+	return fmt.Sprintf("source keys %s are all propagated as %q", strings.Join(e.Sources, ", "), e.Key)
+}
+
+// targetKey returns the key a source key is propagated under.
+func targetKey(t *syncv1alpha1.KeyTransform, key string) string {
+	if t == nil {
+		return key
+	}
+	if to, ok := t.Renames[key]; ok {
+		key = to
+	}
+	return t.Prefix + key
+}
+
+// transformKeys renames the Data and BinaryData keys with the KeyTransform. Both share the key space
+// of the target, so a collision between them is reported as well.
+func transformKeys(t *syncv1alpha1.KeyTransform, data map[string]string, binaryData map[string][]byte) (map[string]string, map[string][]byte, error) {
+	if t == nil {
+		return data, binaryData, nil
+	}
+	sources := make(map[string][]string, len(data)+len(binaryData))
+	renamedData := make(map[string]string, len(data))
+	for k, v := range data {
+		to := targetKey(t, k)
+		sources[to] = append(sources[to], k)
+		renamedData[to] = v
+	}
+	var renamedBinaryData map[string][]byte
+	if binaryData != nil {
+		renamedBinaryData = make(map[string][]byte, len(binaryData))
+	}
+	for k, v := range binaryData {
+		to := targetKey(t, k)
+		sources[to] = append(sources[to], k)
+		renamedBinaryData[to] = v
+	}
+	collisions := make([]string, 0)
+	for to, from := range sources {
+		if len(from) > 1 {
+			collisions = append(collisions, to)
+		}
+	}
+	if len(collisions) > 0 {
+		sort.Strings(collisions)
+		from := sources[collisions[0]]
+		sort.Strings(from)
+		return nil, nil, &KeyCollisionError{Key: collisions[0], Sources: from}
+	}
+	return renamedData, renamedBinaryData, nil
+}
+
+// filterKeys keeps only the IncludeKeys, when set, and then drops the ExcludeKeys.
+func filterKeys[V any](cmp *syncv1alpha1.ConfigMapPropagation, data map[string]V) map[string]V {
+	if len(cmp.Spec.IncludeKeys) == 0 && len(cmp.Spec.ExcludeKeys) == 0 {
+		return data
+	}
+	kept := make(map[string]V, len(data))
+	for k, v := range data {
+		if cmp.Spec.KeyFilteredOut(k) {
+			continue
+		}
+		kept[k] = v
+	}
+	return kept
+}
+
+// validateKeyFilters rejects keys listed in both IncludeKeys and ExcludeKeys, and KeyTransform renames of
+// source keys the filters drop, which would never be propagated. The CRD and the webhook refuse them
+// already, this covers objects stored before the rules existed.
+func validateKeyFilters(cmp *syncv1alpha1.ConfigMapPropagation) error {
+	for _, k := range cmp.Spec.IncludeKeys {
+		if slices.Contains(cmp.Spec.ExcludeKeys, k) {
+			return fmt.Errorf("key %q is listed in both includeKeys and excludeKeys", k)
+		}
+	}
+	if cmp.Spec.KeyTransform == nil {
+		return nil
+	}
+	for _, k := range slices.Sorted(maps.Keys(cmp.Spec.KeyTransform.Renames)) {
+		if cmp.Spec.KeyFilteredOut(k) {
+			return fmt.Errorf("keyTransform renames %q, which includeKeys or excludeKeys drop", k)
+		}
+	}
+	return nil
+}
+
+// setKeysSkippedCondition reports the source keys that don't match the KeyFormat. They are the same for
+// every target, so they are reported once on the propagation instead of per target.
+func setKeysSkippedCondition(cmp *syncv1alpha1.ConfigMapPropagation, source *corev1.ConfigMap, generation int64) {
+	_, skipped := filterKeyFormat(cmp.Spec.KeyFormat, source.Data)
+	_, skippedBinary := filterKeyFormat(cmp.Spec.KeyFormat, source.BinaryData)
+	if skipped = append(skipped, skippedBinary...); len(skipped) == 0 {
+		meta.RemoveStatusCondition(&cmp.Status.Conditions, ConditionTypeKeysSkipped)
+		return
+	}
+	meta.SetStatusCondition(&cmp.Status.Conditions, metav1.Condition{
+		Type:               ConditionTypeKeysSkipped,
+		Status:             metav1.ConditionTrue,
+		Reason:             "KeyFormatMismatch",
+		Message:            fmt.Sprintf("Keys not matching keyFormat %s were not propagated: %s", cmp.Spec.KeyFormat, strings.Join(skipped, ",")),
+		ObservedGeneration: generation,
+	})
+}
+
+// filterKeyFormat returns the entries whose key matches format, along with the sorted skipped keys.
+func filterKeyFormat[V any](format syncv1alpha1.KeyFormat, data map[string]V) (map[string]V, []string) {
+	kept := make(map[string]V, len(data))
+	skipped := make([]string, 0)
+	for k, v := range data {
+		if !matchesKeyFormat(format, k) {
+			skipped = append(skipped, k)
+			continue
+		}
+		kept[k] = v
+	}
+	sort.Strings(skipped)
+	return kept, skipped
+}
+
+func matchesKeyFormat(format syncv1alpha1.KeyFormat, key string) bool {
+	switch format {
+	case syncv1alpha1.KeyFormatEnvSafe:
+		return len(validation.IsCIdentifier(key)) == 0
+	case syncv1alpha1.KeyFormatFileSafe:
+		return len(validation.IsConfigMapKey(key)) == 0
+	default:
+		return true
+	}
+}
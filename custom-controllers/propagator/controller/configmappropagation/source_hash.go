@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// sourceRevisionHash hashes everything a target is derived from: the source data after the key filters
+// and transforms, the copied source metadata and the spec fields deciding how the target is written.
+// It is computed once per sync and compared to the SourceHashAnnotation of every target.
+func sourceRevisionHash(cmp *syncv1alpha1.ConfigMapPropagation, src *corev1.ConfigMap) (string, error) {
+	data, err := sourceDataHash(cmp, src)
+	if err != nil {
+		return "", err
+	}
+	preserved := slices.Clone(cmp.Spec.PreserveTargetKeys)
+	slices.Sort(preserved)
+	protected := slices.Clone(cmp.Spec.ProtectedKeys)
+	slices.Sort(protected)
+	entries := map[string]string{
+		"data":                data,
+		"propagationPolicy":   string(cmp.Spec.PropagationPolicy),
+		"preserveTargetKeys":  strings.Join(preserved, ","),
+		"protectedKeys":       strings.Join(protected, ","),
+		"annotateManagedKeys": strconv.FormatBool(cmp.Spec.AnnotateManagedKeys),
+		"targetTTL":           strconv.FormatBool(cmp.Spec.TargetTTL != nil),
+		"copyLabels":          strings.Join(cmp.Spec.CopyLabels, ","),
+		"copyAnnotations":     strings.Join(cmp.Spec.CopyAnnotations, ","),
+		"serverSideApply":     strconv.FormatBool(cmp.Spec.ServerSideApply != nil),
+	}
+	// Only added when set, so the hash of every other propagation stays the same
+	if immutableFromSource(cmp, src) {
+		entries["immutable"] = "true"
+	}
+	if cmp.Spec.EnableTemplating {
+		entries["templating"] = "true"
+	}
+	if prunesRemovedSourceKeys(cmp) {
+		entries["pruneRemovedSourceKeys"] = "true"
+	}
+	labels, annotations := copiedMetadata(cmp, src)
+	for k, v := range labels {
+		entries["label/"+k] = v
+	}
+	for k, v := range annotations {
+		entries["annotation/"+k] = v
+	}
+	return dataHash(entries, nil), nil
+}
+
+// targetUpToDate reports whether the target was written from the current source hash and left alone since,
+// in which case updating it would not change anything.
+func (r *ConfigMapPropagationReconciler) targetUpToDate(cmp *syncv1alpha1.ConfigMapPropagation, target *corev1.ConfigMap, sourceHash string) bool {
+	if sourceHash == "" || target.Annotations[SourceHashAnnotation] != sourceHash {
+		return false
+	}
+	if _, ok := target.Annotations[AppliedHashAnnotation]; !ok || editedOutOfBand(target) {
+		return false
+	}
+	if ttl := cmp.Spec.TargetTTL; ttl != nil && ttl.Duration > 0 && targetExpired(target, r.now()) {
+		return false
+	}
+	return true
+}
+
+// setSourceHashAnnotation stamps the source hash on the annotations and reports whether they changed.
+func setSourceHashAnnotation(annotations map[string]string, sourceHash string) bool {
+	if sourceHash == "" || annotations[SourceHashAnnotation] == sourceHash {
+		return false
+	}
+	annotations[SourceHashAnnotation] = sourceHash
+	return true
+}
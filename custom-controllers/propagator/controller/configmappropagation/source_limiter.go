@@ -0,0 +1,44 @@
+package controller
+
+import "sync"
+
+// sourceLimiter bounds how many propagations sharing a source ConfigMap sync at the same time,
+// so a change to a popular source does not fan out into every propagation at once.
+type sourceLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight map[string]int
+}
+
+func newSourceLimiter(limit int) *sourceLimiter {
+	return &sourceLimiter{limit: limit, inFlight: map[string]int{}}
+}
+
+// tryAcquire takes a slot for the source key, returning false when all slots are taken.
+// A limit of zero or less never blocks.
+func (l *sourceLimiter) tryAcquire(key string) bool {
+	if l == nil || l.limit <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight[key] >= l.limit {
+		return false
+	}
+	l.inFlight[key]++
+	return true
+}
+
+// release frees a slot taken by tryAcquire.
+func (l *sourceLimiter) release(key string) {
+	if l == nil || l.limit <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight[key] <= 1 {
+		delete(l.inFlight, key)
+		return
+	}
+	l.inFlight[key]--
+}
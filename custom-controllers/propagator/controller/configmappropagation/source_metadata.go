@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// copyAllKeys selects every source label or annotation in CopyLabels and CopyAnnotations
+const copyAllKeys = "*"
+
+// lastAppliedAnnotation is written by kubectl apply and describes the source, not the target
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// copiedMetadata returns the source labels and annotations selected by CopyLabels and CopyAnnotations.
+func copiedMetadata(cmp *syncv1alpha1.ConfigMapPropagation, src *corev1.ConfigMap) (map[string]string, map[string]string) {
+	return selectMetadata(cmp.Spec.CopyLabels, src.Labels), selectMetadata(cmp.Spec.CopyAnnotations, src.Annotations)
+}
+
+func selectMetadata(keys []string, from map[string]string) map[string]string {
+	selected := make(map[string]string)
+	all := slices.Contains(keys, copyAllKeys)
+	for k, v := range from {
+		if controllerOwnedKey(k) || (all && k == lastAppliedAnnotation) {
+			continue
+		}
+		if all || slices.Contains(keys, k) {
+			selected[k] = v
+		}
+	}
+	return selected
+}
+
+// controllerOwnedKey reports whether the label or annotation key belongs to the controller,
+// those are never copied from the source so the ownership of the target is kept.
+func controllerOwnedKey(key string) bool {
+	return strings.HasPrefix(key, "sync.propagators.io/")
+}
+
+// setCopiedMetadata writes the copied labels and annotations on the target and removes the explicitly
+// listed keys the source no longer carries. Keys only reached through "*" are never removed, they can't
+// be told apart from the target's own. It reports whether the target changed.
+func setCopiedMetadata(cmp *syncv1alpha1.ConfigMapPropagation, target metav1.Object, labels, annotations map[string]string) bool {
+	targetLabels, labelsChanged := mergeCopied(cmp.Spec.CopyLabels, target.GetLabels(), labels)
+	targetAnnotations, annotationsChanged := mergeCopied(cmp.Spec.CopyAnnotations, target.GetAnnotations(), annotations)
+	target.SetLabels(targetLabels)
+	target.SetAnnotations(targetAnnotations)
+	return labelsChanged || annotationsChanged
+}
+
+func mergeCopied(keys []string, target, copied map[string]string) (map[string]string, bool) {
+	if len(keys) == 0 {
+		return target, false
+	}
+	if target == nil {
+		target = map[string]string{}
+	}
+	changed := false
+	for _, k := range keys {
+		if _, ok := copied[k]; ok || k == copyAllKeys || controllerOwnedKey(k) {
+			continue
+		}
+		if _, ok := target[k]; ok {
+			delete(target, k)
+			changed = true
+		}
+	}
+	for k, v := range copied {
+		if target[k] != v {
+			target[k] = v
+			changed = true
+		}
+	}
+	return target, changed
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestConfigMapPropagation runs the unit test suite for the ConfigMapPropagation controller.
+// The reconciler is exercised against a fake client, so no cluster or envtest binaries are needed.
+func TestConfigMapPropagation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ConfigMapPropagation controller suite")
+}
+
+// newTestReconciler returns a reconciler backed by a fake client seeded with objs.
+func newTestReconciler(objs ...client.Object) *ConfigMapPropagationReconciler {
+	scheme, err := testutil.NewScheme()
+	Expect(err).NotTo(HaveOccurred())
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&syncv1alpha1.ConfigMapPropagation{}).
+		Build()
+
+	return &ConfigMapPropagationReconciler{
+		Client:   c,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
+	}
+}
+
+func newConfigMap(ns, name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Data:       data,
+	}
+}
+
+func newPropagation(name string, spec syncv1alpha1.ConfigMapPropagationSpec) *syncv1alpha1.ConfigMapPropagation {
+	return &syncv1alpha1.ConfigMapPropagation{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID("uid-" + name), Generation: 1},
+		Spec:       spec,
+	}
+}
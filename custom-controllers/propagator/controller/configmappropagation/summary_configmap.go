@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// summaryConfigMapKey returns the namespace and name of the summary ConfigMap of a propagation.
+func summaryConfigMapKey(configmapPropagator *syncv1alpha1.ConfigMapPropagation) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: configmapPropagator.Spec.Source.NamespaceOrDefault(),
+		Name:      configmapPropagator.Name + "-propagation-summary",
+	}
+}
+
+// writeSummaryConfigMap records every managed target as a "<namespace>.<name>" key holding its sync state.
+// Targets without a failed or drifted TargetStatus are reported as Synced. The summary is removed when
+// WriteSummaryConfigMap is turned off.
+func (r *ConfigMapPropagationReconciler) writeSummaryConfigMap(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation, targetStatuses []syncv1alpha1.TargetStatus) error {
+	key := summaryConfigMapKey(configmapPropagator)
+	if !configmapPropagator.Spec.WriteSummaryConfigMap {
+		return r.deleteSummaryConfigMap(ctx, configmapPropagator)
+	}
+
+	targets, err := r.getCurrentTargets(ctx, configmapPropagator)
+	if err != nil {
+		return err
+	}
+	states := make(map[string]string, len(targetStatuses))
+	for _, t := range targetStatuses {
+		states[t.Namespace+"."+t.Name] = t.State
+	}
+	data := make(map[string]string, len(targets))
+	for _, t := range targets {
+		entry := t.Namespace + "." + t.ConfigmapName
+		if state, ok := states[entry]; ok {
+			data[entry] = state
+		} else {
+			data[entry] = "Synced"
+		}
+	}
+
+	summary := &corev1.ConfigMap{}
+	err = r.Get(ctx, key, summary)
+	if apierrors.IsNotFound(err) {
+		summary = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels: map[string]string{
+					SummaryOfLabelKey: configmapPropagator.Name,
+					ManagedByLabelKey: ManagedByLabelValue,
+				},
+			},
+			Data: data,
+		}
+		if err := r.Create(ctx, summary); err != nil {
+			return fmt.Errorf("failed to create summary configmap %s: %w", key, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if summary.Labels[SummaryOfLabelKey] != configmapPropagator.Name {
+		return fmt.Errorf("configmap %s exists and is not the summary of %s", key, configmapPropagator.Name)
+	}
+	if equality.Semantic.DeepEqual(summary.Data, data) {
+		return nil
+	}
+	summary.Data = data
+	if err := r.Update(ctx, summary); err != nil {
+		return fmt.Errorf("failed to update summary configmap %s: %w", key, err)
+	}
+	return nil
+}
+
+// deleteSummaryConfigMap removes the summary ConfigMap if it was written for this propagation.
+func (r *ConfigMapPropagationReconciler) deleteSummaryConfigMap(ctx context.Context, configmapPropagator *syncv1alpha1.ConfigMapPropagation) error {
+	summary := &corev1.ConfigMap{}
+	if err := r.Get(ctx, summaryConfigMapKey(configmapPropagator), summary); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if summary.Labels[SummaryOfLabelKey] != configmapPropagator.Name {
+		return nil
+	}
+	return r.Delete(ctx, summary)
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+)
+
+// targetBackoff tracks the consecutive write failures of each target in memory and holds a failing
+// target back for an exponentially growing delay, so one broken namespace is not retried, and does not
+// emit events, on every reconcile while the healthy targets keep syncing.
+type targetBackoff struct {
+	mu     sync.Mutex
+	base   time.Duration
+	max    time.Duration
+	states map[string]*targetBackoffState
+}
+
+type targetBackoffState struct {
+	failures int
+	retryAt  time.Time
+}
+
+func newTargetBackoff(base, max time.Duration) *targetBackoff {
+	if base <= 0 {
+		base = defaultTargetBackoffBase
+	}
+	if max < base {
+		max = defaultTargetBackoffMax
+	}
+	return &targetBackoff{base: base, max: max, states: map[string]*targetBackoffState{}}
+}
+
+// targetBackoffKey keys a target by the UID of its propagation, so a recreated propagation starts over.
+func targetBackoffKey(configmapPropagator *syncv1alpha1.ConfigMapPropagation, t *PropagatorTarget) string {
+	return string(configmapPropagator.UID) + "/" + t.Namespace + "/" + t.ConfigmapName
+}
+
+// wait reports whether the target is backing off at now, with the time left and its consecutive failures.
+func (b *targetBackoff) wait(key string, now time.Time) (time.Duration, int, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.states[key]
+	if !ok || !now.Before(state.retryAt) {
+		return 0, 0, false
+	}
+	return state.retryAt.Sub(now), state.failures, true
+}
+
+// failure records a failed write and returns the delay before the target is tried again,
+// base doubled for every consecutive failure and capped at max.
+func (b *targetBackoff) failure(key string, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.states[key]
+	if !ok {
+		state = &targetBackoffState{}
+		b.states[key] = state
+	}
+	state.failures++
+	delay := b.base
+	for i := 1; i < state.failures && delay < b.max; i++ {
+		delay *= 2
+	}
+	delay = min(delay, b.max)
+	state.retryAt = now.Add(delay)
+	return delay
+}
+
+// success forgets the failures of the target.
+func (b *targetBackoff) success(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.states, key)
+}
+
+// forget drops the state of every target of a deleted propagation.
+func (b *targetBackoff) forget(configmapPropagator *syncv1alpha1.ConfigMapPropagation) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	prefix := string(configmapPropagator.UID) + "/"
+	for key := range b.states {
+		if strings.HasPrefix(key, prefix) {
+			delete(b.states, key)
+		}
+	}
+}
+
+// earliestRetry returns the shorter of two requeue delays, ignoring zero.
+func earliestRetry(current, next time.Duration) time.Duration {
+	if current == 0 || (next > 0 && next < current) {
+		return next
+	}
+	return current
+}
@@ -0,0 +1,14 @@
+package controller
+
+import (
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// targetEvent records an event with the target ConfigMap as involvedObject when TargetEvents is set.
+func (r *ConfigMapPropagationReconciler) targetEvent(cmp *syncv1alpha1.ConfigMapPropagation, target *corev1.ConfigMap, eventtype, reason, messageFmt string, args ...interface{}) {
+	if !cmp.Spec.TargetEvents {
+		return
+	}
+	r.Recorder.Eventf(target, eventtype, reason, messageFmt, args...)
+}
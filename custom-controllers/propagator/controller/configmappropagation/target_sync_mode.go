@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"time"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// hasSyncOverride reports whether the target sets its own SyncMode or SyncInterval.
+func (t *PropagatorTarget) hasSyncOverride() bool {
+	return t.SyncMode != "" || t.SyncInterval != nil
+}
+
+// propagationDue reports whether the targets following the propagation-wide SyncMode must be refreshed,
+// either because the SyncMode says so, because the source data changed in OnChange mode or because
+// a target outlived its TTL.
+func (r *ConfigMapPropagationReconciler) propagationDue(configmapPropagator *syncv1alpha1.ConfigMapPropagation, source *corev1.ConfigMap, targets *propagationTargets) bool {
+	mode, interval := r.syncMode(configmapPropagator), r.periodicInterval(configmapPropagator)
+	if shouldRefresh(configmapPropagator, mode, interval, r.now()) {
+		return true
+	}
+	// Periodic with a zero interval follows source changes like OnChange
+	onChange := mode == syncv1alpha1.SyncModeOnChange || (mode == syncv1alpha1.SyncModePeriodic && interval == 0)
+	if onChange && source != nil {
+		// The revision hash is the data the targets were last synced with
+		// Data that can't be prepared is synced anyway, so every target reports why it failed
+		hash, err := sourceDataHash(configmapPropagator, source)
+		if err != nil || hash != configmapPropagator.Status.RevisionHash {
+			return true
+		}
+	}
+	// Targets past their TTL are refreshed even when the sync mode would skip this reconcile
+	return r.hasExpiredTargets(configmapPropagator, targets.labeled)
+}
+
+// targetSyncSettings returns the SyncMode and clamped SyncInterval that apply to a target.
+func (r *ConfigMapPropagationReconciler) targetSyncSettings(configmapPropagator *syncv1alpha1.ConfigMapPropagation, t *PropagatorTarget) (syncv1alpha1.SyncMode, time.Duration) {
+	mode := t.SyncMode
+	if mode == "" {
+		mode = r.syncMode(configmapPropagator)
+	}
+	interval := r.syncInterval(configmapPropagator)
+	if t.SyncInterval != nil {
+		interval = r.clampSyncInterval(t.SyncInterval.Duration)
+	}
+	return mode, interval
+}
+
+// lastTargetSync returns when an overriding target was last synced, zero if it never was.
+func lastTargetSync(configmapPropagator *syncv1alpha1.ConfigMapPropagation, t *PropagatorTarget) metav1.Time {
+	for _, record := range configmapPropagator.Status.TargetSyncTimes {
+		if record.Namespace == t.Namespace && record.Name == t.ConfigmapName {
+			return record.LastSyncedAt
+		}
+	}
+	return metav1.Time{}
+}
+
+// targetDue reports whether an existing target must be updated in this reconcile. Targets without
+// overrides follow the propagation (due), the others are evaluated on their own schedule.
+func (r *ConfigMapPropagationReconciler) targetDue(configmapPropagator *syncv1alpha1.ConfigMapPropagation, t *PropagatorTarget, due bool) bool {
+	if !t.hasSyncOverride() {
+		return due
+	}
+	mode, interval := r.targetSyncSettings(configmapPropagator, t)
+	last := lastTargetSync(configmapPropagator, t)
+	specChanged := !generationSynced(configmapPropagator)
+	switch mode {
+	case syncv1alpha1.SyncModeCreatedOnce:
+		return last.IsZero()
+	case syncv1alpha1.SyncModeOnChange:
+		return specChanged || last.IsZero()
+	case syncv1alpha1.SyncModePeriodic:
+		return specChanged || last.IsZero() || (interval > 0 && !last.Add(interval).After(r.now()))
+	default:
+		return false
+	}
+}
+
+// overrideTargetsDue reports whether any target with its own SyncMode or SyncInterval must be refreshed.
+func (r *ConfigMapPropagationReconciler) overrideTargetsDue(configmapPropagator *syncv1alpha1.ConfigMapPropagation, desired []*PropagatorTarget) bool {
+	for _, t := range desired {
+		if t.hasSyncOverride() && r.targetDue(configmapPropagator, t, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// withOverrideRequeue makes sure the next Periodic override target is refreshed on time.
+func (r *ConfigMapPropagationReconciler) withOverrideRequeue(configmapPropagator *syncv1alpha1.ConfigMapPropagation, result ctrl.Result) ctrl.Result {
+	for _, ref := range configmapPropagator.Spec.Targets {
+		if ref.SyncMode == "" && ref.SyncInterval == nil {
+			continue
+		}
+		t := &PropagatorTarget{Namespace: ref.Namespace, ConfigmapName: ref.Name, SyncMode: ref.SyncMode, SyncInterval: ref.SyncInterval}
+		if t.ConfigmapName == "" {
+			t.ConfigmapName = configmapPropagator.Spec.Source.Name
+		}
+		mode, interval := r.targetSyncSettings(configmapPropagator, t)
+		if mode != syncv1alpha1.SyncModePeriodic || interval == 0 {
+			continue
+		}
+		wait := interval
+		if last := lastTargetSync(configmapPropagator, t); !last.IsZero() {
+			wait = last.Add(interval).Sub(r.now())
+		}
+		if wait <= 0 {
+			wait = time.Second
+		}
+		if result.RequeueAfter == 0 || wait < result.RequeueAfter {
+			result.RequeueAfter = wait
+		}
+	}
+	return result
+}
+
+// targetSyncTimes records the sync time of the overriding targets synced now and keeps the
+// previous record of the ones that were not, dropping targets that are no longer desired.
+func (r *ConfigMapPropagationReconciler) targetSyncTimes(configmapPropagator *syncv1alpha1.ConfigMapPropagation, desired, synced []*PropagatorTarget) []syncv1alpha1.TargetSyncTime {
+	syncedNow := make(map[string]struct{}, len(synced))
+	for _, t := range synced {
+		syncedNow[t.Namespace+"/"+t.ConfigmapName] = struct{}{}
+	}
+	now := metav1.NewTime(r.now())
+	records := make([]syncv1alpha1.TargetSyncTime, 0)
+	for _, t := range desired {
+		if !t.hasSyncOverride() {
+			continue
+		}
+		last := lastTargetSync(configmapPropagator, t)
+		if _, ok := syncedNow[t.Namespace+"/"+t.ConfigmapName]; ok {
+			last = now
+		}
+		if last.IsZero() {
+			continue
+		}
+		records = append(records, syncv1alpha1.TargetSyncTime{Namespace: t.Namespace, Name: t.ConfigmapName, LastSyncedAt: last})
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return records
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"time"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// now returns the current time from the reconciler clock, defaulting to the wall clock for reconcilers
+// that were not set up by SetupWithManager.
+func (r *ConfigMapPropagationReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
+}
+
+// targetExpired reports whether a target's expiry annotation is missing, unparsable or in the past.
+func targetExpired(target *corev1.ConfigMap, now time.Time) bool {
+	expiresAt, err := time.Parse(time.RFC3339, target.Annotations[ExpiresAtAnnotation])
+	if err != nil {
+		return true
+	}
+	return !now.Before(expiresAt)
+}
+
+// stampExpiry sets the expiry annotation of a target to now plus TargetTTL. It returns whether the
+// annotations changed, which only happens when the TTL is set and the previous expiry has passed.
+func (r *ConfigMapPropagationReconciler) stampExpiry(configmapPropagator *syncv1alpha1.ConfigMapPropagation, target *corev1.ConfigMap) bool {
+	ttl := configmapPropagator.Spec.TargetTTL
+	if ttl == nil || ttl.Duration <= 0 {
+		if _, ok := target.Annotations[ExpiresAtAnnotation]; ok {
+			delete(target.Annotations, ExpiresAtAnnotation)
+			return true
+		}
+		return false
+	}
+	now := r.now()
+	if !targetExpired(target, now) {
+		return false
+	}
+	target.Annotations[ExpiresAtAnnotation] = now.Add(ttl.Duration).UTC().Format(time.RFC3339)
+	return true
+}
+
+// withTTLRequeue makes sure a propagation with TargetTTL is reconciled again within one TTL.
+func withTTLRequeue(configmapPropagator *syncv1alpha1.ConfigMapPropagation, result ctrl.Result) ctrl.Result {
+	ttl := configmapPropagator.Spec.TargetTTL
+	if ttl == nil || ttl.Duration <= 0 {
+		return result
+	}
+	if result.RequeueAfter == 0 || result.RequeueAfter > ttl.Duration {
+		result.RequeueAfter = ttl.Duration
+	}
+	return result
+}
+
+// hasExpiredTargets reports whether any managed target has outlived TargetTTL and must be refreshed.
+// labeled are the ConfigMaps carrying the owner label of the propagation.
+func (r *ConfigMapPropagationReconciler) hasExpiredTargets(configmapPropagator *syncv1alpha1.ConfigMapPropagation, labeled []corev1.ConfigMap) bool {
+	if ttl := configmapPropagator.Spec.TargetTTL; ttl == nil || ttl.Duration <= 0 {
+		return false
+	}
+	now := r.now()
+	for i := range labeled {
+		if ownership.IsOwnedBy(&labeled[i], configmapPropagator) && targetExpired(&labeled[i], now) {
+			return true
+		}
+	}
+	return false
+}
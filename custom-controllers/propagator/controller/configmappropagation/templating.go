@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// templateContext is what a source value sees when it is rendered for a target.
+type templateContext struct {
+	Namespace  string
+	TargetName string
+	SourceName string
+}
+
+// TemplateError is returned when a source value can't be rendered for a target.
+type TemplateError struct {
+	Namespace string
+	Name      string
+	Key       string
+	Err       error
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("failed to render key %q for %s/%s: %v", e.Key, e.Namespace, e.Name, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// renderTemplates returns the source with its Data values rendered for the target when EnableTemplating
+// is set, the source itself otherwise. Values are rendered before any ValueTransform, so encoded keys
+// hold the rendered value.
+func renderTemplates(cmp *syncv1alpha1.ConfigMapPropagation, t *PropagatorTarget, src *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	if !cmp.Spec.EnableTemplating {
+		return src, nil
+	}
+	data := templateContext{Namespace: t.Namespace, TargetName: t.ConfigmapName, SourceName: src.Name}
+	rendered := src.DeepCopy()
+	for k, v := range src.Data {
+		tmpl, err := template.New(k).Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, &TemplateError{Namespace: t.Namespace, Name: t.ConfigmapName, Key: k, Err: err}
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, data); err != nil {
+			return nil, &TemplateError{Namespace: t.Namespace, Name: t.ConfigmapName, Key: k, Err: err}
+		}
+		rendered.Data[k] = out.String()
+	}
+	return rendered, nil
+}
+
+// templateStatus reports a target that failed because a source value could not be rendered for it.
+func templateStatus(t *PropagatorTarget, templateErr *TemplateError) syncv1alpha1.TargetStatus {
+	return syncv1alpha1.TargetStatus{
+		Namespace: t.Namespace,
+		Name:      t.ConfigmapName,
+		State:     "Failed",
+		Reason:    "TemplateError",
+		Message:   templateErr.Error(),
+	}
+}
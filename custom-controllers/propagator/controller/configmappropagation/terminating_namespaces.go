@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// terminatingNamespaces returns the namespaces of the targets that are being deleted. Their ConfigMaps
+// are garbage collected with the namespace and creating new ones is refused by the API server.
+// Namespaces that don't exist are left to the create, which reports them through namespaceGoneStatus.
+func (r *ConfigMapPropagationReconciler) terminatingNamespaces(ctx context.Context, targets ...[]*PropagatorTarget) (map[string]struct{}, error) {
+	terminating := make(map[string]struct{})
+	checked := make(map[string]struct{})
+	for _, list := range targets {
+		for _, t := range list {
+			if _, ok := checked[t.Namespace]; ok {
+				continue
+			}
+			checked[t.Namespace] = struct{}{}
+			ns := &corev1.Namespace{}
+			if err := r.Get(ctx, types.NamespacedName{Name: t.Namespace}, ns); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to get target namespace %s: %w", t.Namespace, err)
+			}
+			if ns.Status.Phase == corev1.NamespaceTerminating || !ns.DeletionTimestamp.IsZero() {
+				terminating[t.Namespace] = struct{}{}
+			}
+		}
+	}
+	return terminating, nil
+}
+
+// withoutTerminating splits off the targets in a terminating namespace.
+func withoutTerminating(targets []*PropagatorTarget, terminating map[string]struct{}) ([]*PropagatorTarget, []*PropagatorTarget) {
+	kept := make([]*PropagatorTarget, 0, len(targets))
+	skipped := make([]*PropagatorTarget, 0)
+	for _, t := range targets {
+		if _, ok := terminating[t.Namespace]; ok {
+			skipped = append(skipped, t)
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept, skipped
+}
+
+// namespaceGoneStatus returns the Skipped status of a target whose create failed because its namespace
+// is terminating or doesn't exist, and whether err is such a failure.
+func namespaceGoneStatus(t *PropagatorTarget, err error) (syncv1alpha1.TargetStatus, bool) {
+	switch {
+	case apierrors.HasStatusCause(err, corev1.NamespaceTerminatingCause):
+		return terminatingStatus(t), true
+	case namespaceNotFound(err, t.Namespace):
+		return syncv1alpha1.TargetStatus{
+			Namespace: t.Namespace,
+			Name:      t.ConfigmapName,
+			State:     "Skipped",
+			Reason:    "NamespaceNotFound",
+			Message:   fmt.Sprintf("namespace %s does not exist", t.Namespace),
+		}, true
+	}
+	return syncv1alpha1.TargetStatus{}, false
+}
+
+// namespaceNotFound reports whether err is the API server refusing a create because namespace doesn't exist.
+func namespaceNotFound(err error, namespace string) bool {
+	var status apierrors.APIStatus
+	if !apierrors.IsNotFound(err) || !errors.As(err, &status) {
+		return false
+	}
+	details := status.Status().Details
+	return details != nil && details.Kind == "namespaces" && details.Name == namespace
+}
+
+// terminatingStatus reports a target that was skipped because its namespace is being deleted.
+func terminatingStatus(t *PropagatorTarget) syncv1alpha1.TargetStatus {
+	return syncv1alpha1.TargetStatus{
+		Namespace: t.Namespace,
+		Name:      t.ConfigmapName,
+		State:     "Skipped",
+		Reason:    "NamespaceTerminating",
+		Message:   fmt.Sprintf("namespace %s is terminating", t.Namespace),
+	}
+}
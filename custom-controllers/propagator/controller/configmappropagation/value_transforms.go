@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+)
+
+// applyValueTransforms returns a copy of the source data with the spec's ValueTransforms applied,
+// along with the sorted list of keys that ended up gzip+base64 encoded.
+func applyValueTransforms(cmp *syncv1alpha1.ConfigMapPropagation, data map[string]string) (map[string]string, []string, error) {
+	transformed := make(map[string]string, len(data))
+	for k, v := range data {
+		transformed[k] = v
+	}
+
+	encodedKeys := make([]string, 0)
+	for _, t := range cmp.Spec.ValueTransforms {
+		value, ok := transformed[t.Key]
+		if !ok {
+			continue
+		}
+		switch t.Operation {
+		case syncv1alpha1.ValueTransformGzipBase64:
+			encoded, err := gzipBase64(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to encode key %q: %w", t.Key, err)
+			}
+			transformed[t.Key] = encoded
+			encodedKeys = append(encodedKeys, t.Key)
+		default:
+			return nil, nil, fmt.Errorf("unsupported value transform %q for key %q", t.Operation, t.Key)
+		}
+	}
+	sort.Strings(encodedKeys)
+	return transformed, encodedKeys, nil
+}
+
+// gzipBase64 compresses value and base64 encodes the result. The gzip header carries no
+// timestamp, so the same input always produces the same output and doesn't look like drift.
+func gzipBase64(value string) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(value)); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// setEncodedKeysAnnotation records the encoded keys on the target annotations,
+// removing the annotation when nothing is encoded. It reports whether the annotations changed.
+func setEncodedKeysAnnotation(annotations map[string]string, encodedKeys []string) bool {
+	current, exists := annotations[GzipBase64KeysAnnotation]
+	if len(encodedKeys) == 0 {
+		if !exists {
+			return false
+		}
+		delete(annotations, GzipBase64KeysAnnotation)
+		return true
+	}
+	desired := strings.Join(encodedKeys, ",")
+	if exists && current == desired {
+		return false
+	}
+	annotations[GzipBase64KeysAnnotation] = desired
+	return true
+}
@@ -1,6 +1,11 @@
 package controller
 
-import "errors"
+import (
+	"errors"
+	"time"
+
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
+)
 
 var defaultSystemNamespaces = map[string]struct{}{
 	"kube-system":     {},
@@ -8,12 +13,93 @@ var defaultSystemNamespaces = map[string]struct{}{
 	"kube-node-lease": {},
 }
 
+const (
+	// defaultSyncInterval is used when a Periodic propagation has no SyncInterval set
+	defaultSyncInterval = 5 * time.Minute
+	// DefaultMinSyncInterval is the default lower bound for SyncInterval in Periodic mode
+	DefaultMinSyncInterval = 30 * time.Second
+	// DefaultSyncJitter is the default fraction of the SyncInterval Periodic propagations are spread by
+	DefaultSyncJitter = 0.1
+	// batchRequeueDelay is the delay before the next batch when BatchSize is set
+	batchRequeueDelay = 2 * time.Second
+	// sourceBusyRequeueDelay is the delay before retrying a propagation whose source has no free slot
+	sourceBusyRequeueDelay = time.Second
+	// defaultHashedTargetGracePeriod is how long superseded hash suffixed targets are kept by default
+	defaultHashedTargetGracePeriod = 5 * time.Minute
+	// policyDeniedRequeueDelay is the delay before retrying targets rejected by an admission webhook
+	policyDeniedRequeueDelay = 5 * time.Minute
+	// defaultSourceFailureThreshold is the number of consecutive source fetch errors that open the circuit breaker
+	defaultSourceFailureThreshold = 5
+	// defaultSourceBreakerBackoff is how long an open circuit breaker waits before probing the source again
+	defaultSourceBreakerBackoff = 10 * time.Minute
+	// defaultTargetBackoffBase is the delay before a target is retried after its first failed write
+	defaultTargetBackoffBase = 5 * time.Second
+	// defaultTargetBackoffMax caps the delay between retries of a target that keeps failing
+	defaultTargetBackoffMax = 5 * time.Minute
+	// defaultNamespaceCoalesceDelay is the window in which namespace events are merged into one reconcile
+	defaultNamespaceCoalesceDelay = 2 * time.Second
+	// defaultOrphanSweepInterval is how often orphaned targets are checked for an expired TargetTTL by default
+	defaultOrphanSweepInterval = time.Minute
+	// maxManagedKeysAnnotationLength bounds the managed keys annotation, longer lists are replaced by a reference
+	maxManagedKeysAnnotationLength = 4096
+	// maxListRestarts bounds how often a paginated list is restarted after its continue token expired
+	maxListRestarts = 3
+)
+
 var (
-	FinalizerName       = "sync.propagators.io/finalizer"
-	OwnerLabelKey       = "sync.propagators.io/owner"
-	OwnerUIDAnnotation  = "sync.propagators.io/owner-uid"
-	ManagedByLabelKey   = "sync.propagators.io/managed-by"
+	FinalizerName       = ownership.FinalizerName
+	OwnerLabelKey       = ownership.OwnerLabelKey
+	OwnerUIDAnnotation  = ownership.OwnerUIDAnnotation
+	ManagedByLabelKey   = ownership.ManagedByLabelKey
 	ManagedByLabelValue = "configmap-propagator"
+	// ExpiresAtAnnotation holds the RFC3339 time after which a target is refreshed when TargetTTL is set
+	ExpiresAtAnnotation = "sync.propagators.io/expires-at"
+	// OrphanedFromAnnotation holds the UID of the propagation that orphaned a target with an expiry
+	OrphanedFromAnnotation = "sync.propagators.io/orphaned-from"
+	// OrphanedLabelKey marks the orphaned targets that are deleted once their expiry has passed
+	OrphanedLabelKey = "sync.propagators.io/orphaned"
+	// HashOfAnnotation holds the unsuffixed target name of a hash suffixed target
+	HashOfAnnotation = "sync.propagators.io/hash-of"
+	// SupersededAtAnnotation holds the RFC3339 time a hash suffixed target was replaced by a newer one
+	SupersededAtAnnotation = "sync.propagators.io/superseded-at"
+	// SummaryOfLabelKey marks the summary ConfigMap of a propagation, it is not an owner label so the
+	// summary is never treated as a target
+	SummaryOfLabelKey = "sync.propagators.io/summary-of"
+	// FieldManager is the field manager used when applying target ConfigMaps with Server-Side Apply
+	FieldManager = "configmap-propagator"
+	// GzipBase64KeysAnnotation lists the target keys whose values are gzip compressed and base64 encoded
+	GzipBase64KeysAnnotation = "sync.propagators.io/gzip-base64-keys"
+	// RevisionAnnotation holds the revision of the propagated data a target was last written with
+	RevisionAnnotation = "sync.propagators.io/revision"
+	// ConfigHashAnnotation is set by consumers on their pod template to the config hash they run with
+	ConfigHashAnnotation = "sync.propagators.io/config-hash"
+	// AppliedHashAnnotation holds the hash of the data last written to a target, a mismatch means it was edited
+	AppliedHashAnnotation = "sync.propagators.io/applied-hash"
+	// DriftCountAnnotation counts how often the out-of-band edits of a target were reverted
+	DriftCountAnnotation = "sync.propagators.io/drift-count"
+	// DriftedAtAnnotation holds the RFC3339 time of the last reverted out-of-band edit of a target
+	DriftedAtAnnotation = "sync.propagators.io/drifted-at"
+	// ManagedKeysAnnotation lists the sorted keys of a target managed by its propagation when AnnotateManagedKeys is set
+	ManagedKeysAnnotation = "sync.propagators.io/managed-keys"
+	// ManagedKeyCountAnnotation holds the number of managed keys of a target
+	ManagedKeyCountAnnotation = "sync.propagators.io/managed-key-count"
+	// PropagatedKeysAnnotation lists the sorted keys last propagated to a target when PruneRemovedSourceKeys is set
+	PropagatedKeysAnnotation = "sync.propagators.io/propagated-keys"
+	// SourceHashAnnotation holds the hash of the source a target was last written from, see sourceRevisionHash
+	SourceHashAnnotation = "sync.propagators.io/source-hash"
+	// DryRunAnnotation set to "true" on a propagation reports the planned target changes in status without applying them
+	DryRunAnnotation = "sync.propagators.io/dry-run"
+)
+
+const (
+	// ConditionTypeReady is the condition reporting whether all targets are in sync with the source
+	ConditionTypeReady = "Ready"
+	// ConditionTypeDriftCorrected reports whether the last sync reverted out-of-band edits of targets
+	ConditionTypeDriftCorrected = "DriftCorrected"
+	// ConditionTypeKeysSkipped reports the source keys left out of every target because they don't match the KeyFormat
+	ConditionTypeKeysSkipped = "KeysSkipped"
+	// legacyConditionTypeUnReady is the condition older versions set on failed syncs, it is removed on the next sync
+	legacyConditionTypeUnReady = "UnReady"
 )
 
 var (
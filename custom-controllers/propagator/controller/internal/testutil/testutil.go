@@ -0,0 +1,67 @@
+// Package testutil holds the fixtures shared by the test suites of the controllers.
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// NewScheme returns a scheme with the built-in types and the sync.propagators.io types registered.
+func NewScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := syncv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+// NewEnvironment returns a test environment serving the CRDs of the project. Paths are relative to
+// the package of the suite, a directory under controller/.
+func NewEnvironment() *envtest.Environment {
+	return &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+		BinaryAssetsDirectory: firstEnvTestBinaryDir(),
+	}
+}
+
+// EnvTestAvailable reports whether the binaries of env can be found, either through KUBEBUILDER_ASSETS
+// or in the directory populated by make setup-envtest.
+func EnvTestAvailable(env *envtest.Environment) bool {
+	return os.Getenv("KUBEBUILDER_ASSETS") != "" || env.BinaryAssetsDirectory != ""
+}
+
+// firstEnvTestBinaryDir returns the first version directory under bin/k8s, so the suites can run from
+// an IDE without KUBEBUILDER_ASSETS once make setup-envtest has run.
+func firstEnvTestBinaryDir() string {
+	basePath := filepath.Join("..", "..", "bin", "k8s")
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return filepath.Join(basePath, entry.Name())
+		}
+	}
+	return ""
+}
+
+// NewSecret returns a Secret of the given type holding data.
+func NewSecret(ns, name string, secretType corev1.SecretType, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Type:       secretType,
+		Data:       data,
+	}
+}
@@ -0,0 +1,127 @@
+// Package ownership holds the labels, annotations and finalizer the propagation controllers use to
+// track the objects they manage, so every propagation kind claims and releases its targets the same way.
+package ownership
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// FinalizerName guards a propagation until its targets are deleted or orphaned
+	FinalizerName = "sync.propagators.io/finalizer"
+	// OwnerLabelKey holds the name of the propagation owning a target
+	OwnerLabelKey = "sync.propagators.io/owner"
+	// OwnerUIDAnnotation holds the UID of the propagation owning a target
+	OwnerUIDAnnotation = "sync.propagators.io/owner-uid"
+	// ManagedByLabelKey holds the controller managing a target
+	ManagedByLabelKey = "sync.propagators.io/managed-by"
+)
+
+// Claim stamps the owner label, the owner UID annotation and the managed-by label of owner on obj.
+// It reports whether obj changed.
+func Claim(obj, owner metav1.Object, managedBy string) bool {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	changed := false
+	if labels[OwnerLabelKey] != owner.GetName() {
+		labels[OwnerLabelKey] = owner.GetName()
+		changed = true
+	}
+	if labels[ManagedByLabelKey] != managedBy {
+		labels[ManagedByLabelKey] = managedBy
+		changed = true
+	}
+	if annotations[OwnerUIDAnnotation] != string(owner.GetUID()) {
+		annotations[OwnerUIDAnnotation] = string(owner.GetUID())
+		changed = true
+	}
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+	return changed
+}
+
+// Release removes the owner label and the owner UID annotation of owner from obj, leaving the object
+// in place. Metadata pointing at another owner is kept. It reports whether obj changed.
+func Release(obj, owner metav1.Object) bool {
+	changed := false
+	labels := obj.GetLabels()
+	if name, ok := labels[OwnerLabelKey]; ok && name == owner.GetName() {
+		delete(labels, OwnerLabelKey)
+		obj.SetLabels(labels)
+		changed = true
+	}
+	if IsOwnedBy(obj, owner) {
+		annotations := obj.GetAnnotations()
+		delete(annotations, OwnerUIDAnnotation)
+		obj.SetAnnotations(annotations)
+		changed = true
+	}
+	return changed
+}
+
+// IsOwnedBy reports whether obj carries the owner UID annotation of owner.
+func IsOwnedBy(obj, owner metav1.Object) bool {
+	uid, ok := obj.GetAnnotations()[OwnerUIDAnnotation]
+	return ok && uid == string(owner.GetUID())
+}
+
+// OtherOwner returns the propagation other than owner that claims obj, by name from the owner label or
+// by UID from the owner UID annotation. A label carrying the name of owner is only its own claim when
+// the UID annotation is missing or matches owner, the name alone can be forged or left behind by an
+// earlier incarnation of owner.
+func OtherOwner(obj, owner metav1.Object) (string, bool) {
+	if name := obj.GetLabels()[OwnerLabelKey]; name != "" && name != owner.GetName() {
+		return name, true
+	}
+	uid := obj.GetAnnotations()[OwnerUIDAnnotation]
+	if uid == "" || uid == string(owner.GetUID()) {
+		return "", false
+	}
+	return "uid " + uid, true
+}
+
+// OwnedByAnotherError is returned when a target is already managed by another propagation.
+type OwnedByAnotherError struct {
+	// Kind of the target, e.g. configmap or secret
+	Kind      string
+	Namespace string
+	Name      string
+	// OwnerKind is the kind of the other propagation, e.g. ConfigMapPropagation
+	OwnerKind string
+	// Owner is the name of the other propagation, or its UID when the owner label was stripped
+	Owner string
+}
+
+func (e *OwnedByAnotherError) Error() string {
+	return fmt.Sprintf("target %s %s/%s is managed by %s %s", e.Kind, e.Namespace, e.Name, e.OwnerKind, e.Owner)
+}
+
+// AddFinalizer adds FinalizerName to the propagation and updates it when it was missing.
+func AddFinalizer(ctx context.Context, c client.Client, obj client.Object) error {
+	if !controllerutil.AddFinalizer(obj, FinalizerName) {
+		return nil
+	}
+	if err := c.Update(ctx, obj); err != nil {
+		return fmt.Errorf("failed to add finalizer: %w", err)
+	}
+	return nil
+}
+
+// RemoveFinalizer removes FinalizerName from the propagation and updates it when it was present.
+func RemoveFinalizer(ctx context.Context, c client.Client, obj client.Object) error {
+	if !controllerutil.RemoveFinalizer(obj, FinalizerName) {
+		return nil
+	}
+	return c.Update(ctx, obj)
+}
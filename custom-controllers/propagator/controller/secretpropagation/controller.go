@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// SecretPropagationReconciler reconciles a SecretPropagation object
+type SecretPropagationReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// Clock is the time source of the sync schedule. SetupWithManager defaults it to the wall clock.
+	Clock clock.PassiveClock
+}
+
+// +kubebuilder:rbac:groups=sync.propagators.io,resources=secretpropagations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sync.propagators.io,resources=secretpropagations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=sync.propagators.io,resources=secretpropagations/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile syncs the target Secrets of a SecretPropagation with its source Secret.
+func (r *SecretPropagationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var secretPropagation syncv1alpha1.SecretPropagation
+	if err := r.Get(ctx, req.NamespacedName, &secretPropagation); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !secretPropagation.DeletionTimestamp.IsZero() {
+		if err := r.HandleDelete(ctx, &secretPropagation); err != nil {
+			r.Recorder.Eventf(&secretPropagation, corev1.EventTypeWarning, "DeleteFailed", "%v", err)
+			if errors.Is(err, ErrDeletingTargets) {
+				return ctrl.Result{RequeueAfter: deleteRetryDelay}, nil
+			}
+			return ctrl.Result{}, err
+		}
+		log.Info("deleted the secret propagator")
+		return ctrl.Result{}, nil
+	}
+
+	if err := ownership.AddFinalizer(ctx, r.Client, &secretPropagation); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	source := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: secretPropagation.Spec.Source.NamespaceOrDefault(), Name: secretPropagation.Spec.Source.Name}, source)
+	if apierrors.IsNotFound(err) {
+		// The source watch brings the propagation back once the Secret exists
+		return ctrl.Result{}, r.markNotReady(ctx, &secretPropagation, "SourceNotFound",
+			fmt.Sprintf("source Secret %s/%s not found", secretPropagation.Spec.Source.NamespaceOrDefault(), secretPropagation.Spec.Source.Name))
+	}
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get source secret: %w", err)
+	}
+
+	return r.SyncTargets(ctx, &secretPropagation, source)
+}
+
+// markNotReady sets the Ready condition of the propagation to False with the given reason.
+func (r *SecretPropagationReconciler) markNotReady(ctx context.Context, secretPropagation *syncv1alpha1.SecretPropagation, reason, message string) error {
+	updated := secretPropagation.DeepCopy()
+	meta.SetStatusCondition(&updated.Status.Conditions, metav1.Condition{
+		Type:    ConditionTypeReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	})
+	if equality.Semantic.DeepEqual(secretPropagation.Status, updated.Status) {
+		return nil
+	}
+	if err := r.Status().Patch(ctx, updated, client.MergeFrom(secretPropagation)); err != nil {
+		return fmt.Errorf("failed to update the status of secretpropagation: %w", err)
+	}
+	return nil
+}
+
+// syncMode returns the SyncMode of the propagation, OnChange when unset.
+func syncMode(secretPropagation *syncv1alpha1.SecretPropagation) syncv1alpha1.SyncMode {
+	if secretPropagation.Spec.SyncMode != "" {
+		return secretPropagation.Spec.SyncMode
+	}
+	return syncv1alpha1.SyncModeOnChange
+}
+
+// now returns the current time of the reconciler's clock.
+func (r *SecretPropagationReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
+}
+
+// requeueResult requeues Periodic propagations once their SyncInterval, defaultSyncInterval when it is
+// unset, has elapsed since LastSyncedAt. A zero SyncInterval disables the periodic syncs.
+func (r *SecretPropagationReconciler) requeueResult(secretPropagation *syncv1alpha1.SecretPropagation) ctrl.Result {
+	if syncMode(secretPropagation) != syncv1alpha1.SyncModePeriodic {
+		return ctrl.Result{}
+	}
+	interval := defaultSyncInterval
+	if secretPropagation.Spec.SyncInterval != nil {
+		if secretPropagation.Spec.SyncInterval.Duration == 0 {
+			return ctrl.Result{}
+		}
+		if secretPropagation.Spec.SyncInterval.Duration > 0 {
+			interval = secretPropagation.Spec.SyncInterval.Duration
+		}
+	}
+	if remaining := interval - r.now().Sub(secretPropagation.Status.LastSyncedAt.Time); remaining > 0 && remaining <= interval {
+		return ctrl.Result{RequeueAfter: remaining}
+	}
+	return ctrl.Result{RequeueAfter: interval}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SecretPropagationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Recorder = mgr.GetEventRecorderFor("secret-propagator")
+	if r.Clock == nil {
+		r.Clock = clock.RealClock{}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&syncv1alpha1.SecretPropagation{}).
+		// Secrets are not cached, only their metadata is watched and they are read from the API server
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.mapSecret), builder.OnlyMetadata).
+		Named("secretpropagation").
+		Complete(r)
+}
+
+// mapSecret enqueues the propagation owning a managed Secret, or every propagation reading an unmanaged
+// Secret as its source so that source changes reach the targets.
+func (r *SecretPropagationReconciler) mapSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetLabels()[ownership.ManagedByLabelKey] == ManagedByLabelValue {
+		if owner := obj.GetLabels()[ownership.OwnerLabelKey]; owner != "" {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: owner}}}
+		}
+		return nil
+	}
+	var propagations syncv1alpha1.SecretPropagationList
+	if err := r.List(ctx, &propagations); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list secret propagators for source secret")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0)
+	for _, sp := range propagations.Items {
+		if sp.Spec.Source.NamespaceOrDefault() == obj.GetNamespace() && sp.Spec.Source.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: sp.Name}})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,259 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/internal/testutil"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("SecretPropagation Reconcile", func() {
+	ctx := context.Background()
+
+	var sourceNs, teamA, teamB string
+	BeforeEach(func() {
+		sourceNs, teamA, teamB = createNamespace(ctx), createNamespace(ctx), createNamespace(ctx)
+	})
+
+	spec := func(policy syncv1alpha1.DeletionPolicy) syncv1alpha1.SecretPropagationSpec {
+		return syncv1alpha1.SecretPropagationSpec{
+			Source:         syncv1alpha1.PropagationSource{Name: "registry", Namespace: sourceNs},
+			Targets:        []syncv1alpha1.TargetRef{{Namespace: teamA}, {Namespace: teamB, Name: "pull-secret"}},
+			DeletionPolicy: policy,
+		}
+	}
+	request := func(sp *syncv1alpha1.SecretPropagation) ctrl.Request {
+		return ctrl.Request{NamespacedName: types.NamespacedName{Name: sp.Name}}
+	}
+
+	It("creates the targets with the source data and type", func() {
+		source := testutil.NewSecret(sourceNs, "registry", corev1.SecretTypeDockerConfigJson, map[string][]byte{".dockerconfigjson": []byte("{}")})
+		Expect(k8sClient.Create(ctx, source)).To(Succeed())
+		sp := createPropagation(ctx, spec("Delete"))
+		r := newTestReconciler()
+
+		_, err := r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, key := range []types.NamespacedName{{Namespace: teamA, Name: "registry"}, {Namespace: teamB, Name: "pull-secret"}} {
+			target := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, key, target)).To(Succeed())
+			Expect(target.Type).To(Equal(corev1.SecretTypeDockerConfigJson))
+			Expect(target.Data).To(HaveKeyWithValue(".dockerconfigjson", []byte("{}")))
+			Expect(target.Labels).To(HaveKeyWithValue(ownership.OwnerLabelKey, sp.Name))
+			Expect(target.Labels).To(HaveKeyWithValue(ownership.ManagedByLabelKey, ManagedByLabelValue))
+			Expect(target.Annotations).To(HaveKeyWithValue(ownership.OwnerUIDAnnotation, string(sp.UID)))
+		}
+
+		Expect(k8sClient.Get(ctx, request(sp).NamespacedName, sp)).To(Succeed())
+		Expect(sp.Finalizers).To(ContainElement(ownership.FinalizerName))
+		Expect(sp.Status.TargetsSummary.Created).To(Equal(int32(2)))
+		Expect(meta.IsStatusConditionTrue(sp.Status.Conditions, ConditionTypeReady)).To(BeTrue())
+	})
+
+	It("updates the targets when the source changes", func() {
+		source := testutil.NewSecret(sourceNs, "registry", corev1.SecretTypeOpaque, map[string][]byte{"token": []byte("old")})
+		Expect(k8sClient.Create(ctx, source)).To(Succeed())
+		sp := createPropagation(ctx, spec("Delete"))
+		r := newTestReconciler()
+		_, err := r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+
+		source.Data["token"] = []byte("new")
+		Expect(k8sClient.Update(ctx, source)).To(Succeed())
+		Expect(r.mapSecret(ctx, source)).To(ConsistOf(request(sp)))
+
+		_, err = r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+
+		target := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: teamA, Name: "registry"}, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("token", []byte("new")))
+		Expect(k8sClient.Get(ctx, request(sp).NamespacedName, sp)).To(Succeed())
+		Expect(sp.Status.TargetsSummary.Updated).To(Equal(int32(2)))
+	})
+
+	It("reports a target whose type differs from the source", func() {
+		source := testutil.NewSecret(sourceNs, "registry", corev1.SecretTypeDockerConfigJson, map[string][]byte{".dockerconfigjson": []byte("{}")})
+		existing := testutil.NewSecret(teamA, "registry", corev1.SecretTypeOpaque, map[string][]byte{"token": []byte("local")})
+		Expect(k8sClient.Create(ctx, source)).To(Succeed())
+		Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+		s := spec("Delete")
+		s.AdoptExistingTargets = true
+		sp := createPropagation(ctx, s)
+		r := newTestReconciler()
+
+		_, err := r.Reconcile(ctx, request(sp))
+		Expect(err).To(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, request(sp).NamespacedName, sp)).To(Succeed())
+		Expect(sp.Status.TargetStatuses).To(HaveLen(1))
+		Expect(sp.Status.TargetStatuses[0].Namespace).To(Equal(teamA))
+		Expect(meta.IsStatusConditionFalse(sp.Status.Conditions, ConditionTypeReady)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: teamA, Name: "registry"}, existing)).To(Succeed())
+		Expect(existing.Type).To(Equal(corev1.SecretTypeOpaque))
+	})
+
+	It("leaves a Secret managed by another propagation alone", func() {
+		source := testutil.NewSecret(sourceNs, "registry", corev1.SecretTypeOpaque, map[string][]byte{"token": []byte("new")})
+		other := testutil.NewSecret(teamA, "registry", corev1.SecretTypeOpaque, map[string][]byte{"token": []byte("other")})
+		other.Labels = map[string]string{ownership.OwnerLabelKey: "other-propagation", ownership.ManagedByLabelKey: ManagedByLabelValue}
+		Expect(k8sClient.Create(ctx, source)).To(Succeed())
+		Expect(k8sClient.Create(ctx, other)).To(Succeed())
+		s := spec("Delete")
+		s.PropagationPolicy = syncv1alpha1.PropagationPolicyOverwrite
+		s.AdoptExistingTargets = true
+		sp := createPropagation(ctx, s)
+		r := newTestReconciler()
+
+		_, err := r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: teamA, Name: "registry"}, other)).To(Succeed())
+		Expect(other.Data).To(HaveKeyWithValue("token", []byte("other")))
+		Expect(other.Labels).To(HaveKeyWithValue(ownership.OwnerLabelKey, "other-propagation"))
+		Expect(k8sClient.Get(ctx, request(sp).NamespacedName, sp)).To(Succeed())
+		Expect(sp.Status.TargetsSummary.Skipped).To(Equal(int32(1)))
+		Expect(sp.Status.TargetStatuses).To(ConsistOf(HaveField("Reason", "OwnedByAnother")))
+
+		By("keeping the Secret on delete with the Delete policy")
+		Expect(k8sClient.Delete(ctx, sp)).To(Succeed())
+		_, err = r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: teamA, Name: "registry"}, other)).To(Succeed())
+	})
+
+	It("adopts an unmanaged Secret only with adoptExistingTargets", func() {
+		source := testutil.NewSecret(sourceNs, "registry", corev1.SecretTypeOpaque, map[string][]byte{"token": []byte("new")})
+		existing := testutil.NewSecret(teamA, "registry", corev1.SecretTypeOpaque, map[string][]byte{"token": []byte("local")})
+		Expect(k8sClient.Create(ctx, source)).To(Succeed())
+		Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+		sp := createPropagation(ctx, spec("Delete"))
+		r := newTestReconciler()
+
+		_, err := r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: teamA, Name: "registry"}, existing)).To(Succeed())
+		Expect(existing.Data).To(HaveKeyWithValue("token", []byte("local")))
+		Expect(existing.Labels).NotTo(HaveKey(ownership.OwnerLabelKey))
+		Expect(k8sClient.Get(ctx, request(sp).NamespacedName, sp)).To(Succeed())
+		Expect(sp.Status.TargetStatuses).To(ConsistOf(HaveField("Reason", "UnmanagedTarget")))
+
+		sp.Spec.AdoptExistingTargets = true
+		Expect(k8sClient.Update(ctx, sp)).To(Succeed())
+		_, err = r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: teamA, Name: "registry"}, existing)).To(Succeed())
+		Expect(existing.Data).To(HaveKeyWithValue("token", []byte("new")))
+		Expect(existing.Labels).To(HaveKeyWithValue(ownership.OwnerLabelKey, sp.Name))
+	})
+
+	It("orphans the targets on delete with the Orphan policy", func() {
+		source := testutil.NewSecret(sourceNs, "registry", corev1.SecretTypeOpaque, map[string][]byte{"token": []byte("t")})
+		Expect(k8sClient.Create(ctx, source)).To(Succeed())
+		sp := createPropagation(ctx, spec("Orphan"))
+		r := newTestReconciler()
+		_, err := r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Delete(ctx, sp)).To(Succeed())
+		_, err = r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+
+		err = k8sClient.Get(ctx, request(sp).NamespacedName, sp)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		target := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: teamA, Name: "registry"}, target)).To(Succeed())
+		Expect(target.Labels).NotTo(HaveKey(ownership.OwnerLabelKey))
+		Expect(target.Annotations).NotTo(HaveKey(ownership.OwnerUIDAnnotation))
+		Expect(target.Data).To(HaveKeyWithValue("token", []byte("t")))
+	})
+
+	It("deletes the targets on delete with the Delete policy", func() {
+		source := testutil.NewSecret(sourceNs, "registry", corev1.SecretTypeOpaque, map[string][]byte{"token": []byte("t")})
+		Expect(k8sClient.Create(ctx, source)).To(Succeed())
+		sp := createPropagation(ctx, spec("Delete"))
+		r := newTestReconciler()
+		_, err := r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Delete(ctx, sp)).To(Succeed())
+		_, err = r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: teamA, Name: "registry"}, &corev1.Secret{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: sourceNs, Name: "registry"}, &corev1.Secret{})).To(Succeed())
+	})
+
+	It("marks the propagation not ready while the source is missing", func() {
+		sp := createPropagation(ctx, spec("Delete"))
+		r := newTestReconciler()
+
+		_, err := r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, request(sp).NamespacedName, sp)).To(Succeed())
+		cond := meta.FindStatusCondition(sp.Status.Conditions, ConditionTypeReady)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal("SourceNotFound"))
+	})
+
+	It("requeues Periodic propagations after their syncInterval unless it is zero", func() {
+		lastSync := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		fakeClock := clocktesting.NewFakeClock(lastSync)
+		r := newTestReconciler()
+		r.Clock = fakeClock
+		sp := &syncv1alpha1.SecretPropagation{Spec: spec("Delete")}
+		sp.Spec.SyncMode = syncv1alpha1.SyncModePeriodic
+		sp.Status.LastSyncedAt = metav1.NewTime(lastSync)
+		Expect(r.requeueResult(sp).RequeueAfter).To(Equal(defaultSyncInterval))
+
+		sp.Spec.SyncInterval = &metav1.Duration{Duration: time.Minute}
+		Expect(r.requeueResult(sp).RequeueAfter).To(Equal(time.Minute))
+		fakeClock.Step(20 * time.Second)
+		Expect(r.requeueResult(sp).RequeueAfter).To(Equal(40 * time.Second))
+
+		sp.Spec.SyncInterval = &metav1.Duration{}
+		Expect(r.requeueResult(sp)).To(Equal(ctrl.Result{}))
+	})
+
+	It("stamps LastSyncedAt from the reconciler clock", func() {
+		source := testutil.NewSecret(sourceNs, "registry", corev1.SecretTypeOpaque, map[string][]byte{"token": []byte("t")})
+		Expect(k8sClient.Create(ctx, source)).To(Succeed())
+		sp := createPropagation(ctx, spec("Delete"))
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		r := newTestReconciler()
+		r.Clock = fakeClock
+
+		_, err := r.Reconcile(ctx, request(sp))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, request(sp).NamespacedName, sp)).To(Succeed())
+		Expect(sp.Status.LastSyncedAt.Time).To(BeTemporally("==", fakeClock.Now()))
+	})
+
+	It("rejects a per-target syncMode or syncInterval", func() {
+		s := spec("Delete")
+		s.Targets[0].SyncMode = syncv1alpha1.SyncModePeriodic
+		err := k8sClient.Create(ctx, &syncv1alpha1.SecretPropagation{ObjectMeta: metav1.ObjectMeta{GenerateName: "creds-"}, Spec: s})
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+		s = spec("Delete")
+		s.Targets[1].SyncInterval = &metav1.Duration{Duration: time.Minute}
+		err = k8sClient.Create(ctx, &syncv1alpha1.SecretPropagation{ObjectMeta: metav1.ObjectMeta{GenerateName: "creds-"}, Spec: s})
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,320 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	"github.com/harsha3330/kubernetes/custom-controllers/propagator/controller/ownership"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// SyncTargets creates and updates the target Secrets from source and cleans up the managed Secrets
+// that are no longer targeted, then records the outcome in the status.
+func (r *SecretPropagationReconciler) SyncTargets(ctx context.Context, secretPropagation *syncv1alpha1.SecretPropagation, source *corev1.Secret) (ctrl.Result, error) {
+	desired, err := r.resolveTargets(ctx, secretPropagation)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to resolve target secrets: %w", err)
+	}
+	current, err := r.getCurrentTargets(ctx, secretPropagation)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list managed secrets: %w", err)
+	}
+
+	var summary syncv1alpha1.TargetsSummary
+	targetStatuses := make([]syncv1alpha1.TargetStatus, 0)
+	for _, target := range desired {
+		summary.Total += 1
+		created, updated, err := r.ensureSecret(ctx, secretPropagation, source, target)
+		var ownedErr *ownership.OwnedByAnotherError
+		var existsErr *UnmanagedTargetError
+		if errors.As(err, &ownedErr) || errors.As(err, &existsErr) {
+			summary.Skipped += 1
+			reason := "OwnedByAnother"
+			if existsErr != nil {
+				reason = "UnmanagedTarget"
+			}
+			targetStatuses = append(targetStatuses, syncv1alpha1.TargetStatus{
+				Namespace: target.Namespace,
+				Name:      target.Name,
+				State:     "Skipped",
+				Reason:    reason,
+				Message:   err.Error(),
+			})
+			r.Recorder.Eventf(secretPropagation, corev1.EventTypeWarning, reason, "%v", err)
+			continue
+		}
+		if err != nil {
+			summary.Failed += 1
+			targetStatuses = append(targetStatuses, syncv1alpha1.TargetStatus{
+				Namespace: target.Namespace,
+				Name:      target.Name,
+				State:     "Failed",
+				Reason:    "SyncFailed",
+				Message:   err.Error(),
+			})
+			r.Recorder.Eventf(secretPropagation, corev1.EventTypeWarning, "SyncFailed", "%s/%s sync failed: %v", target.Namespace, target.Name, err)
+			continue
+		}
+		if created {
+			summary.Created += 1
+		}
+		if updated {
+			summary.Updated += 1
+		}
+		if !created && !updated {
+			summary.Unchanged += 1
+		}
+	}
+
+	for key, target := range current {
+		if _, ok := desired[key]; ok {
+			continue
+		}
+		summary.Total += 1
+		var err error
+		switch secretPropagation.Spec.DeletionPolicy {
+		case "Orphan":
+			if err = r.orphanSecret(ctx, secretPropagation, target); err == nil {
+				summary.Orphaned += 1
+			}
+		default:
+			if err = client.IgnoreNotFound(r.Delete(ctx, target)); err == nil {
+				summary.Deleted += 1
+			}
+		}
+		if err != nil {
+			summary.Failed += 1
+			r.Recorder.Eventf(secretPropagation, corev1.EventTypeWarning, "CleanupFailed", "%s/%s cleanup failed: %v", target.Namespace, target.Name, err)
+		}
+	}
+
+	updated := secretPropagation.DeepCopy()
+	updated.Status.TargetsSummary = summary
+	updated.Status.TargetStatuses = targetStatuses
+	updated.Status.LastSyncedAt = metav1.NewTime(r.now())
+	if summary.Failed > 0 {
+		failed := make([]string, 0, len(targetStatuses))
+		for _, t := range targetStatuses {
+			if t.State == "Failed" {
+				failed = append(failed, t.Namespace+"/"+t.Name)
+			}
+		}
+		meta.SetStatusCondition(&updated.Status.Conditions, metav1.Condition{
+			Type:    ConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "SyncFailed",
+			Message: fmt.Sprintf("Sync Failed for: %s", strings.Join(failed, ",")),
+		})
+	} else {
+		updated.Status.SyncedGeneration = fmt.Sprintf("%d", secretPropagation.Generation)
+		meta.SetStatusCondition(&updated.Status.Conditions, metav1.Condition{
+			Type:    ConditionTypeReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Synced",
+			Message: "All Objects have been synced",
+		})
+	}
+	if !equality.Semantic.DeepEqual(secretPropagation.Status, updated.Status) {
+		if err := r.Status().Patch(ctx, updated, client.MergeFrom(secretPropagation)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update the status of secretpropagation: %w", err)
+		}
+	}
+
+	if summary.Failed > 0 {
+		return ctrl.Result{}, fmt.Errorf("failed to sync the targets")
+	}
+	return r.requeueResult(updated), nil
+}
+
+// ensureSecret creates the target Secret from source or brings an existing one in line with it.
+// CreatedOnce only claims existing targets and leaves their data alone. A Secret managed by another
+// propagation is never touched, one managed by nobody only with AdoptExistingTargets.
+func (r *SecretPropagationReconciler) ensureSecret(ctx context.Context, secretPropagation *syncv1alpha1.SecretPropagation, source *corev1.Secret, target types.NamespacedName) (bool, bool, error) {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, target, secret)
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: target.Name, Namespace: target.Namespace},
+			Type:       source.Type,
+			Data:       copyData(source.Data),
+		}
+		ownership.Claim(secret, secretPropagation, ManagedByLabelValue)
+		if err := r.Create(ctx, secret); err != nil {
+			return false, false, fmt.Errorf("failed to create propagated secret %s/%s: %w", target.Namespace, target.Name, err)
+		}
+		return true, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	// Taking over the Secret would replace its data and, with the Delete policy, delete it with the propagation
+	if owner, ok := ownership.OtherOwner(secret, secretPropagation); ok {
+		return false, false, &ownership.OwnedByAnotherError{Kind: "secret", Namespace: target.Namespace, Name: target.Name, OwnerKind: "SecretPropagation", Owner: owner}
+	}
+	if !claimedBy(secret, secretPropagation) && !secretPropagation.Spec.AdoptExistingTargets {
+		return false, false, &UnmanagedTargetError{Namespace: target.Namespace, Name: target.Name}
+	}
+
+	if secret.Type != source.Type {
+		// The type of a Secret is immutable, replacing the Secret is left to its owner
+		return false, false, fmt.Errorf("target secret %s/%s has type %s, the source has type %s", target.Namespace, target.Name, secret.Type, source.Type)
+	}
+
+	changed := ownership.Claim(secret, secretPropagation, ManagedByLabelValue)
+	dataChanged := false
+	if syncMode(secretPropagation) != syncv1alpha1.SyncModeCreatedOnce {
+		desired := buildDesiredData(secretPropagation, source.Data, secret.Data)
+		if !equality.Semantic.DeepEqual(secret.Data, desired) {
+			secret.Data = desired
+			dataChanged = true
+		}
+	}
+	if !changed && !dataChanged {
+		return false, false, nil
+	}
+	if err := r.Update(ctx, secret); err != nil {
+		return false, false, fmt.Errorf("failed to update target secret %s/%s: %w", target.Namespace, target.Name, err)
+	}
+	return false, dataChanged, nil
+}
+
+// claimedBy reports whether the Secret carries the claim of the propagation, its owner UID annotation or,
+// when the annotation was stripped, its owner label.
+func claimedBy(secret *corev1.Secret, secretPropagation *syncv1alpha1.SecretPropagation) bool {
+	return ownership.IsOwnedBy(secret, secretPropagation) || secret.Labels[ownership.OwnerLabelKey] == secretPropagation.Name
+}
+
+// buildDesiredData computes the Data the target Secret should hold for the propagation policy.
+// Overwrite mirrors the source, Merge layers the source keys over the existing target keys.
+func buildDesiredData(secretPropagation *syncv1alpha1.SecretPropagation, srcData, targetData map[string][]byte) map[string][]byte {
+	desired := map[string][]byte{}
+	if secretPropagation.Spec.PropagationPolicy != "Overwrite" {
+		for k, v := range targetData {
+			desired[k] = v
+		}
+	}
+	for k, v := range srcData {
+		desired[k] = v
+	}
+	return desired
+}
+
+func copyData(data map[string][]byte) map[string][]byte {
+	if data == nil {
+		return nil
+	}
+	out := make(map[string][]byte, len(data))
+	for k, v := range data {
+		out[k] = append([]byte(nil), v...)
+	}
+	return out
+}
+
+// resolveTargets computes the desired target Secrets from spec.targets and spec.namespaceSelector,
+// keyed by namespace/name. The source Secret itself is never a target.
+func (r *SecretPropagationReconciler) resolveTargets(ctx context.Context, secretPropagation *syncv1alpha1.SecretPropagation) (map[string]types.NamespacedName, error) {
+	targets := make(map[string]types.NamespacedName)
+	sourceName := secretPropagation.Spec.Source.Name
+	add := func(ns, name string) {
+		if _, isSys := defaultSystemNamespaces[ns]; isSys && !secretPropagation.Spec.AllowSystemNamespaces {
+			return
+		}
+		if ns == secretPropagation.Spec.Source.NamespaceOrDefault() && name == sourceName {
+			return
+		}
+		targets[ns+"/"+name] = types.NamespacedName{Namespace: ns, Name: name}
+	}
+
+	for _, t := range secretPropagation.Spec.Targets {
+		name := t.Name
+		if name == "" {
+			name = sourceName
+		}
+		add(t.Namespace, name)
+	}
+
+	if nsSel := secretPropagation.Spec.NamespaceSelector; nsSel != nil {
+		sel, err := metav1.LabelSelectorAsSelector(nsSel)
+		if err != nil {
+			return nil, err
+		}
+		var nsList corev1.NamespaceList
+		if err := r.List(ctx, &nsList, client.MatchingLabelsSelector{Selector: sel}); err != nil {
+			return nil, err
+		}
+		for _, ns := range nsList.Items {
+			add(ns.Name, sourceName)
+		}
+	}
+	return targets, nil
+}
+
+// getCurrentTargets lists the Secrets owned by the propagation, keyed by namespace/name.
+func (r *SecretPropagationReconciler) getCurrentTargets(ctx context.Context, secretPropagation *syncv1alpha1.SecretPropagation) (map[string]*corev1.Secret, error) {
+	var secretList corev1.SecretList
+	if err := r.List(ctx, &secretList, client.MatchingLabels{
+		ownership.OwnerLabelKey:     secretPropagation.Name,
+		ownership.ManagedByLabelKey: ManagedByLabelValue,
+	}); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]*corev1.Secret, len(secretList.Items))
+	for i := range secretList.Items {
+		secret := &secretList.Items[i]
+		targets[secret.Namespace+"/"+secret.Name] = secret
+	}
+	return targets, nil
+}
+
+// orphanSecret releases the target Secret from the propagation and leaves it in place.
+func (r *SecretPropagationReconciler) orphanSecret(ctx context.Context, secretPropagation *syncv1alpha1.SecretPropagation, secret *corev1.Secret) error {
+	if !ownership.Release(secret, secretPropagation) {
+		return nil
+	}
+	if err := r.Update(ctx, secret); err != nil {
+		return fmt.Errorf("failed to patch secret to orphan: %w", err)
+	}
+	return nil
+}
+
+// HandleDelete deletes or orphans every target Secret per the DeletionPolicy and removes the finalizer.
+func (r *SecretPropagationReconciler) HandleDelete(ctx context.Context, secretPropagation *syncv1alpha1.SecretPropagation) error {
+	if !controllerutil.ContainsFinalizer(secretPropagation, ownership.FinalizerName) {
+		return nil
+	}
+
+	targets, err := r.getCurrentTargets(ctx, secretPropagation)
+	if err != nil {
+		return err
+	}
+
+	failed := make([]string, 0)
+	for key, target := range targets {
+		var err error
+		switch secretPropagation.Spec.DeletionPolicy {
+		case "Orphan":
+			err = r.orphanSecret(ctx, secretPropagation, target)
+		default:
+			err = client.IgnoreNotFound(r.Delete(ctx, target))
+		}
+		if err != nil {
+			failed = append(failed, key)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: %s", ErrDeletingTargets, strings.Join(failed, ","))
+	}
+
+	return ownership.RemoveFinalizer(ctx, r.Client, secretPropagation)
+}
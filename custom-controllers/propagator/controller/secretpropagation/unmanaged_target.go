@@ -0,0 +1,14 @@
+package controller
+
+import "fmt"
+
+// UnmanagedTargetError is returned when a Secret that no propagation manages already exists at a target
+// location and the propagation does not adopt existing targets.
+type UnmanagedTargetError struct {
+	Namespace string
+	Name      string
+}
+
+func (e *UnmanagedTargetError) Error() string {
+	return fmt.Sprintf("target secret %s/%s already exists and is not managed by a SecretPropagation, set adoptExistingTargets to take it over", e.Namespace, e.Name)
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"errors"
+	"time"
+)
+
+var defaultSystemNamespaces = map[string]struct{}{
+	"kube-system":     {},
+	"kube-public":     {},
+	"kube-node-lease": {},
+}
+
+const (
+	// defaultSyncInterval is used when a Periodic propagation has no SyncInterval set
+	defaultSyncInterval = 5 * time.Minute
+	// deleteRetryDelay is the delay before retrying a deletion whose targets could not be cleaned up
+	deleteRetryDelay = 30 * time.Second
+)
+
+var (
+	// ManagedByLabelValue marks the Secrets managed by the SecretPropagation controller
+	ManagedByLabelValue = "secret-propagator"
+)
+
+const (
+	// ConditionTypeReady is the condition reporting whether all targets are in sync with the source
+	ConditionTypeReady = "Ready"
+)
+
+var (
+	ErrDeletingTargets = errors.New("failed to remove/orphan Secrets of targets")
+)
@@ -0,0 +1,158 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var configmappropagationlog = logf.Log.WithName("configmappropagation-resource")
+
+// SetupConfigMapPropagationWebhookWithManager registers the validating webhook for ConfigMapPropagation in the manager.
+func SetupConfigMapPropagationWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&syncv1alpha1.ConfigMapPropagation{}).
+		WithValidator(&ConfigMapPropagationCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-sync-propagators-io-v1alpha1-configmappropagation,mutating=false,failurePolicy=fail,sideEffects=None,groups=sync.propagators.io,resources=configmappropagations,verbs=create;update,versions=v1alpha1,name=vconfigmappropagation-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ConfigMapPropagationCustomValidator rejects ConfigMapPropagation specs the controller can't act on:
+// a target that is the source itself, no targets at all, an invalid or conflicting sourceSelector, a
+// rename of a key the key filters drop, an invalid namespaceNamePattern and Periodic mode without a
+// SyncInterval or with a negative one.
+type ConfigMapPropagationCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ConfigMapPropagationCustomValidator{}
+
+// ValidateCreate validates a new ConfigMapPropagation.
+func (v *ConfigMapPropagationCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	configmapPropagation, ok := obj.(*syncv1alpha1.ConfigMapPropagation)
+	if !ok {
+		return nil, fmt.Errorf("expected a ConfigMapPropagation object but got %T", obj)
+	}
+	configmappropagationlog.Info("validation for ConfigMapPropagation upon creation", "name", configmapPropagation.GetName())
+	return nil, validateConfigMapPropagation(configmapPropagation)
+}
+
+// ValidateUpdate validates the updated ConfigMapPropagation, the old object is not considered.
+func (v *ConfigMapPropagationCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	configmapPropagation, ok := newObj.(*syncv1alpha1.ConfigMapPropagation)
+	if !ok {
+		return nil, fmt.Errorf("expected a ConfigMapPropagation object for the newObj but got %T", newObj)
+	}
+	configmappropagationlog.Info("validation for ConfigMapPropagation upon update", "name", configmapPropagation.GetName())
+	return nil, validateConfigMapPropagation(configmapPropagation)
+}
+
+// ValidateDelete allows every deletion.
+func (v *ConfigMapPropagationCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateConfigMapPropagation(configmapPropagation *syncv1alpha1.ConfigMapPropagation) error {
+	spec := configmapPropagation.Spec
+	specPath := field.NewPath("spec")
+	var allErrs field.ErrorList
+
+	if spec.NamespaceSelector == nil && spec.NamespaceNamePattern == "" && len(spec.Targets) == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("targets"),
+			"at least one of namespaceSelector, namespaceNamePattern or targets must be set, otherwise nothing is propagated"))
+	}
+
+	selectorPath := specPath.Child("sourceSelector")
+	if spec.SourceSelector != nil {
+		if spec.SecretSource != nil {
+			allErrs = append(allErrs, field.Forbidden(selectorPath,
+				"sourceSelector selects ConfigMaps, it can't be combined with secretSource"))
+		}
+		if _, err := metav1.LabelSelectorAsSelector(spec.SourceSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(selectorPath, spec.SourceSelector, err.Error()))
+		}
+	} else if spec.SourceSelectorMode == syncv1alpha1.SourceSelectorModeSingle {
+		allErrs = append(allErrs, field.Required(selectorPath,
+			"sourceSelectorMode Single picks the source by label, a sourceSelector is required"))
+	}
+
+	if spec.KeyTransform != nil {
+		renamesPath := specPath.Child("keyTransform", "renames")
+		for _, k := range slices.Sorted(maps.Keys(spec.KeyTransform.Renames)) {
+			if spec.KeyFilteredOut(k) {
+				allErrs = append(allErrs, field.Invalid(renamesPath.Key(k), spec.KeyTransform.Renames[k],
+					"the source key is dropped by includeKeys or excludeKeys, it is never propagated"))
+			}
+		}
+	}
+
+	if err := validateNamespaceNamePattern(spec.NamespaceNamePattern); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("namespaceNamePattern"), spec.NamespaceNamePattern, err.Error()))
+	}
+
+	// Hash suffixed targets never carry the source name, so they can't collide with it
+	if !spec.HashSuffixTargetNames {
+		sourceNamespace := spec.Source.NamespaceOrDefault()
+		for i, t := range spec.Targets {
+			name := t.Name
+			if name == "" {
+				name = spec.Source.Name
+			}
+			if t.Namespace == sourceNamespace && name == spec.Source.Name {
+				allErrs = append(allErrs, field.Invalid(specPath.Child("targets").Index(i), fmt.Sprintf("%s/%s", t.Namespace, name),
+					"target is the source ConfigMap itself, the controller would overwrite its own source"))
+			}
+		}
+	}
+
+	// A zero syncInterval is allowed, it disables the periodic syncs
+	if spec.SyncMode == syncv1alpha1.SyncModePeriodic && (spec.SyncInterval == nil || spec.SyncInterval.Duration < 0) {
+		allErrs = append(allErrs, field.Required(specPath.Child("syncInterval"),
+			"a syncInterval of 0 or more is required when syncMode is Periodic"))
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(syncv1alpha1.GroupVersion.WithKind("ConfigMapPropagation").GroupKind(),
+		configmapPropagation.Name, allErrs)
+}
+
+// validateNamespaceNamePattern checks that a NamespaceNamePattern is a valid glob, or a valid
+// regular expression when prefixed with "re:".
+func validateNamespaceNamePattern(pattern string) error {
+	if expr, ok := strings.CutPrefix(pattern, "re:"); ok {
+		_, err := regexp.Compile(expr)
+		return err
+	}
+	_, err := path.Match(pattern, "")
+	return err
+}
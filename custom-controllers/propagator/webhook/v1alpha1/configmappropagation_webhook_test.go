@@ -0,0 +1,174 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	syncv1alpha1 "github.com/harsha3330/kubernetes/custom-controllers/propagator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ConfigMapPropagation webhook", func() {
+	ctx := context.Background()
+	validator := &ConfigMapPropagationCustomValidator{}
+
+	newPropagation := func(spec syncv1alpha1.ConfigMapPropagationSpec) *syncv1alpha1.ConfigMapPropagation {
+		return &syncv1alpha1.ConfigMapPropagation{ObjectMeta: metav1.ObjectMeta{Name: "app"}, Spec: spec}
+	}
+
+	It("admits a valid spec", func() {
+		cmp := newPropagation(syncv1alpha1.ConfigMapPropagationSpec{
+			Source:       syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:      []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "default", Name: "app-copy"}},
+			SyncMode:     syncv1alpha1.SyncModePeriodic,
+			SyncInterval: &metav1.Duration{Duration: 5 * time.Minute},
+		})
+		_, err := validator.ValidateCreate(ctx, cmp)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = validator.ValidateUpdate(ctx, cmp, cmp)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects a target that is the source ConfigMap", func() {
+		cmp := newPropagation(syncv1alpha1.ConfigMapPropagationSpec{
+			Source:  syncv1alpha1.PropagationSource{Name: "app"},
+			Targets: []syncv1alpha1.TargetRef{{Namespace: "team-a"}, {Namespace: "default"}},
+		})
+		_, err := validator.ValidateCreate(ctx, cmp)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.targets[1]"))
+		Expect(err.Error()).To(ContainSubstring("target is the source ConfigMap itself"))
+	})
+
+	It("allows a hash suffixed target in the source namespace", func() {
+		cmp := newPropagation(syncv1alpha1.ConfigMapPropagationSpec{
+			Source:                syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:               []syncv1alpha1.TargetRef{{Namespace: "default"}},
+			HashSuffixTargetNames: true,
+		})
+		_, err := validator.ValidateCreate(ctx, cmp)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects a sourceSelector combined with secretSource", func() {
+		cmp := newPropagation(syncv1alpha1.ConfigMapPropagationSpec{
+			Source:             syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			SourceSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"config-role": "app-defaults"}},
+			SourceSelectorMode: syncv1alpha1.SourceSelectorModeSingle,
+			SecretSource:       &syncv1alpha1.SecretSource{},
+			Targets:            []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+		})
+		_, err := validator.ValidateCreate(ctx, cmp)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.sourceSelector"))
+		Expect(err.Error()).To(ContainSubstring("can't be combined with secretSource"))
+	})
+
+	It("requires a sourceSelector in the Single sourceSelectorMode", func() {
+		cmp := newPropagation(syncv1alpha1.ConfigMapPropagationSpec{
+			Source:             syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			SourceSelectorMode: syncv1alpha1.SourceSelectorModeSingle,
+			Targets:            []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+		})
+		_, err := validator.ValidateCreate(ctx, cmp)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.sourceSelector"))
+	})
+
+	It("requires a namespaceSelector or targets", func() {
+		cmp := newPropagation(syncv1alpha1.ConfigMapPropagationSpec{
+			Source: syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+		})
+		_, err := validator.ValidateCreate(ctx, cmp)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("at least one of namespaceSelector, namespaceNamePattern or targets must be set"))
+
+		cmp.Spec.NamespaceSelector = &metav1.LabelSelector{}
+		_, err = validator.ValidateCreate(ctx, cmp)
+		Expect(err).NotTo(HaveOccurred())
+
+		cmp.Spec.NamespaceSelector = nil
+		cmp.Spec.NamespaceNamePattern = "team-*"
+		_, err = validator.ValidateCreate(ctx, cmp)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects a rename of a key dropped by includeKeys or excludeKeys", func() {
+		cmp := newPropagation(syncv1alpha1.ConfigMapPropagationSpec{
+			Source:       syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:      []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			IncludeKeys:  []string{"url", "port"},
+			ExcludeKeys:  []string{"port"},
+			KeyTransform: &syncv1alpha1.KeyTransform{Renames: map[string]string{"url": "URL", "port": "PORT", "user": "USER"}},
+		})
+		_, err := validator.ValidateCreate(ctx, cmp)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.keyTransform.renames[port]"))
+		Expect(err.Error()).To(ContainSubstring("spec.keyTransform.renames[user]"))
+		Expect(err.Error()).NotTo(ContainSubstring("spec.keyTransform.renames[url]"))
+
+		cmp.Spec.IncludeKeys, cmp.Spec.ExcludeKeys = nil, nil
+		_, err = validator.ValidateUpdate(ctx, cmp, cmp)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects an invalid namespaceNamePattern", func() {
+		cmp := newPropagation(syncv1alpha1.ConfigMapPropagationSpec{
+			Source:               syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			NamespaceNamePattern: "re:team-(",
+		})
+		_, err := validator.ValidateCreate(ctx, cmp)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.namespaceNamePattern"))
+
+		cmp.Spec.NamespaceNamePattern = "team-["
+		_, err = validator.ValidateCreate(ctx, cmp)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	It("rejects Periodic mode without a syncInterval or with a negative one", func() {
+		cmp := newPropagation(syncv1alpha1.ConfigMapPropagationSpec{
+			Source:   syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:  []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode: syncv1alpha1.SyncModePeriodic,
+		})
+		_, err := validator.ValidateCreate(ctx, cmp)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("a syncInterval of 0 or more is required when syncMode is Periodic"))
+
+		cmp.Spec.SyncInterval = &metav1.Duration{Duration: -time.Minute}
+		_, err = validator.ValidateUpdate(ctx, cmp, cmp)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	It("accepts Periodic mode with a zero syncInterval", func() {
+		cmp := newPropagation(syncv1alpha1.ConfigMapPropagationSpec{
+			Source:       syncv1alpha1.PropagationSource{Name: "app", Namespace: "default"},
+			Targets:      []syncv1alpha1.TargetRef{{Namespace: "team-a"}},
+			SyncMode:     syncv1alpha1.SyncModePeriodic,
+			SyncInterval: &metav1.Duration{},
+		})
+		_, err := validator.ValidateCreate(ctx, cmp)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})